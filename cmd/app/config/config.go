@@ -5,9 +5,10 @@ var SysConfig *Config
 
 // Config 配置对象
 type Config struct {
-	Default DefaultOptions `mapstructure:"default"`
-	Mysql   MysqlOptions   `mapstructure:"mysql"`
-	Log     LogConfig      `mapstructure:"log"`
+	Default  DefaultOptions  `mapstructure:"default"`
+	Mysql    MysqlOptions    `mapstructure:"mysql"`
+	Log      LogConfig       `mapstructure:"log"`
+	WebShell WebShellOptions `mapstructure:"webshell"`
 }
 
 // DefaultOptions 默认配置选项
@@ -18,6 +19,7 @@ type DefaultOptions struct {
 	JWTSecret            string `mapstructure:"JWTSecret"`
 	ExpireTime           int64  `mapstructure:"expireTime"`
 	KubernetesConfigFile string `mapstructure:"kubernetesConfigFile"`
+	DebugImage           string `mapstructure:"debugImage"`
 }
 
 // MysqlOptions mysql配置选项
@@ -40,3 +42,19 @@ type LogConfig struct {
 	MaxAge     int    `mapstructure:"max_age"`
 	MaxBackups int    `mapstructure:"max_backups"`
 }
+
+// WebShellOptions web终端配置选项
+type WebShellOptions struct {
+	// Shells 未指定命令时依次尝试的shell，为空时使用 bash、sh、ash、zsh
+	Shells []string `mapstructure:"shells"`
+	// AllowedCommands 允许用户自定义执行的命令，每一项为完整的命令行，命令及全部参数完全一致才允许执行，为空时不允许自定义命令
+	AllowedCommands []string `mapstructure:"allowedCommands"`
+	// RecordingDir 会话录像保存的本地目录，为空时使用工作目录下的recordings
+	RecordingDir string `mapstructure:"recordingDir"`
+	// IdleTimeout 会话没有输入多少秒后断开，为0时不断开
+	IdleTimeout int `mapstructure:"idleTimeout"`
+	// MaxSessions 全局的并发会话数上限，为0时不限制
+	MaxSessions int `mapstructure:"maxSessions"`
+	// MaxSessionsPerUser 每个用户的并发会话数上限，为0时不限制
+	MaxSessionsPerUser int `mapstructure:"maxSessionsPerUser"`
+}
@@ -31,6 +31,11 @@ var configObj = &Config{
 		MaxAge:     30,
 		MaxBackups: 7,
 	},
+	WebShell: WebShellOptions{
+		IdleTimeout:        1800,
+		MaxSessions:        200,
+		MaxSessionsPerUser: 5,
+	},
 }
 
 // Binding 解析外部的配置文件，默认是 ./config.yaml
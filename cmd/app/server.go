@@ -16,6 +16,7 @@ import (
 	"github.com/noovertime7/kubemanage/cmd/app/options"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
 	"github.com/noovertime7/kubemanage/pkg/logger"
+	"github.com/noovertime7/kubemanage/pkg/types"
 	"github.com/noovertime7/kubemanage/pkg/utils"
 	"github.com/noovertime7/kubemanage/router"
 )
@@ -71,8 +72,12 @@ func Run(opt *options.Options) error {
 	utils.PrintLogo()
 	// 设置核心应用接口
 	v1.Setup(opt)
-	//初始化K8s client  TODO 未来移除
+	// 注册本地集群，作为默认集群
 	InitLocalK8s()
+	// 注册数据库中导入的集群
+	if err := v1.CoreV1.Cluster().Register(context.TODO()); err != nil {
+		return err
+	}
 	// 初始化 APIs 路由
 	router.InstallRouters(opt)
 	// 启动优雅服务
@@ -80,12 +85,15 @@ func Run(opt *options.Options) error {
 	return nil
 }
 
-// InitLocalK8s 初始化本地k8s连接环境
+// InitLocalK8s 初始化本地k8s连接环境，并注册为默认集群
 func InitLocalK8s() {
-	// 初始化 K8sClient client，有异常则panic
-	if err := kube.K8sClient.Init(); err != nil {
-		utils.Must(err)
+	local := &kube.K8sClient{Name: kube.DefaultClusterName}
+	// 本地集群不可用时仍然可以使用导入的集群，只记录日志
+	if err := local.Init(); err != nil {
+		logger.LG.Warn("init local kubernetes client failed, default cluster is unavailable", zap.Error(err))
+		return
 	}
+	kube.Clusters.Set(local)
 }
 
 // runServer 优雅启动貔貅服务
@@ -108,10 +116,17 @@ func runServer(opt *options.Options) {
 	<-quit
 	logger.LG.Info("shutting kubemanage server down ...")
 
+	// websocket连接已被劫持，Shutdown不会等待，先通知所有web终端会话关闭并等待录像保存完成，
+	// 会话排空使用单独的超时，不占用下面http server关闭的时间
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer drainCancel()
+	if err := types.TerminalSessions.Shutdown(drainCtx); err != nil {
+		logger.LG.Warn("webshell sessions were not drained before shutdown timeout", zap.Error(err))
+	}
+
 	// The context is used to inform the server it has 5 seconds to finish the request
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.LG.Fatal("kubemanage server forced to shutdown: ", zap.Error(err))
 		os.Exit(1)
@@ -0,0 +1,142 @@
+package cluster
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// CreateCluster 导入集群
+// ListPage godoc
+// @Summary      导入集群
+// @Description  上传kubeconfig文件导入集群，导入后立即生效
+// @Tags         cluster
+// @ID           /api/cluster/create
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        name         formData  string  true   "集群名称"
+// @Param        description  formData  string  false  "集群描述"
+// @Param        kubeconfig   formData  file    true   "kubeconfig文件"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": "导入成功"}"
+// @Router       /api/cluster/create [post]
+func (c *clusterController) CreateCluster(ctx *gin.Context) {
+	params := &dto.ClusterCreateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	kubeConfig, err := readKubeConfig(ctx)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err = v1.CoreV1.Cluster().Create(ctx, params, kubeConfig); err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "导入成功")
+}
+
+// DeleteCluster 删除集群
+// ListPage godoc
+// @Summary      删除集群
+// @Description  删除集群
+// @Tags         cluster
+// @ID           /api/cluster/del
+// @Accept       json
+// @Produce      json
+// @Param        id  query  int  true  "集群ID"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": "删除成功"}"
+// @Router       /api/cluster/del [delete]
+func (c *clusterController) DeleteCluster(ctx *gin.Context) {
+	params := &dto.ClusterIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.Cluster().Delete(ctx, params.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// GetClusterList 查看集群列表
+// ListPage godoc
+// @Summary      查看集群列表
+// @Description  查看集群列表
+// @Tags         cluster
+// @ID           /api/cluster/list
+// @Accept       json
+// @Produce      json
+// @Param        filter_name  query  string  false  "过滤"
+// @Param        page         query  int     false  "页码"
+// @Param        limit        query  int     false  "分页限制"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": v1.ClusterResp}"
+// @Router       /api/cluster/list [get]
+func (c *clusterController) GetClusterList(ctx *gin.Context) {
+	params := &dto.ClusterListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.Cluster().FindList(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetClusterDetail 查看集群详情
+// ListPage godoc
+// @Summary      查看集群详情
+// @Description  查看集群详情
+// @Tags         cluster
+// @ID           /api/cluster/detail
+// @Accept       json
+// @Produce      json
+// @Param        id  query  int  true  "集群ID"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": model.Cluster}"
+// @Router       /api/cluster/detail [get]
+func (c *clusterController) GetClusterDetail(ctx *gin.Context) {
+	params := &dto.ClusterIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.Cluster().Find(ctx, params.ID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// readKubeConfig 读取上传的kubeconfig文件内容
+func readKubeConfig(ctx *gin.Context) ([]byte, error) {
+	fileHeader, err := ctx.FormFile("kubeconfig")
+	if err != nil {
+		return nil, err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
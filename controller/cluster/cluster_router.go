@@ -0,0 +1,21 @@
+package cluster
+
+import "github.com/gin-gonic/gin"
+
+type clusterController struct{}
+
+func NewClusterRouter(ginEngine *gin.RouterGroup) {
+	c := clusterController{}
+	c.initRoutes(ginEngine)
+}
+
+func (c *clusterController) initRoutes(ginEngine *gin.RouterGroup) {
+	clusterRoute := ginEngine.Group("/cluster")
+	cluster := &clusterController{}
+	{
+		clusterRoute.POST("/create", cluster.CreateCluster)
+		clusterRoute.DELETE("/del", cluster.DeleteCluster)
+		clusterRoute.GET("/list", cluster.GetClusterList)
+		clusterRoute.GET("/detail", cluster.GetClusterDetail)
+	}
+}
@@ -22,6 +22,7 @@ type configmap struct{}
 // @ID           /api/k8s/configmap/del
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "Configmap名称"
 // @Param        namespace    query  string  true  "命名空间"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
@@ -33,7 +34,7 @@ func (s *configmap) DeleteConfigmap(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Configmap.DeleteConfigmap(params.Name, params.NameSpace); err != nil {
+	if err := kube.Configmap.DeleteConfigmap(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
 		v1.Log.ErrorWithCode(globalError.DeleteError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
 		return
@@ -44,14 +45,17 @@ func (s *configmap) DeleteConfigmap(ctx *gin.Context) {
 // UpdateConfigmap 更新Configmap
 // ListPage godoc
 // @Summary      更新Configmap
-// @Description  更新Configmap
+// @Description  更新Configmap，content中必须包含metadata.resourceVersion，版本冲突时返回20105错误码并在data中附带线上最新对象
 // @Tags         Configmap
 // @ID           /api/k8s/configmap/update
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "无状态控制器名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Param        content    query  string  true  "更新内容"
+// @Param        dry_run       query  bool    false  "预览模式，只返回变更差异不修改集群"
+// @Param        unified_diff  query  bool    false  "预览时是否返回unified格式的yaml差异"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "更新成功}"
 // @Router       /api/k8s/configmap/update [put]
 func (s *configmap) UpdateConfigmap(ctx *gin.Context) {
@@ -61,9 +65,13 @@ func (s *configmap) UpdateConfigmap(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Configmap.UpdateConfigmap(params.Content, params.NameSpace); err != nil {
-		v1.Log.ErrorWithCode(globalError.UpdateError, err)
-		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+	preview, err := kube.Configmap.UpdateConfigmap(middleware.GetK8sClient(ctx), params.Content, params.NameSpace, params.DryRunInput)
+	if err != nil {
+		responseUpdateError(ctx, err)
+		return
+	}
+	if params.DryRun {
+		middleware.ResponseSuccess(ctx, preview)
 		return
 	}
 	middleware.ResponseSuccess(ctx, "更新成功")
@@ -77,7 +85,13 @@ func (s *configmap) UpdateConfigmap(ctx *gin.Context) {
 // @ID           /api/k8s/configmap/list
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.nodeName=node1"
+// @Param        status       query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by      query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view         query  string  false  "返回视图，table时只返回摘要行"
 // @Param        namespace  query  string  false  "命名空间"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
@@ -90,7 +104,7 @@ func (s *configmap) GetConfigmapList(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Configmap.GetConfigmaps(params.FilterName, params.NameSpace, params.Limit, params.Page)
+	data, err := kube.Configmap.GetConfigmaps(middleware.GetK8sClient(ctx), params.NameSpace, &params.DataSelectInput)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -107,6 +121,7 @@ func (s *configmap) GetConfigmapList(ctx *gin.Context) {
 // @ID           /api/k8s/configmap/detail
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "Configmap名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":v1.Deployment }"
@@ -118,7 +133,7 @@ func (s *configmap) GetConfigmapDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Configmap.GetConfigmapDetail(params.Name, params.NameSpace)
+	data, err := kube.Configmap.GetConfigmapDetail(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
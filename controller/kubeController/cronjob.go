@@ -0,0 +1,283 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+var CronJob cronJob
+
+type cronJob struct{}
+
+// CreateCronJob 创建cronjob
+// ListPage godoc
+// @Summary      创建cronjob
+// @Description  创建cronjob，schedule为cron格式，其余字段为任务模板，与创建job的参数一致，cronjob接口需要kubernetes 1.21及以上版本
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/create
+// @Accept       json
+// @Produce      json
+// @Param        cluster  query  string                      false  "集群名称，默认为default"
+// @Param        body     body   kubeDto.CronJobCreateInput  true   "body"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": "新增成功}"
+// @Router       /api/k8s/cronjob/create [post]
+func (c *cronJob) CreateCronJob(ctx *gin.Context) {
+	params := &kubeDto.CronJobCreateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.CronJob.CreateCronJob(middleware.GetK8sClient(ctx), params); err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "新增成功")
+}
+
+// DeleteCronJob 删除cronjob
+// ListPage godoc
+// @Summary      删除cronjob
+// @Description  删除cronjob，其创建的job和pod一起删除
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/del
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "cronjob名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response "{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/k8s/cronjob/del [delete]
+func (c *cronJob) DeleteCronJob(ctx *gin.Context) {
+	params := &kubeDto.CronJobNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.CronJob.DeleteCronJob(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// UpdateCronJob 更新cronjob
+// ListPage godoc
+// @Summary      更新cronjob
+// @Description  更新cronjob，content中必须包含metadata.resourceVersion，版本冲突时返回20105错误码并在data中附带线上最新对象
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/update
+// @Accept       json
+// @Produce      json
+// @Param        cluster       query  string  false  "集群名称，默认为default"
+// @Param        namespace     query  string  true   "命名空间"
+// @Param        content       query  string  true   "更新内容"
+// @Param        dry_run       query  bool    false  "预览模式，只返回变更差异不修改集群"
+// @Param        unified_diff  query  bool    false  "预览时是否返回unified格式的yaml差异"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": "更新成功}"
+// @Router       /api/k8s/cronjob/update [put]
+func (c *cronJob) UpdateCronJob(ctx *gin.Context) {
+	params := &kubeDto.CronJobUpdateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	preview, err := kube.CronJob.UpdateCronJob(middleware.GetK8sClient(ctx), params.Content, params.NameSpace, params.DryRunInput)
+	if err != nil {
+		responseUpdateError(ctx, err)
+		return
+	}
+	if params.DryRun {
+		middleware.ResponseSuccess(ctx, preview)
+		return
+	}
+	middleware.ResponseSuccess(ctx, "更新成功")
+}
+
+// GetCronJobList 查看cronjob列表
+// ListPage godoc
+// @Summary      查看cronjob列表
+// @Description  查看cronjob列表，status可按Scheduled、Suspended过滤
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/list
+// @Accept       json
+// @Produce      json
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        filter_name     query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.schedule=0 * * * *"
+// @Param        status          query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by         query  string  false  "排序，如 name:asc,last_schedule:desc"
+// @Param        view            query  string  false  "返回视图，table时只返回摘要行"
+// @Param        namespace       query  string  false  "命名空间"
+// @Param        page            query  int     false  "页码"
+// @Param        limit           query  int     false  "分页限制"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": }"
+// @Router       /api/k8s/cronjob/list [get]
+func (c *cronJob) GetCronJobList(ctx *gin.Context) {
+	params := &kubeDto.CronJobListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.CronJob.GetCronJobs(middleware.GetK8sClient(ctx), params.NameSpace, &params.DataSelectInput)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetCronJobDetail 获取cronjob详情
+// ListPage godoc
+// @Summary      获取cronjob详情
+// @Description  获取cronjob详情
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/detail
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "cronjob名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data":v1.CronJob }"
+// @Router       /api/k8s/cronjob/detail [get]
+func (c *cronJob) GetCronJobDetail(ctx *gin.Context) {
+	params := &kubeDto.CronJobNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.CronJob.GetCronJobDetail(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// SuspendCronJob 暂停cronjob调度
+// ListPage godoc
+// @Summary      暂停cronjob调度
+// @Description  暂停cronjob调度，已经创建的任务继续运行
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/suspend
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "cronjob名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": 暂停CronJob成功}"
+// @Router       /api/k8s/cronjob/suspend [put]
+func (c *cronJob) SuspendCronJob(ctx *gin.Context) {
+	params := &kubeDto.CronJobNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.CronJob.SuspendCronJob(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "暂停CronJob成功")
+}
+
+// ResumeCronJob 恢复cronjob调度
+// ListPage godoc
+// @Summary      恢复cronjob调度
+// @Description  恢复cronjob调度
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/resume
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "cronjob名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": 恢复CronJob成功}"
+// @Router       /api/k8s/cronjob/resume [put]
+func (c *cronJob) ResumeCronJob(ctx *gin.Context) {
+	params := &kubeDto.CronJobNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.CronJob.ResumeCronJob(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "恢复CronJob成功")
+}
+
+// TriggerCronJob 立即执行cronjob
+// ListPage godoc
+// @Summary      立即执行cronjob
+// @Description  以cronjob的任务模板立即创建一个job，与kubectl create job --from=cronjob一致，暂停调度时也可以执行
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/trigger
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "cronjob名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data":v1.Job }"
+// @Router       /api/k8s/cronjob/trigger [post]
+func (c *cronJob) TriggerCronJob(ctx *gin.Context) {
+	params := &kubeDto.CronJobNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.CronJob.TriggerCronJob(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetCronJobHistory 获取cronjob任务历史
+// ListPage godoc
+// @Summary      获取cronjob任务历史
+// @Description  获取cronjob创建的任务，按创建时间倒序，包含任务状态、是否手动触发以及每个pod各容器的日志地址
+// @Tags         cronjob
+// @ID           /api/k8s/cronjob/history
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "cronjob名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": []kube.CronJobRun}"
+// @Router       /api/k8s/cronjob/history [get]
+func (c *cronJob) GetCronJobHistory(ctx *gin.Context) {
+	params := &kubeDto.CronJobNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.CronJob.GetCronJobHistory(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
@@ -21,6 +21,7 @@ type daemonSet struct{}
 // @ID           /api/k8s/DaemonSet/del
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "DaemonSet名称"
 // @Param        namespace    query  string  true  "命名空间"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
@@ -32,7 +33,7 @@ func (s *daemonSet) DeleteDaemonSet(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.DaemonSet.DeleteDaemonSet(params.Name, params.NameSpace); err != nil {
+	if err := kube.DaemonSet.DeleteDaemonSet(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
 		v1.Log.ErrorWithCode(globalError.DeleteError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
 		return
@@ -43,14 +44,17 @@ func (s *daemonSet) DeleteDaemonSet(ctx *gin.Context) {
 // UpdateDaemonSet 更新DaemonSet
 // ListPage godoc
 // @Summary      更新DaemonSet
-// @Description  更新DaemonSet
+// @Description  更新DaemonSet，content中必须包含metadata.resourceVersion，版本冲突时返回20105错误码并在data中附带线上最新对象
 // @Tags         DaemonSet
 // @ID           /api/k8s/DaemonSet/update
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "无状态控制器名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Param        content    query  string  true  "更新内容"
+// @Param        dry_run       query  bool    false  "预览模式，只返回变更差异不修改集群"
+// @Param        unified_diff  query  bool    false  "预览时是否返回unified格式的yaml差异"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "更新成功}"
 // @Router       /api/k8s/daemonset/update [put]
 func (s *daemonSet) UpdateDaemonSet(ctx *gin.Context) {
@@ -60,9 +64,13 @@ func (s *daemonSet) UpdateDaemonSet(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.DaemonSet.UpdateDaemonSet(params.Content, params.NameSpace); err != nil {
-		v1.Log.ErrorWithCode(globalError.UpdateError, err)
-		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+	preview, err := kube.DaemonSet.UpdateDaemonSet(middleware.GetK8sClient(ctx), params.Content, params.NameSpace, params.DryRunInput)
+	if err != nil {
+		responseUpdateError(ctx, err)
+		return
+	}
+	if params.DryRun {
+		middleware.ResponseSuccess(ctx, preview)
 		return
 	}
 	middleware.ResponseSuccess(ctx, "更新成功")
@@ -76,7 +84,13 @@ func (s *daemonSet) UpdateDaemonSet(ctx *gin.Context) {
 // @ID           /api/k8s/DaemonSet/list
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.nodeName=node1"
+// @Param        status       query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by      query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view         query  string  false  "返回视图，table时只返回摘要行"
 // @Param        namespace  query  string  false  "命名空间"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
@@ -89,7 +103,7 @@ func (s *daemonSet) GetDaemonSetList(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.DaemonSet.GetDaemonSets(params.FilterName, params.NameSpace, params.Limit, params.Page)
+	data, err := kube.DaemonSet.GetDaemonSets(middleware.GetK8sClient(ctx), params.NameSpace, &params.DataSelectInput)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -106,6 +120,7 @@ func (s *daemonSet) GetDaemonSetList(ctx *gin.Context) {
 // @ID           /api/k8s/DaemonSet/detail
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "DaemonSet名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":v1.Deployment }"
@@ -117,7 +132,7 @@ func (s *daemonSet) GetDaemonSetDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.DaemonSet.GetDaemonSetDetail(params.Name, params.NameSpace)
+	data, err := kube.DaemonSet.GetDaemonSetDetail(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -125,3 +140,31 @@ func (s *daemonSet) GetDaemonSetDetail(ctx *gin.Context) {
 	}
 	middleware.ResponseSuccess(ctx, data)
 }
+
+// SetImage 更新daemonset容器镜像
+// ListPage godoc
+// @Summary      更新daemonset容器镜像
+// @Description  按容器名称更新daemonset的镜像，记录变更原因并返回新的版本号，控制器尚未生成新版本时版本号为0
+// @Tags         daemonset
+// @ID           /api/k8s/daemonset/image
+// @Accept       json
+// @Produce      json
+// @Param        cluster  query  string                   false  "集群名称，默认为default"
+// @Param        body     body   kubeDto.SetImageInput  true   "镜像信息"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": revision}"
+// @Router       /api/k8s/daemonset/image [put]
+func (s *daemonSet) SetImage(ctx *gin.Context) {
+	params := &kubeDto.SetImageInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	revision, err := kube.DaemonSet.SetImage(middleware.GetK8sClient(ctx), params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, revision)
+}
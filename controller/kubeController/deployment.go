@@ -1,7 +1,13 @@
 package kubeController
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
 	_ "k8s.io/api/apps/v1"
@@ -23,6 +29,7 @@ type deployment struct{}
 // @ID           /api/k8s/deployment/create
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        body  body  kubernetes.DeployCreateInput  true  "body"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "新增成功}"
 // @Router       /api/k8s/deployment/create [post]
@@ -35,7 +42,7 @@ func (d *deployment) CreateDeployment(ctx *gin.Context) {
 		return
 	}
 	//
-	if err := kube.Deployment.CreateDeployment(params); err != nil {
+	if err := kube.Deployment.CreateDeployment(middleware.GetK8sClient(ctx), params); err != nil {
 		v1.Log.ErrorWithCode(globalError.CreateError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
 		return
@@ -51,6 +58,7 @@ func (d *deployment) CreateDeployment(ctx *gin.Context) {
 // @ID           /api/k8s/deployment/del
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "Deployment名称"
 // @Param        namespace    query  string  true  "命名空间"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
@@ -62,7 +70,7 @@ func (d *deployment) DeleteDeployment(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Deployment.DeleteDeployment(params.Name, params.NameSpace); err != nil {
+	if err := kube.Deployment.DeleteDeployment(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
 		v1.Log.ErrorWithCode(globalError.DeleteError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
 		return
@@ -73,14 +81,17 @@ func (d *deployment) DeleteDeployment(ctx *gin.Context) {
 // UpdateDeployment 更新deployment
 // ListPage godoc
 // @Summary      更新deployment
-// @Description  更新deployment
+// @Description  更新deployment，content中必须包含metadata.resourceVersion，版本冲突时返回20105错误码并在data中附带线上最新对象
 // @Tags         deployment
 // @ID           /api/k8s/deployment/update
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "无状态控制器名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Param        content    query  string  true  "更新内容"
+// @Param        dry_run       query  bool    false  "预览模式，只返回变更差异不修改集群"
+// @Param        unified_diff  query  bool    false  "预览时是否返回unified格式的yaml差异"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "更新成功}"
 // @Router       /api/k8s/deployment/update [put]
 func (d *deployment) UpdateDeployment(ctx *gin.Context) {
@@ -90,9 +101,13 @@ func (d *deployment) UpdateDeployment(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Deployment.UpdateDeployment(params.NameSpace, params.Content); err != nil {
-		v1.Log.ErrorWithCode(globalError.UpdateError, err)
-		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+	preview, err := kube.Deployment.UpdateDeployment(middleware.GetK8sClient(ctx), params.NameSpace, params.Content, params.DryRunInput)
+	if err != nil {
+		responseUpdateError(ctx, err)
+		return
+	}
+	if params.DryRun {
+		middleware.ResponseSuccess(ctx, preview)
 		return
 	}
 	middleware.ResponseSuccess(ctx, "更新成功")
@@ -106,7 +121,13 @@ func (d *deployment) UpdateDeployment(ctx *gin.Context) {
 // @ID           /api/k8s/deployment/list
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.nodeName=node1"
+// @Param        status       query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by      query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view         query  string  false  "返回视图，table时只返回摘要行"
 // @Param        namespace  query  string  false  "命名空间"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
@@ -119,7 +140,7 @@ func (d *deployment) GetDeploymentList(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Deployment.GetDeployments(params.FilterName, params.NameSpace, params.Limit, params.Page)
+	data, err := kube.Deployment.GetDeployments(middleware.GetK8sClient(ctx), params.NameSpace, &params.DataSelectInput)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -136,6 +157,7 @@ func (d *deployment) GetDeploymentList(ctx *gin.Context) {
 // @ID           /api/k8s/deployment/detail
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "Deployment名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":v1.Deployment }"
@@ -147,7 +169,7 @@ func (d *deployment) GetDeploymentDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Deployment.GetDeploymentDetail(params.Name, params.NameSpace)
+	data, err := kube.Deployment.GetDeploymentDetail(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -164,10 +186,11 @@ func (d *deployment) GetDeploymentDetail(ctx *gin.Context) {
 // @ID           /api/k8s/deployment/numnp
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data":service.DeployNp }"
 // @Router       /api/k8s/deployment/numnp [get]
 func (d *deployment) GetDeploymentNumPreNS(ctx *gin.Context) {
-	data, err := kube.Deployment.GetDeployNumPerNS()
+	data, err := kube.Deployment.GetDeployNumPerNS(middleware.GetK8sClient(ctx))
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -184,6 +207,7 @@ func (d *deployment) GetDeploymentNumPreNS(ctx *gin.Context) {
 // @ID           /api/k8s/deployment/restart
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "无状态控制器名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": 重启Deployment成功}"
@@ -195,7 +219,7 @@ func (d *deployment) RestartDeployment(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Deployment.RestartDeployment(params.Name, params.NameSpace); err != nil {
+	if err := kube.Deployment.RestartDeployment(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
 		v1.Log.ErrorWithCode(globalError.ServerError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
 		return
@@ -211,6 +235,7 @@ func (d *deployment) RestartDeployment(ctx *gin.Context) {
 // @ID           /api/k8s/deployment/scale
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "无状态控制器名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Param        scale_num  query  int     true  "期望副本数"
@@ -223,7 +248,7 @@ func (d *deployment) ScaleDeployment(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	num, err := kube.Deployment.ScaleDeployment(params.Name, params.NameSpace, params.ScaleNum)
+	num, err := kube.Deployment.ScaleDeployment(middleware.GetK8sClient(ctx), params.Name, params.NameSpace, params.ScaleNum)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.ServerError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
@@ -231,3 +256,202 @@ func (d *deployment) ScaleDeployment(ctx *gin.Context) {
 	}
 	middleware.ResponseSuccess(ctx, num)
 }
+
+// GetDeploymentHistory 获取deployment发布历史
+// ListPage godoc
+// @Summary      获取deployment发布历史
+// @Description  获取deployment发布历史，每个版本对应一个replicaSet
+// @Tags         deployment
+// @ID           /api/k8s/deployment/history
+// @Accept       json
+// @Produce      json
+// @Param        cluster          query  string  false  "集群名称，默认为default"
+// @Param        deployment_name  query  string  true   "无状态控制器名称"
+// @Param        namespace        query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": []service.DeploymentRevision}"
+// @Router       /api/k8s/deployment/history [get]
+func (d *deployment) GetDeploymentHistory(ctx *gin.Context) {
+	params := &kubeDto.DeploymentNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.Deployment.GetDeploymentHistory(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// RollbackDeployment 回滚deployment
+// ListPage godoc
+// @Summary      回滚deployment
+// @Description  回滚deployment到指定版本，revision为0时回滚到上一个版本
+// @Tags         deployment
+// @ID           /api/k8s/deployment/rollback
+// @Accept       json
+// @Produce      json
+// @Param        cluster          query  string  false  "集群名称，默认为default"
+// @Param        deployment_name  query  string  true   "无状态控制器名称"
+// @Param        namespace        query  string  true   "命名空间"
+// @Param        revision         query  int     false  "目标版本号"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": revision}"
+// @Router       /api/k8s/deployment/rollback [put]
+func (d *deployment) RollbackDeployment(ctx *gin.Context) {
+	params := &kubeDto.DeployRollbackInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	revision, err := kube.Deployment.RollbackDeployment(middleware.GetK8sClient(ctx), params.Name, params.NameSpace, params.Revision)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, revision)
+}
+
+// PauseDeployment 暂停deployment发布
+// ListPage godoc
+// @Summary      暂停deployment发布
+// @Description  暂停deployment发布，暂停期间修改模板不会触发滚动更新
+// @Tags         deployment
+// @ID           /api/k8s/deployment/pause
+// @Accept       json
+// @Produce      json
+// @Param        cluster          query  string  false  "集群名称，默认为default"
+// @Param        deployment_name  query  string  true   "无状态控制器名称"
+// @Param        namespace        query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": 暂停Deployment成功}"
+// @Router       /api/k8s/deployment/pause [put]
+func (d *deployment) PauseDeployment(ctx *gin.Context) {
+	params := &kubeDto.DeploymentNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Deployment.PauseDeployment(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "暂停Deployment成功")
+}
+
+// ResumeDeployment 恢复deployment发布
+// ListPage godoc
+// @Summary      恢复deployment发布
+// @Description  恢复deployment发布
+// @Tags         deployment
+// @ID           /api/k8s/deployment/resume
+// @Accept       json
+// @Produce      json
+// @Param        cluster          query  string  false  "集群名称，默认为default"
+// @Param        deployment_name  query  string  true   "无状态控制器名称"
+// @Param        namespace        query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": 恢复Deployment成功}"
+// @Router       /api/k8s/deployment/resume [put]
+func (d *deployment) ResumeDeployment(ctx *gin.Context) {
+	params := &kubeDto.DeploymentNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Deployment.ResumeDeployment(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "恢复Deployment成功")
+}
+
+// GetRolloutStatus 查询deployment发布进度
+// ListPage godoc
+// @Summary      查询deployment发布进度
+// @Description  普通请求返回当前发布进度；websocket请求持续推送operation为status的进度消息，直到发布完成、失败或超时，
+// @Description  监听结束时推送operation为done的消息，出错或超时时推送operation为error的消息
+// @Tags         deployment
+// @ID           /api/k8s/deployment/rollout/status
+// @Accept       json
+// @Produce      json
+// @Param        cluster          query  string  false  "集群名称，默认为default"
+// @Param        deployment_name  query  string  true   "无状态控制器名称"
+// @Param        namespace        query  string  true   "命名空间"
+// @Param        timeout          query  int     false  "websocket推送的超时时间，单位秒"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": kube.RolloutStatus}"
+// @Router       /api/k8s/deployment/rollout/status [get]
+func (d *deployment) GetRolloutStatus(ctx *gin.Context) {
+	params := &kubeDto.DeployRolloutStatusInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	cli := middleware.GetK8sClient(ctx)
+	if !websocket.IsWebSocketUpgrade(ctx.Request) {
+		data, err := kube.Deployment.GetRolloutStatus(cli, params.Name, params.NameSpace)
+		if err != nil {
+			v1.Log.ErrorWithCode(globalError.GetError, err)
+			middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+			return
+		}
+		middleware.ResponseSuccess(ctx, data)
+		return
+	}
+
+	serveStream(ctx, func(streamCtx context.Context, send func(operation, data string) error) error {
+		// 客户端断开或超时后停止监听
+		watchCtx := streamCtx
+		if params.Timeout > 0 {
+			var cancel context.CancelFunc
+			watchCtx, cancel = context.WithTimeout(streamCtx, time.Duration(params.Timeout)*time.Second)
+			defer cancel()
+		}
+		err := kube.Deployment.WatchRolloutStatus(watchCtx, cli, params.Name, params.NameSpace, func(status *kube.RolloutStatus) error {
+			data, err := json.Marshal(status)
+			if err != nil {
+				return err
+			}
+			return send("status", string(data))
+		})
+		if err != nil && watchCtx.Err() == context.DeadlineExceeded {
+			return errors.New("等待发布完成超时")
+		}
+		return err
+	})
+}
+
+// SetImage 更新deployment容器镜像
+// ListPage godoc
+// @Summary      更新deployment容器镜像
+// @Description  按容器名称更新deployment的镜像，记录变更原因并返回新的版本号，控制器尚未生成新版本时版本号为0
+// @Tags         deployment
+// @ID           /api/k8s/deployment/image
+// @Accept       json
+// @Produce      json
+// @Param        cluster  query  string                   false  "集群名称，默认为default"
+// @Param        body     body   kubeDto.SetImageInput  true   "镜像信息"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": revision}"
+// @Router       /api/k8s/deployment/image [put]
+func (d *deployment) SetImage(ctx *gin.Context) {
+	params := &kubeDto.SetImageInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	revision, err := kube.Deployment.SetImage(middleware.GetK8sClient(ctx), params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, revision)
+}
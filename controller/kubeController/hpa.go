@@ -0,0 +1,169 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+var HPA hpa
+
+type hpa struct{}
+
+// CreateHPA 创建hpa
+// ListPage godoc
+// @Summary      创建hpa
+// @Description  创建autoscaling/v2版本的hpa，扩缩容目标为deployment，cpu_utilization与memory_utilization至少填写一项，需要kubernetes 1.23及以上版本
+// @Tags         hpa
+// @ID           /api/k8s/hpa/create
+// @Accept       json
+// @Produce      json
+// @Param        cluster  query  string                  false  "集群名称，默认为default"
+// @Param        body     body   kubeDto.HPACreateInput  true   "body"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": "新增成功}"
+// @Router       /api/k8s/hpa/create [post]
+func (h *hpa) CreateHPA(ctx *gin.Context) {
+	params := &kubeDto.HPACreateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.HPA.CreateHPA(middleware.GetK8sClient(ctx), params); err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "新增成功")
+}
+
+// DeleteHPA 删除hpa
+// ListPage godoc
+// @Summary      删除hpa
+// @Description  删除hpa，目标deployment保持当前副本数
+// @Tags         hpa
+// @ID           /api/k8s/hpa/del
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "hpa名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response "{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/k8s/hpa/del [delete]
+func (h *hpa) DeleteHPA(ctx *gin.Context) {
+	params := &kubeDto.HPANameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.HPA.DeleteHPA(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// UpdateHPA 更新hpa
+// ListPage godoc
+// @Summary      更新hpa
+// @Description  更新hpa，content中必须包含metadata.resourceVersion，版本冲突时返回20105错误码并在data中附带线上最新对象
+// @Tags         hpa
+// @ID           /api/k8s/hpa/update
+// @Accept       json
+// @Produce      json
+// @Param        cluster       query  string  false  "集群名称，默认为default"
+// @Param        namespace     query  string  true   "命名空间"
+// @Param        content       query  string  true   "更新内容"
+// @Param        dry_run       query  bool    false  "预览模式，只返回变更差异不修改集群"
+// @Param        unified_diff  query  bool    false  "预览时是否返回unified格式的yaml差异"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": "更新成功}"
+// @Router       /api/k8s/hpa/update [put]
+func (h *hpa) UpdateHPA(ctx *gin.Context) {
+	params := &kubeDto.HPAUpdateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	preview, err := kube.HPA.UpdateHPA(middleware.GetK8sClient(ctx), params.Content, params.NameSpace, params.DryRunInput)
+	if err != nil {
+		responseUpdateError(ctx, err)
+		return
+	}
+	if params.DryRun {
+		middleware.ResponseSuccess(ctx, preview)
+		return
+	}
+	middleware.ResponseSuccess(ctx, "更新成功")
+}
+
+// GetHPAList 查看hpa列表
+// ListPage godoc
+// @Summary      查看hpa列表
+// @Description  查看hpa列表，table视图的targets列为各指标的当前值/目标值，status可按Scaling、MaxReplicas过滤
+// @Tags         hpa
+// @ID           /api/k8s/hpa/list
+// @Accept       json
+// @Produce      json
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        filter_name     query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.scaleTargetRef.name=nginx"
+// @Param        status          query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by         query  string  false  "排序，如 name:asc,replicas:desc"
+// @Param        view            query  string  false  "返回视图，table时只返回摘要行"
+// @Param        namespace       query  string  false  "命名空间"
+// @Param        page            query  int     false  "页码"
+// @Param        limit           query  int     false  "分页限制"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": }"
+// @Router       /api/k8s/hpa/list [get]
+func (h *hpa) GetHPAList(ctx *gin.Context) {
+	params := &kubeDto.HPAListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.HPA.GetHPAs(middleware.GetK8sClient(ctx), params.NameSpace, &params.DataSelectInput)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetHPADetail 获取hpa详情
+// ListPage godoc
+// @Summary      获取hpa详情
+// @Description  获取hpa详情，包含各指标的当前值、目标值以及扩缩容事件
+// @Tags         hpa
+// @ID           /api/k8s/hpa/detail
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "hpa名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data":kube.HPADetail }"
+// @Router       /api/k8s/hpa/detail [get]
+func (h *hpa) GetHPADetail(ctx *gin.Context) {
+	params := &kubeDto.HPANameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.HPA.GetHPADetail(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
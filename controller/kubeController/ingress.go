@@ -21,6 +21,7 @@ type ingressController struct{}
 // @ID           /api/k8s/ingress/create
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        body  body  kubernetes.IngressCreteInput  true  "body"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "新增成功}"
 // @Router       /api/k8s/ingress/create [post]
@@ -31,7 +32,7 @@ func (i *ingressController) CreateIngress(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Ingress.CreateIngress(params); err != nil {
+	if err := kube.Ingress.CreateIngress(middleware.GetK8sClient(ctx), params); err != nil {
 		v1.Log.ErrorWithCode(globalError.CreateError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
 		return
@@ -47,6 +48,7 @@ func (i *ingressController) CreateIngress(ctx *gin.Context) {
 // @ID           /api/k8s/ingress/del
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "ingress名称"
 // @Param        namespace    query  string  true  "命名空间"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
@@ -58,7 +60,7 @@ func (i *ingressController) DeleteIngress(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Ingress.DeleteIngress(params.NameSpace, params.Name); err != nil {
+	if err := kube.Ingress.DeleteIngress(middleware.GetK8sClient(ctx), params.NameSpace, params.Name); err != nil {
 		v1.Log.ErrorWithCode(globalError.DeleteError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
 		return
@@ -69,14 +71,17 @@ func (i *ingressController) DeleteIngress(ctx *gin.Context) {
 // UpdateIngress 更新ingress
 // ListPage godoc
 // @Summary      更新ingress
-// @Description  更新ingress
+// @Description  更新ingress，content中必须包含metadata.resourceVersion，版本冲突时返回20105错误码并在data中附带线上最新对象
 // @Tags         ingress
 // @ID           /api/k8s/ingress/update
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "ingress名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Param        content    query  string  true  "更新内容"
+// @Param        dry_run       query  bool    false  "预览模式，只返回变更差异不修改集群"
+// @Param        unified_diff  query  bool    false  "预览时是否返回unified格式的yaml差异"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "更新成功}"
 // @Router       /api/k8s/ingress/update [put]
 func (i *ingressController) UpdateIngress(ctx *gin.Context) {
@@ -86,9 +91,13 @@ func (i *ingressController) UpdateIngress(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Ingress.UpdateIngress(params.NameSpace, params.Content); err != nil {
-		v1.Log.ErrorWithCode(globalError.UpdateError, err)
-		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+	preview, err := kube.Ingress.UpdateIngress(middleware.GetK8sClient(ctx), params.NameSpace, params.Content, params.DryRunInput)
+	if err != nil {
+		responseUpdateError(ctx, err)
+		return
+	}
+	if params.DryRun {
+		middleware.ResponseSuccess(ctx, preview)
 		return
 	}
 	middleware.ResponseSuccess(ctx, "更新成功")
@@ -102,7 +111,13 @@ func (i *ingressController) UpdateIngress(ctx *gin.Context) {
 // @ID           /api/k8s/ingress/list
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.nodeName=node1"
+// @Param        status       query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by      query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view         query  string  false  "返回视图，table时只返回摘要行"
 // @Param        namespace  query  string  false  "命名空间"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
@@ -115,7 +130,7 @@ func (i *ingressController) GetIngressList(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Ingress.GetIngressList(params.FilterName, params.NameSpace, params.Limit, params.Page)
+	data, err := kube.Ingress.GetIngressList(middleware.GetK8sClient(ctx), params.NameSpace, &params.DataSelectInput)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -132,6 +147,7 @@ func (i *ingressController) GetIngressList(ctx *gin.Context) {
 // @ID           /api/k8s/ingress/detail
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name       query  string  true  "ingress名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":""  }"
@@ -143,7 +159,7 @@ func (i *ingressController) GetIngressDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Ingress.GetIngressDetail(params.NameSpace, params.Name)
+	data, err := kube.Ingress.GetIngressDetail(middleware.GetK8sClient(ctx), params.NameSpace, params.Name)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -160,10 +176,11 @@ func (i *ingressController) GetIngressDetail(ctx *gin.Context) {
 // @ID           /api/k8s/ingress/numnp
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data":"" }"
 // @Router       /api/k8s/ingress/numnp [get]
 func (i *ingressController) GetIngressNumPreNp(ctx *gin.Context) {
-	data, err := kube.Ingress.GetIngressNp()
+	data, err := kube.Ingress.GetIngressNp(middleware.GetK8sClient(ctx))
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -0,0 +1,169 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+var Job job
+
+type job struct{}
+
+// CreateJob 创建job
+// ListPage godoc
+// @Summary      创建job
+// @Description  创建job，restart_policy默认为Never，cpu和memory为空时不限制资源
+// @Tags         job
+// @ID           /api/k8s/job/create
+// @Accept       json
+// @Produce      json
+// @Param        cluster  query  string                  false  "集群名称，默认为default"
+// @Param        body     body   kubeDto.JobCreateInput  true   "body"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": "新增成功}"
+// @Router       /api/k8s/job/create [post]
+func (j *job) CreateJob(ctx *gin.Context) {
+	params := &kubeDto.JobCreateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Job.CreateJob(middleware.GetK8sClient(ctx), params); err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "新增成功")
+}
+
+// DeleteJob 删除job
+// ListPage godoc
+// @Summary      删除job
+// @Description  删除job，job创建的pod一起删除
+// @Tags         job
+// @ID           /api/k8s/job/del
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "job名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response "{"code": 200, msg="","data": "删除成功}"
+// @Router       /api/k8s/job/del [delete]
+func (j *job) DeleteJob(ctx *gin.Context) {
+	params := &kubeDto.JobNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := kube.Job.DeleteJob(middleware.GetK8sClient(ctx), params.Name, params.NameSpace); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
+
+// UpdateJob 更新job
+// ListPage godoc
+// @Summary      更新job
+// @Description  更新job，content中必须包含metadata.resourceVersion，版本冲突时返回20105错误码并在data中附带线上最新对象；job创建后pod模板不可修改
+// @Tags         job
+// @ID           /api/k8s/job/update
+// @Accept       json
+// @Produce      json
+// @Param        cluster       query  string  false  "集群名称，默认为default"
+// @Param        namespace     query  string  true   "命名空间"
+// @Param        content       query  string  true   "更新内容"
+// @Param        dry_run       query  bool    false  "预览模式，只返回变更差异不修改集群"
+// @Param        unified_diff  query  bool    false  "预览时是否返回unified格式的yaml差异"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": "更新成功}"
+// @Router       /api/k8s/job/update [put]
+func (j *job) UpdateJob(ctx *gin.Context) {
+	params := &kubeDto.JobUpdateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	preview, err := kube.Job.UpdateJob(middleware.GetK8sClient(ctx), params.Content, params.NameSpace, params.DryRunInput)
+	if err != nil {
+		responseUpdateError(ctx, err)
+		return
+	}
+	if params.DryRun {
+		middleware.ResponseSuccess(ctx, preview)
+		return
+	}
+	middleware.ResponseSuccess(ctx, "更新成功")
+}
+
+// GetJobList 查看job列表
+// ListPage godoc
+// @Summary      查看job列表
+// @Description  查看job列表，status可按Running、Succeeded、Failed、Suspended过滤
+// @Tags         job
+// @ID           /api/k8s/job/list
+// @Accept       json
+// @Produce      json
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        filter_name     query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 metadata.name=backup"
+// @Param        status          query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by         query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view            query  string  false  "返回视图，table时只返回摘要行"
+// @Param        namespace       query  string  false  "命名空间"
+// @Param        page            query  int     false  "页码"
+// @Param        limit           query  int     false  "分页限制"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": }"
+// @Router       /api/k8s/job/list [get]
+func (j *job) GetJobList(ctx *gin.Context) {
+	params := &kubeDto.JobListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.Job.GetJobs(middleware.GetK8sClient(ctx), params.NameSpace, &params.DataSelectInput)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// GetJobDetail 获取job详情
+// ListPage godoc
+// @Summary      获取job详情
+// @Description  获取job详情
+// @Tags         job
+// @ID           /api/k8s/job/detail
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        name       query  string  true   "job名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data":v1.Job }"
+// @Router       /api/k8s/job/detail [get]
+func (j *job) GetJobDetail(ctx *gin.Context) {
+	params := &kubeDto.JobNameNS{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.Job.GetJobDetail(middleware.GetK8sClient(ctx), params.Name, params.NameSpace)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
@@ -2,6 +2,8 @@ package kubeController
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/middleware"
 )
 
 type kubeRouter struct{}
@@ -12,8 +14,9 @@ func NewKubeRouter(ginEngine *gin.RouterGroup) {
 }
 
 func (k *kubeRouter) initRoutes(ginEngine *gin.RouterGroup) {
-	// 所有k8s相关的接口，都属于k8s组
+	// 所有k8s相关的接口，都属于k8s组，通过cluster参数选择操作的集群
 	k8sRoute := ginEngine.Group("/k8s")
+	k8sRoute.Use(middleware.K8sCluster())
 	{
 		k8sRoute.POST("/deployment/create", Deployment.CreateDeployment)
 		k8sRoute.DELETE("/deployment/del", Deployment.DeleteDeployment)
@@ -23,6 +26,12 @@ func (k *kubeRouter) initRoutes(ginEngine *gin.RouterGroup) {
 		k8sRoute.PUT("/deployment/restart", Deployment.RestartDeployment)
 		k8sRoute.GET("/deployment/scale", Deployment.ScaleDeployment)
 		k8sRoute.GET("/deployment/numnp", Deployment.GetDeploymentNumPreNS)
+		k8sRoute.GET("/deployment/history", Deployment.GetDeploymentHistory)
+		k8sRoute.PUT("/deployment/rollback", Deployment.RollbackDeployment)
+		k8sRoute.PUT("/deployment/pause", Deployment.PauseDeployment)
+		k8sRoute.PUT("/deployment/resume", Deployment.ResumeDeployment)
+		k8sRoute.GET("/deployment/rollout/status", Deployment.GetRolloutStatus)
+		k8sRoute.PUT("/deployment/image", Deployment.SetImage)
 	}
 	{
 		k8sRoute.GET("/pod/list", Pod.GetPods)
@@ -31,20 +40,54 @@ func (k *kubeRouter) initRoutes(ginEngine *gin.RouterGroup) {
 		k8sRoute.PUT("/pod/update", Pod.UpdatePod)
 		k8sRoute.GET("/pod/container", Pod.GetPodContainer)
 		k8sRoute.GET("/pod/log", Pod.GetPodLog)
+		k8sRoute.GET("/pod/log/stream", Pod.StreamPodLog)
+		k8sRoute.GET("/pod/log/aggregate", Pod.AggregatePodLogs)
+		k8sRoute.GET("/pod/log/download", Pod.DownloadPodLog)
 		k8sRoute.GET("/pod/numnp", Pod.GetPodNumPreNp)
 		k8sRoute.GET("/pod/webshell", Pod.WebShell)
+		k8sRoute.POST("/pod/debug", Pod.DebugPod)
+		k8sRoute.GET("/pod/file/list", Pod.ListFiles)
+		k8sRoute.GET("/pod/file/download", Pod.DownloadFile)
+		k8sRoute.POST("/pod/file/upload", Pod.UploadFile)
 	}
 	{
 		k8sRoute.DELETE("/daemonset/del", DaemonSet.DeleteDaemonSet)
 		k8sRoute.PUT("/daemonset/update", DaemonSet.UpdateDaemonSet)
 		k8sRoute.GET("/daemonset/list", DaemonSet.GetDaemonSetList)
 		k8sRoute.GET("/daemonset/detail", DaemonSet.GetDaemonSetDetail)
+		k8sRoute.PUT("/daemonset/image", DaemonSet.SetImage)
 	}
 	{
 		k8sRoute.DELETE("/statefulset/del", StatefulSet.DeleteStatefulSet)
 		k8sRoute.PUT("/statefulset/update", StatefulSet.UpdateStatefulSet)
 		k8sRoute.GET("/statefulset/list", StatefulSet.GetStatefulSetList)
 		k8sRoute.GET("/statefulset/detail", StatefulSet.GetStatefulSetDetail)
+		k8sRoute.PUT("/statefulset/image", StatefulSet.SetImage)
+	}
+	{
+		k8sRoute.POST("/job/create", Job.CreateJob)
+		k8sRoute.DELETE("/job/del", Job.DeleteJob)
+		k8sRoute.PUT("/job/update", Job.UpdateJob)
+		k8sRoute.GET("/job/list", Job.GetJobList)
+		k8sRoute.GET("/job/detail", Job.GetJobDetail)
+	}
+	{
+		k8sRoute.POST("/cronjob/create", CronJob.CreateCronJob)
+		k8sRoute.DELETE("/cronjob/del", CronJob.DeleteCronJob)
+		k8sRoute.PUT("/cronjob/update", CronJob.UpdateCronJob)
+		k8sRoute.GET("/cronjob/list", CronJob.GetCronJobList)
+		k8sRoute.GET("/cronjob/detail", CronJob.GetCronJobDetail)
+		k8sRoute.PUT("/cronjob/suspend", CronJob.SuspendCronJob)
+		k8sRoute.PUT("/cronjob/resume", CronJob.ResumeCronJob)
+		k8sRoute.POST("/cronjob/trigger", CronJob.TriggerCronJob)
+		k8sRoute.GET("/cronjob/history", CronJob.GetCronJobHistory)
+	}
+	{
+		k8sRoute.POST("/hpa/create", HPA.CreateHPA)
+		k8sRoute.DELETE("/hpa/del", HPA.DeleteHPA)
+		k8sRoute.PUT("/hpa/update", HPA.UpdateHPA)
+		k8sRoute.GET("/hpa/list", HPA.GetHPAList)
+		k8sRoute.GET("/hpa/detail", HPA.GetHPADetail)
 	}
 	{
 		k8sRoute.GET("/node/list", Node.GetNodeList)
@@ -110,4 +153,13 @@ func (k *kubeRouter) initRoutes(ginEngine *gin.RouterGroup) {
 		k8sRoute.GET("/workflow/id", WorkFlow.GetWorkflowByID)
 	}
 
+	{
+		k8sRoute.POST("/apply", Manifest.Apply)
+	}
+	{
+		k8sRoute.GET("/pod/portforward", Proxy.PortForward)
+		k8sRoute.POST("/proxy_ticket", Proxy.IssueProxyTicket)
+		k8sRoute.GET("/proxy/:ticket/:cluster/:namespace/:kind/:name/:port/*path", Proxy.ReverseProxy)
+	}
+
 }
@@ -0,0 +1,43 @@
+package kubeController
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+var Manifest manifest
+
+type manifest struct{}
+
+// Apply 提交yaml或json清单
+// ListPage godoc
+// @Summary      提交yaml或json清单
+// @Description  支持多文档的yaml或json，任意内置或自定义资源，使用服务端apply提交，逐个返回created、configured、unchanged或failed；dry_run为true时只返回每个对象的变更差异
+// @Tags         manifest
+// @ID           /api/k8s/apply
+// @Accept       json
+// @Produce      json
+// @Param        cluster  query  string              false  "集群名称，默认为default"
+// @Param        body     body   kubeDto.ApplyInput  true   "清单内容"
+// @Success      200  {object}  middleware.Response"{"code": 200, msg="","data": []service.ApplyResult}"
+// @Router       /api/k8s/apply [post]
+func (m *manifest) Apply(ctx *gin.Context) {
+	params := &kubeDto.ApplyInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := kube.Manifest.Apply(middleware.GetK8sClient(ctx), params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
@@ -21,6 +21,7 @@ type namespace struct{}
 // @ID           /api/k8s/namespace/create
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name  query  string  true  "namespace名称"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "创建成功}"
 // @Router       /api/k8s/namespace/create [put]
@@ -31,7 +32,7 @@ func (n *namespace) CreateNameSpace(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.NameSpace.CreateNameSpace(params.Name); err != nil {
+	if err := kube.NameSpace.CreateNameSpace(middleware.GetK8sClient(ctx), params.Name); err != nil {
 		v1.Log.ErrorWithCode(globalError.CreateError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
 		return
@@ -47,6 +48,7 @@ func (n *namespace) CreateNameSpace(ctx *gin.Context) {
 // @ID           /api/k8s/namespace/del
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name  query  string  true  "namespace名称"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
 // @Router       /api/k8s/namespace/del [delete]
@@ -57,7 +59,7 @@ func (n *namespace) DeleteNameSpace(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.NameSpace.DeleteNameSpace(params.Name); err != nil {
+	if err := kube.NameSpace.DeleteNameSpace(middleware.GetK8sClient(ctx), params.Name); err != nil {
 		v1.Log.ErrorWithCode(globalError.DeleteError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
 		return
@@ -73,7 +75,13 @@ func (n *namespace) DeleteNameSpace(ctx *gin.Context) {
 // @ID           /api/k8s/namespace/list
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.nodeName=node1"
+// @Param        status       query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by      query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view         query  string  false  "返回视图，table时只返回摘要行"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": service.NameSpaceResp}"
@@ -88,7 +96,7 @@ func (n *namespace) GetNameSpaceList(ctx *gin.Context) {
 	}
 	v1.Log.Infof("GetNameSpaceList params parse success: [{}]", params)
 	// 取出全局的NameSpace对象，调用GetNameSpaces，获取ns列表
-	data, err := kube.NameSpace.GetNameSpaces(params.FilterName, params.Limit, params.Page)
+	data, err := kube.NameSpace.GetNameSpaces(middleware.GetK8sClient(ctx), &params.DataSelectInput)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -105,6 +113,7 @@ func (n *namespace) GetNameSpaceList(ctx *gin.Context) {
 // @ID           /api/k8s/namespace/detail
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name  query  string  true  "namespace名称"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":data }"
 // @Router       /api/k8s/namespace/detail [get]
@@ -115,7 +124,7 @@ func (n *namespace) GetNameSpaceDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.NameSpace.GetNameSpacesDetail(params.Name)
+	data, err := kube.NameSpace.GetNameSpacesDetail(middleware.GetK8sClient(ctx), params.Name)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -21,7 +21,13 @@ type node struct{}
 // @ID           /api/k8s/node/list
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.nodeName=node1"
+// @Param        status       query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by      query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view         query  string  false  "返回视图，table时只返回摘要行"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": service.NameSpaceResp}"
@@ -33,7 +39,7 @@ func (n *node) GetNodeList(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Node.GetNodes(params.FilterName, params.Limit, params.Page)
+	data, err := kube.Node.GetNodes(middleware.GetK8sClient(ctx), &params.DataSelectInput)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -50,6 +56,7 @@ func (n *node) GetNodeList(ctx *gin.Context) {
 // @ID           /api/k8s/node/detail
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name  query  string  true  "node名称"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":data }"
 // @Router       /api/k8s/node/detail [get]
@@ -60,7 +67,7 @@ func (n *node) GetNodeDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Node.GetNodeDetail(params.Name)
+	data, err := kube.Node.GetNodeDetail(middleware.GetK8sClient(ctx), params.Name)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
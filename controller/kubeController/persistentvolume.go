@@ -22,6 +22,7 @@ type persistentVolume struct{}
 // @ID           /api/k8s/spersistentvolume/del
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name  query  string  true  "persistentvolume名称"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
 // @Router       /api/k8s/spersistentvolume/del [delete]
@@ -32,7 +33,7 @@ func (n *persistentVolume) DeletePersistentVolume(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.PersistentVolume.DeletePersistentVolume(params.Name); err != nil {
+	if err := kube.PersistentVolume.DeletePersistentVolume(middleware.GetK8sClient(ctx), params.Name); err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
 		return
@@ -48,7 +49,13 @@ func (n *persistentVolume) DeletePersistentVolume(ctx *gin.Context) {
 // @ID           /api/k8s/persistentvolume/list
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.nodeName=node1"
+// @Param        status       query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by      query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view         query  string  false  "返回视图，table时只返回摘要行"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": service.PersistentVolumeResp}"
@@ -60,7 +67,7 @@ func (n *persistentVolume) GetPersistentVolumeList(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.PersistentVolume.GetPersistentVolumes(params.FilterName, params.Limit, params.Page)
+	data, err := kube.PersistentVolume.GetPersistentVolumes(middleware.GetK8sClient(ctx), &params.DataSelectInput)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -77,6 +84,7 @@ func (n *persistentVolume) GetPersistentVolumeList(ctx *gin.Context) {
 // @ID           /api/k8s/persistentvolume/detail
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        name  query  string  true  "persistentVolume名称"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": *coreV1.PersistentVolume}"
 // @Router       /api/k8s/persistentvolume/detail [get]
@@ -87,7 +95,7 @@ func (n *persistentVolume) GetPersistentVolumeDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.PersistentVolume.GetPersistentVolumesDetail(params.Name)
+	data, err := kube.PersistentVolume.GetPersistentVolumesDetail(middleware.GetK8sClient(ctx), params.Name)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -1,12 +1,24 @@
 package kubeController
 
 import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/dao/model"
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
 	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/types"
+	"github.com/noovertime7/kubemanage/pkg/utils"
 	_ "k8s.io/api/core/v1"
 )
 
@@ -22,7 +34,13 @@ type pod struct{}
 // @ID           /api/k8s/pods
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
+// @Param        label_selector  query  string  false  "标签选择器，如 app=nginx,env!=prod"
+// @Param        field_selector  query  string  false  "字段选择器，如 spec.nodeName=node1"
+// @Param        status       query  string  false  "状态过滤，多个状态以逗号分隔"
+// @Param        sort_by      query  string  false  "排序，如 name:asc,creation:desc"
+// @Param        view         query  string  false  "返回视图，table时只返回摘要行"
 // @Param        namespace    query  string  false  "命名空间"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
@@ -36,7 +54,7 @@ func (p *pod) GetPods(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Pod.GetPods(parmas.FilterName, parmas.NameSpace, parmas.Limit, parmas.Page)
+	data, err := kube.Pod.GetPods(middleware.GetK8sClient(ctx), parmas.NameSpace, &parmas.DataSelectInput)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -53,6 +71,7 @@ func (p *pod) GetPods(ctx *gin.Context) {
 // @ID           /api/k8s/pod/detail
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        pod_name   query  string  true  "POD名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":v1.Pod }"
@@ -65,7 +84,7 @@ func (p *pod) GetPodDetail(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Pod.GetPodDetail(parmas.PodName, parmas.NameSpace)
+	data, err := kube.Pod.GetPodDetail(middleware.GetK8sClient(ctx), parmas.PodName, parmas.NameSpace)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -82,6 +101,7 @@ func (p *pod) GetPodDetail(ctx *gin.Context) {
 // @ID           /api/k8s/pod/del
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        pod_name   query  string  true  "POD名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":"" }"
@@ -93,7 +113,7 @@ func (p *pod) DeletePod(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Pod.DeletePod(params.PodName, params.NameSpace); err != nil {
+	if err := kube.Pod.DeletePod(middleware.GetK8sClient(ctx), params.PodName, params.NameSpace); err != nil {
 		v1.Log.ErrorWithCode(globalError.DeleteError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
 		return
@@ -104,14 +124,17 @@ func (p *pod) DeletePod(ctx *gin.Context) {
 // UpdatePod 更新POD
 // ListPage godoc
 // @Summary      更新POD
-// @Description  更新POD
+// @Description  更新POD，content中必须包含metadata.resourceVersion，版本冲突时返回20105错误码并在data中附带线上最新对象
 // @Tags         pod
 // @ID           /api/k8s/pod/update
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        pod_name   query  string  true  "POD名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Param        content    query  string  true  "更新内容"
+// @Param        dry_run       query  bool    false  "预览模式，只返回变更差异不修改集群"
+// @Param        unified_diff  query  bool    false  "预览时是否返回unified格式的yaml差异"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":"" }"
 // @Router       /api/k8s/pod/update [put]
 func (p *pod) UpdatePod(ctx *gin.Context) {
@@ -121,9 +144,13 @@ func (p *pod) UpdatePod(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	if err := kube.Pod.UpdatePod(params.NameSpace, params.Content); err != nil {
-		v1.Log.ErrorWithCode(globalError.UpdateError, err)
-		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+	preview, err := kube.Pod.UpdatePod(middleware.GetK8sClient(ctx), params.NameSpace, params.Content, params.DryRunInput)
+	if err != nil {
+		responseUpdateError(ctx, err)
+		return
+	}
+	if params.DryRun {
+		middleware.ResponseSuccess(ctx, preview)
 		return
 	}
 	middleware.ResponseSuccess(ctx, "更新成功")
@@ -137,6 +164,7 @@ func (p *pod) UpdatePod(ctx *gin.Context) {
 // @ID           /api/k8s/pod/container
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        pod_name   query  string  true  "POD名称"
 // @Param        namespace  query  string  true  "命名空间"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":"" }"
@@ -148,7 +176,7 @@ func (p *pod) GetPodContainer(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Pod.GetPodContainer(params.PodName, params.NameSpace)
+	data, err := kube.Pod.GetPodContainer(middleware.GetK8sClient(ctx), params.PodName, params.NameSpace)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -165,6 +193,7 @@ func (p *pod) GetPodContainer(ctx *gin.Context) {
 // @ID           /api/k8s/pod/log
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        pod_name        query  string  true  "POD名称"
 // @Param        namespace       query  string  true  "命名空间"
 // @Param        container_name  query  string  true  "容器名"
@@ -177,7 +206,7 @@ func (p *pod) GetPodLog(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
-	data, err := kube.Pod.GetPodLog(params.ContainerName, params.PodName, params.NameSpace)
+	data, err := kube.Pod.GetPodLog(middleware.GetK8sClient(ctx), params.ContainerName, params.PodName, params.NameSpace)
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.UpdateError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
@@ -186,6 +215,155 @@ func (p *pod) GetPodLog(ctx *gin.Context) {
 	middleware.ResponseSuccess(ctx, data)
 }
 
+// StreamPodLog 通过websocket推送容器日志
+// ListPage godoc
+// @Summary      通过websocket推送容器日志
+// @Description  升级为websocket后持续推送operation为stdout的日志消息，日志结束时推送done，出错时推送error，客户端断开后停止读取日志
+// @Tags         pod
+// @ID           /api/k8s/pod/log/stream
+// @Accept       json
+// @Produce      json
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        pod_name        query  string  true   "POD名称"
+// @Param        namespace       query  string  true   "命名空间"
+// @Param        container_name  query  string  true   "容器名"
+// @Param        follow          query  bool    false  "是否持续跟踪日志"
+// @Param        tail_lines      query  int     false  "返回最后的行数，0使用默认配置，-1返回全部"
+// @Param        since_seconds   query  int     false  "只返回最近多少秒的日志"
+// @Param        since_time      query  string  false  "只返回该时间之后的日志，RFC3339格式"
+// @Param        timestamps      query  bool    false  "是否附带时间戳"
+// @Param        previous        query  bool    false  "是否查看上一次重启前的容器日志"
+// @Router       /api/k8s/pod/log/stream [get]
+func (p *pod) StreamPodLog(ctx *gin.Context) {
+	params := &kubeDto.PodLogStreamInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	opts, err := kube.NewPodLogOptions(params.ContainerName, &params.PodLogOptionsInput)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	cli := middleware.GetK8sClient(ctx)
+	serveStream(ctx, func(streamCtx context.Context, send func(operation, data string) error) error {
+		stream, err := kube.Pod.StreamPodLog(streamCtx, cli, params.PodName, params.NameSpace, opts)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		return kube.PumpLog(stream, func(data string) error {
+			return send("stdout", data)
+		})
+	})
+}
+
+// DownloadPodLog 下载容器日志
+// ListPage godoc
+// @Summary      下载容器日志
+// @Description  以附件形式流式下载容器日志，默认下载全部日志，支持按时间范围和正则在服务端过滤，可选gzip压缩
+// @Tags         pod
+// @ID           /api/k8s/pod/log/download
+// @Accept       json
+// @Produce      octet-stream
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        pod_name        query  string  true   "POD名称"
+// @Param        namespace       query  string  true   "命名空间"
+// @Param        container_name  query  string  true   "容器名"
+// @Param        tail_lines      query  int     false  "返回最后的行数，0返回全部"
+// @Param        since_seconds   query  int     false  "只返回最近多少秒的日志"
+// @Param        since_time      query  string  false  "只返回该时间之后的日志，RFC3339格式"
+// @Param        until_time      query  string  false  "只返回该时间之前的日志，RFC3339格式"
+// @Param        include         query  string  false  "只保留匹配该正则的行"
+// @Param        exclude         query  string  false  "去掉匹配该正则的行"
+// @Param        timestamps      query  bool    false  "是否附带时间戳"
+// @Param        previous        query  bool    false  "是否下载上一次重启前的容器日志"
+// @Param        gzip            query  bool    false  "是否gzip压缩"
+// @Success      200        {file}  file
+// @Router       /api/k8s/pod/log/download [get]
+func (p *pod) DownloadPodLog(ctx *gin.Context) {
+	params := &kubeDto.PodLogDownloadInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	opts, filter, err := kube.NewPodLogDownload(params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	stream, err := kube.Pod.StreamPodLog(ctx.Request.Context(), middleware.GetK8sClient(ctx), params.PodName, params.NameSpace, opts)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	defer stream.Close()
+
+	// 开始写入后无法再返回json格式的错误，只记录日志
+	filename := fmt.Sprintf("%s_%s_%s.log", params.PodName, params.ContainerName, time.Now().Format("20060102150405"))
+	var w io.Writer = ctx.Writer
+	if params.Gzip {
+		gz := gzip.NewWriter(ctx.Writer)
+		defer gz.Close()
+		w = gz
+		filename += ".gz"
+		ctx.Header("Content-Type", "application/gzip")
+	} else {
+		ctx.Header("Content-Type", "text/plain; charset=utf-8")
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(http.StatusOK)
+	if err := kube.CopyLog(w, stream, filter); err != nil && ctx.Request.Context().Err() == nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+	}
+}
+
+// AggregatePodLogs 聚合推送多个pod的日志
+// ListPage godoc
+// @Summary      聚合推送多个pod的日志
+// @Description  按标签选择器或工作负载选择pod，升级为websocket后同时推送所有匹配容器的日志，每行以[pod/container]为前缀，operation为stdout；
+// @Description  开始或停止跟踪容器时推送operation为event的消息，follow模式下自动跟踪新增的pod并停止跟踪已删除的pod
+// @Tags         pod
+// @ID           /api/k8s/pod/log/aggregate
+// @Accept       json
+// @Produce      json
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        namespace       query  string  true   "命名空间"
+// @Param        label_selector  query  string  false  "标签选择器，与工作负载二选一"
+// @Param        workload_kind   query  string  false  "工作负载类型，deployment、statefulset或daemonset"
+// @Param        workload_name   query  string  false  "工作负载名称"
+// @Param        container       query  string  false  "容器名称的正则表达式，为空时跟踪所有容器"
+// @Param        follow          query  bool    false  "是否持续跟踪日志"
+// @Param        tail_lines      query  int     false  "每个容器返回最后的行数，0使用默认配置，-1返回全部"
+// @Param        since_seconds   query  int     false  "只返回最近多少秒的日志"
+// @Param        since_time      query  string  false  "只返回该时间之后的日志，RFC3339格式"
+// @Param        timestamps      query  bool    false  "是否附带时间戳"
+// @Param        previous        query  bool    false  "是否查看上一次重启前的容器日志"
+// @Router       /api/k8s/pod/log/aggregate [get]
+func (p *pod) AggregatePodLogs(ctx *gin.Context) {
+	params := &kubeDto.PodLogAggregateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	cli := middleware.GetK8sClient(ctx)
+	query, err := kube.NewAggregateQuery(cli, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	serveStream(ctx, func(streamCtx context.Context, send func(operation, data string) error) error {
+		return kube.Pod.AggregatePodLogs(streamCtx, cli, query, send)
+	})
+}
+
 // GetPodNumPreNp 根据命名空间获取数量
 // ListPage godoc
 // @Summary      根据命名空间获取数量
@@ -194,10 +372,11 @@ func (p *pod) GetPodLog(ctx *gin.Context) {
 // @ID           /api/k8s/pod/numnp
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":[]service.PodsNp }"
 // @Router       /api/k8s/pod/numnp [get]
 func (p *pod) GetPodNumPreNp(ctx *gin.Context) {
-	data, err := kube.Pod.GetPodNumPerNp()
+	data, err := kube.Pod.GetPodNumPerNp(middleware.GetK8sClient(ctx))
 	if err != nil {
 		v1.Log.ErrorWithCode(globalError.GetError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
@@ -206,13 +385,267 @@ func (p *pod) GetPodNumPreNp(ctx *gin.Context) {
 	middleware.ResponseSuccess(ctx, data)
 }
 
+// WebShell web终端
+// ListPage godoc
+// @Summary      web终端
+// @Description  升级为websocket后在容器中执行shell，未指定命令时依次尝试配置的shell(默认bash、sh、ash、zsh)，指定命令时命令及全部参数需要与配置的允许列表中的某一项完全一致；
+// @Description  失败时推送operation为error的消息，data为包含code和message的json，code取值为shell_not_found、command_not_allowed、exec_failed；
+// @Description  会话的输入、输出和窗口大小调整以asciinema v2格式录像，可以在录像接口中查询和回放；
+// @Description  超过并发会话数限制时推送code为session_limit的错误，空闲超时前在终端中提醒，超时后发送关闭帧断开；
+// @Description  回车时按命名空间和角色生效的命令策略检查输入的命令行，拦截或要求再次回车确认，命中策略的命令写入操作记录
+// @Tags         pod
+// @ID           /api/k8s/pod/webshell
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        pod_name        query  string  true   "POD名称"
+// @Param        namespace       query  string  true   "命名空间"
+// @Param        container_name  query  string  false  "容器名"
+// @Param        command         query  string  false  "自定义命令，按空白分隔参数"
+// @Router       /api/k8s/pod/webshell [get]
 func (p *pod) WebShell(ctx *gin.Context) {
 	ops := &kubeDto.WebShellOptions{}
 	if err := ops.BindingValidParams(ctx); err != nil {
 		v1.Log.ErrorWithCode(globalError.ServerError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	// 除接口权限外，还需要有目标命名空间的web终端权限
+	if err := middleware.CasbinEnforce(ctx, pkg.WebShellNamespaceObj+ops.Namespace, ctx.Request.Method); err != nil {
+		v1.Log.ErrorWithCode(globalError.AuthErr, err)
+		middleware.ResponseError(ctx, err)
+		return
+	}
+	claims := utils.GetUserInfo(ctx)
+	cluster := middleware.GetK8sClient(ctx).Name
+	v1.Log.Info(fmt.Sprintf("用户 %s 打开web终端 集群: %s pod: %s/%s 容器: %s 命令: %s", claims.Username, cluster, ops.Namespace, ops.Pod, ops.Container, ops.Command))
+	rules, err := v1.CoreV1.System().CommandPolicy().CommandRules(ctx, ops.Namespace, claims.AuthorityId)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	// 服务关闭时等待会话的录像保存完成
+	end, err := types.TerminalSessions.Begin()
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
+		return
+	}
+	defer end()
+	// 所有会话都需要录像，无法创建录像时不允许打开终端
+	record := &model.TerminalRecording{
+		UserID:    claims.ID,
+		Username:  claims.Username,
+		Cluster:   cluster,
+		Namespace: ops.Namespace,
+		Pod:       ops.Pod,
+		Container: ops.Container,
+		Command:   ops.Command,
+	}
+	recorder, err := v1.CoreV1.System().Recording().Start(ctx, record)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	defer func() {
+		// 未指定容器时WebShellHandler会解析出实际使用的容器
+		record.Container = ops.Container
+		if err := v1.CoreV1.System().Recording().Finish(context.Background(), record, recorder); err != nil {
+			v1.Log.ErrorWithErr(fmt.Sprintf("保存web终端录像 %d 失败", record.ID), err)
+		}
+	}()
+	meta := &types.TerminalSessionMeta{
+		UserID:      claims.ID,
+		Username:    claims.Username,
+		Cluster:     cluster,
+		Namespace:   ops.Namespace,
+		Pod:         ops.Pod,
+		Command:     ops.Command,
+		RecordingID: record.ID,
+	}
+	guard := types.NewCommandGuard(rules, func(event types.CommandEvent) {
+		recordCommandEvent(ctx, claims.ID, meta, event)
+	})
+	pods, err := v1.CoreV1.Cloud().Pods(cluster)
+	if err != nil {
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
+		return
 	}
-	if err := v1.CoreV1.Cloud().Pods("").WebShellHandler(ops, ctx.Writer, ctx.Request); err != nil {
+	if err := pods.WebShellHandler(ops, meta, recorder, guard, ctx.Writer, ctx.Request); err != nil {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
 	}
 }
+
+// recordCommandEvent 将web终端中命中命令策略的命令写入操作记录
+func recordCommandEvent(ctx *gin.Context, userID int, meta *types.TerminalSessionMeta, event types.CommandEvent) {
+	v1.Log.Info(fmt.Sprintf("用户 %s 在web终端 %s/%s/%s 中执行的命令 %q 命中策略 %s: %s",
+		meta.Username, meta.Namespace, meta.Pod, meta.Container, event.Command, event.Rule, event.Outcome))
+	if err := v1.CoreV1.System().CommandPolicy().RecordCommandEvent(ctx, userID, meta, event); err != nil {
+		v1.Log.ErrorWithErr("保存web终端命令审计记录失败", err)
+	}
+}
+
+// DebugPod 向pod注入临时调试容器
+// ListPage godoc
+// @Summary      向pod注入临时调试容器
+// @Description  通过ephemeralcontainers子资源向运行中的pod注入开启了tty的调试容器，等待其启动后返回容器名称；
+// @Description  web终端的container_name指定该容器时自动attach到调试容器，适用于没有shell的distroless镜像，需要kubernetes 1.23及以上版本；
+// @Description  除接口权限外，还需要有目标命名空间的web终端权限
+// @Tags         pod
+// @ID           /api/k8s/pod/debug
+// @Accept       json
+// @Produce      json
+// @Param        cluster  query  string                  false  "集群名称，默认为default"
+// @Param        body     body   kubeDto.PodDebugInput  true   "调试容器参数"
+// @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":kube.DebugContainer }"
+// @Router       /api/k8s/pod/debug [post]
+func (p *pod) DebugPod(ctx *gin.Context) {
+	params := &kubeDto.PodDebugInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	// 调试容器等同于在该命名空间打开web终端，需要有目标命名空间的web终端权限
+	if err := middleware.CasbinEnforce(ctx, pkg.WebShellNamespaceObj+params.NameSpace, http.MethodGet); err != nil {
+		v1.Log.ErrorWithCode(globalError.AuthErr, err)
+		middleware.ResponseError(ctx, err)
+		return
+	}
+	data, err := kube.Pod.DebugPod(middleware.GetK8sClient(ctx), params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// ListFiles 浏览容器内的目录
+// ListPage godoc
+// @Summary      浏览容器内的目录
+// @Description  在容器中执行stat列出目录下的文件，目录排在前面，依赖容器中的sh和stat命令
+// @Tags         pod
+// @ID           /api/k8s/pod/file/list
+// @Accept       json
+// @Produce      json
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        pod_name        query  string  true   "POD名称"
+// @Param        namespace       query  string  true   "命名空间"
+// @Param        container_name  query  string  false  "容器名，为空时使用默认容器"
+// @Param        path            query  string  true   "容器内目录的绝对路径"
+// @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":[]kube.ContainerFile }"
+// @Router       /api/k8s/pod/file/list [get]
+func (p *pod) ListFiles(ctx *gin.Context) {
+	params := &kubeDto.PodFileInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	pods, err := v1.CoreV1.Cloud().Pods(middleware.GetK8sClient(ctx).Name)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	data, err := pods.ListFiles(params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DownloadFile 下载容器内的文件或目录
+// ListPage godoc
+// @Summary      下载容器内的文件或目录
+// @Description  在容器中执行tar将文件或目录打包，以tar附件的形式流式返回，依赖容器中的tar命令
+// @Tags         pod
+// @ID           /api/k8s/pod/file/download
+// @Accept       json
+// @Produce      octet-stream
+// @Param        cluster         query  string  false  "集群名称，默认为default"
+// @Param        pod_name        query  string  true   "POD名称"
+// @Param        namespace       query  string  true   "命名空间"
+// @Param        container_name  query  string  false  "容器名，为空时使用默认容器"
+// @Param        path            query  string  true   "容器内文件或目录的绝对路径"
+// @Success      200        {file}  file
+// @Router       /api/k8s/pod/file/download [get]
+func (p *pod) DownloadFile(ctx *gin.Context) {
+	params := &kubeDto.PodFileInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	pods, err := v1.CoreV1.Cloud().Pods(middleware.GetK8sClient(ctx).Name)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	w := &attachmentWriter{
+		ctx:         ctx,
+		filename:    path.Base(params.Path) + ".tar",
+		contentType: "application/x-tar",
+	}
+	if err := pods.DownloadFile(params, w); err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		// 已经开始下载时无法再返回json格式的错误
+		if !w.written {
+			middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		}
+	}
+}
+
+// UploadFile 上传文件到容器内的目录
+// ListPage godoc
+// @Summary      上传文件到容器内的目录
+// @Description  将上传的文件打包为tar后在容器中解压到指定目录，目录不存在时自动创建，同名文件会被覆盖，依赖容器中的sh和tar命令
+// @Tags         pod
+// @ID           /api/k8s/pod/file/upload
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        cluster         query     string  false  "集群名称，默认为default"
+// @Param        pod_name        formData  string  true   "POD名称"
+// @Param        namespace       formData  string  true   "命名空间"
+// @Param        container_name  formData  string  false  "容器名，为空时使用默认容器"
+// @Param        path            formData  string  true   "容器内目标目录的绝对路径"
+// @Param        file            formData  file    true   "上传的文件"
+// @Success      200        {object}  middleware.Response"{"code": 200, msg="","data":"上传成功" }"
+// @Router       /api/k8s/pod/file/upload [post]
+func (p *pod) UploadFile(ctx *gin.Context) {
+	params := &kubeDto.PodFileInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	pods, err := v1.CoreV1.Cloud().Pods(middleware.GetK8sClient(ctx).Name)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
+		return
+	}
+	defer file.Close()
+	if err := pods.UploadFile(params, fileHeader.Filename, fileHeader.Size, file); err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "上传成功")
+}
@@ -0,0 +1,167 @@
+package kubeController
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/types"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+var Proxy proxy
+
+type proxy struct{}
+
+// PortForward 通过websocket转发pod端口
+// ListPage godoc
+// @Summary      通过websocket转发pod端口
+// @Description  建立到pod端口的SPDY端口转发，升级为websocket后以二进制消息双向转发tcp数据，一个websocket连接对应一个tcp连接；
+// @Description  会话结束后记录到操作记录中，耗时即为会话时长
+// @Tags         proxy
+// @ID           /api/k8s/pod/portforward
+// @Accept       json
+// @Produce      json
+// @Param        cluster    query  string  false  "集群名称，默认为default"
+// @Param        pod_name   query  string  true   "POD名称"
+// @Param        namespace  query  string  true   "命名空间"
+// @Param        port       query  int     true   "pod端口"
+// @Router       /api/k8s/pod/portforward [get]
+func (p *proxy) PortForward(ctx *gin.Context) {
+	params := &kubeDto.PodPortForwardInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	conn, err := kube.PortForward.Dial(middleware.GetK8sClient(ctx), params.NameSpace, params.PodName, params.Port)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
+		return
+	}
+	session, err := types.NewTunnelSession(ctx.Writer, ctx.Request)
+	if err != nil {
+		_ = conn.Close()
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		return
+	}
+	sent, received, err := session.Bridge(conn)
+	if podErr := conn.Err(); podErr != nil {
+		err = podErr
+	}
+	username := ""
+	if claims, err := utils.GetClaims(ctx); err == nil {
+		username = claims.Username
+	}
+	v1.Log.Info(fmt.Sprintf("用户 %s 转发 %s/%s:%d 结束，上行 %d 字节，下行 %d 字节", username, params.NameSpace, params.PodName, params.Port, sent, received))
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		// 记录到操作记录的错误信息中
+		_ = ctx.Error(err)
+	}
+}
+
+// IssueProxyTicket 签发反向代理票据
+// ListPage godoc
+// @Summary      签发反向代理票据
+// @Description  反向代理只接受路径中的票据认证，不接受token；票据只能访问签发时指定的pod或service端口，10分钟内有效；
+// @Description  同时下发路径限定在代理地址下的HttpOnly cookie，票据只能在签发它的浏览器中使用；
+// @Description  通过https访问时cookie为SameSite=None，http部署时浏览器可能不会在沙箱页面的子资源请求中携带该cookie
+// @Tags         proxy
+// @ID           /api/k8s/proxy_ticket
+// @Accept       json
+// @Produce      json
+// @Param        cluster  query  string                    false  "集群名称，默认为default"
+// @Param        body     body   kubeDto.ProxyTicketInput  true   "body"
+// @Success      200  {object}  middleware.Response{data=kubeDto.ProxyTicketOut} "success"
+// @Router       /api/k8s/proxy_ticket [post]
+func (p *proxy) IssueProxyTicket(ctx *gin.Context) {
+	params := &kubeDto.ProxyTicketInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims, err := utils.GetClaims(ctx)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.AuthorizationError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.AuthorizationError, err))
+		return
+	}
+	target := path.Join(middleware.GetK8sClient(ctx).Name, params.NameSpace, params.Kind, params.Name, params.Port)
+	ticket, key, err := pkg.ProxyTicket.Issue(claims, target)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
+		return
+	}
+	setProxyTicketCookie(ctx, ticket, key)
+	middleware.ResponseSuccess(ctx, &kubeDto.ProxyTicketOut{
+		Ticket:    ticket,
+		URL:       pkg.K8sProxyURLPrefix + ticket + "/" + target + "/",
+		ExpiresIn: int64(pkg.ProxyTicketTTL.Seconds()),
+	})
+}
+
+// setProxyTicketCookie 下发与票据绑定的密钥，路径限定在该票据的代理地址下；
+// 目标页面在沙箱中运行，子资源请求属于跨站请求，只有SameSite=None的cookie会被携带，而该模式要求https
+func setProxyTicketCookie(ctx *gin.Context, ticket, key string) {
+	secure := ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+	sameSite := http.SameSiteLaxMode
+	if secure {
+		sameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     pkg.ProxyTicketCookie,
+		Value:    key,
+		Path:     pkg.K8sProxyURLPrefix + ticket + "/",
+		MaxAge:   int(pkg.ProxyTicketTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSite,
+	})
+}
+
+// ReverseProxy 反向代理到pod或service的端口
+// ListPage godoc
+// @Summary      反向代理到pod或service的端口
+// @Description  通过apiserver的proxy子资源将GET请求转发到pod或service的端口，路径中port之后的部分作为目标路径；
+// @Description  通过路径中的票据和签发票据时下发的cookie认证，票据由 /api/k8s/proxy_ticket 签发，日志和操作记录中的票据会被替换为***；
+// @Description  响应附带 Content-Security-Policy: sandbox 和 Referrer-Policy: no-referrer，目标页面中的脚本无法访问本服务的页面和接口；
+// @Description  每个请求都会记录到操作记录中，但不记录响应内容
+// @Tags         proxy
+// @ID           /api/k8s/proxy
+// @Param        ticket     path  string  true  "代理票据"
+// @Param        cluster    path  string  true  "集群名称"
+// @Param        namespace  path  string  true  "命名空间"
+// @Param        kind       path  string  true  "资源类型，pods或services"
+// @Param        name       path  string  true  "pod或service名称"
+// @Param        port       path  string  true  "端口，service可以使用端口名称"
+// @Param        path       path  string  true  "目标路径"
+// @Router       /api/k8s/proxy/{ticket}/{cluster}/{namespace}/{kind}/{name}/{port}/{path} [get]
+func (p *proxy) ReverseProxy(ctx *gin.Context) {
+	params := &kubeDto.ProxyInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	prefix := strings.TrimSuffix(ctx.Request.URL.Path, ctx.Param("path"))
+	reverseProxy, err := kube.Proxy.NewReverseProxy(middleware.GetK8sClient(ctx), params.Kind, params.NameSpace, params.Name, params.Port, prefix)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
+		return
+	}
+	reverseProxy.ServeHTTP(ctx.Writer, ctx.Request)
+}
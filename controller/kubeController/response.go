@@ -0,0 +1,44 @@
+package kubeController
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// responseUpdateError 更新失败时的统一响应，资源版本冲突时返回ConflictError错误码，并在data中附带线上最新对象
+func responseUpdateError(ctx *gin.Context, err error) {
+	var conflict *kube.ConflictError
+	if errors.As(err, &conflict) {
+		v1.Log.ErrorWithCode(globalError.ConflictError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalErrorWithData(globalError.ConflictError, err, conflict.Live))
+		return
+	}
+	v1.Log.ErrorWithCode(globalError.UpdateError, err)
+	middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+}
+
+// attachmentWriter 第一次写入时才设置附件下载的响应头，写入之前出错时仍然可以返回json格式的错误
+type attachmentWriter struct {
+	ctx         *gin.Context
+	filename    string
+	contentType string
+	written     bool
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.written = true
+		w.ctx.Header("Content-Type", w.contentType)
+		w.ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.filename))
+		w.ctx.Status(http.StatusOK)
+	}
+	return w.ctx.Writer.Write(p)
+}
@@ -0,0 +1,51 @@
+package kubeController
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/types"
+)
+
+// streamWriteTimeout 推送单条消息的超时时间，客户端长时间不读取时断开连接，避免服务端一直阻塞
+const streamWriteTimeout = 30 * time.Second
+
+// serveStream 将请求升级为websocket，调用stream持续推送消息，客户端断开时取消stream的ctx；
+// stream正常结束时推送operation为done的消息，出错时推送operation为error的消息
+func serveStream(ctx *gin.Context, stream func(ctx context.Context, send func(operation, data string) error) error) {
+	session, err := types.NewTerminalSession(ctx.Writer, ctx.Request)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		return
+	}
+	defer session.Close()
+
+	streamCtx, cancel := context.WithCancel(ctx.Request.Context())
+	defer cancel()
+	go func() {
+		session.WaitClose()
+		cancel()
+	}()
+
+	send := func(operation, data string) error {
+		if err := session.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+			return err
+		}
+		return session.WriteMessage(operation, data)
+	}
+	err = stream(streamCtx, send)
+	// 客户端已断开，无需再推送
+	if streamCtx.Err() != nil {
+		return
+	}
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		_ = send("error", err.Error())
+		return
+	}
+	_ = send("done", "")
+}
@@ -21,6 +21,7 @@ type workflow struct{}
 // @ID           /api/k8s/workflow/create
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        body  body  kubernetes.WorkFlowCreateInput  true  "body"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "创建成功}"
 // @Router       /api/k8s/workflow/create [post]
@@ -31,6 +32,8 @@ func (w *workflow) CreateWorkFlow(ctx *gin.Context) {
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
 		return
 	}
+	// workflow创建在cluster参数选定的集群中
+	params.Cluster = middleware.GetK8sClient(ctx).Name
 	if err := v1.CoreV1.WorkFlow().Save(ctx, params); err != nil {
 		v1.Log.ErrorWithCode(globalError.CreateError, err)
 		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
@@ -47,6 +50,7 @@ func (w *workflow) CreateWorkFlow(ctx *gin.Context) {
 // @ID           /api/k8s/workflow/del
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        ID       query  int  true  "Workflow ID"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": "删除成功}"
 // @Router       /api/k8s/workflow/del [delete]
@@ -73,6 +77,7 @@ func (w *workflow) DeleteWorkflow(ctx *gin.Context) {
 // @ID           /api/k8s/workflow/list
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        filter_name  query  string  false  "过滤"
 // @Param        page         query  int     false  "页码"
 // @Param        limit        query  int     false  "分页限制"
@@ -102,6 +107,7 @@ func (w *workflow) GetWorkflowList(ctx *gin.Context) {
 // @ID           /api/k8s/workflow/id
 // @Accept       json
 // @Produce      json
+// @Param        cluster      query  string  false  "集群名称，默认为default"
 // @Param        ID       query  int  true  "Workflow ID"
 // @Success       200  {object}  middleware.Response"{"code": 200, msg="","data": }"
 // @Router       /api/k8s/workflow/id [get]
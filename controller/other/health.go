@@ -0,0 +1,43 @@
+package other
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+)
+
+// NewHealthRoute 安装就绪检查路由，不经过认证，供 kubernetes readinessProbe 使用
+func NewHealthRoute(ginEngine *gin.Engine) {
+	ginEngine.GET("/readyz", Readyz)
+}
+
+// ReadyzResp 就绪检查响应体
+type ReadyzResp struct {
+	// Ready 本地集群的缓存同步完成时为true
+	Ready bool `json:"ready"`
+	// Clusters 每个集群的缓存同步状态，导入的集群未就绪不影响Ready
+	Clusters map[string]bool `json:"clusters"`
+}
+
+// Readyz 就绪检查
+// @Summary      就绪检查
+// @Description  本地集群的缓存同步完成后返回200，否则返回503；导入的集群只在clusters中返回同步状态，
+// @Description  未就绪时不影响就绪检查，访问该集群的接口会返回缓存未同步的错误
+// @Tags         Other
+// @ID           /readyz
+// @Produce      json
+// @Success      200  {object}  other.ReadyzResp
+// @Failure      503  {object}  other.ReadyzResp
+// @Router       /readyz [get]
+func Readyz(ctx *gin.Context) {
+	// 导入的集群不可达时不能让整个服务一直处于未就绪状态
+	clusters := kube.Clusters.Synced()
+	resp := &ReadyzResp{Ready: clusters[kube.DefaultClusterName], Clusters: clusters}
+	if !resp.Ready {
+		ctx.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,107 @@
+package recording
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// GetRecordingList
+// @Tags      TerminalRecording
+// @Summary   分页获取web终端会话录像列表
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     data  query     dto.RecordingListInput                        true  "页码, 每页大小, 用户名, 集群, 命名空间, pod"
+// @Success   200   {object}  middleware.Response{data=dto.RecordingListOutPut,msg=string}  "分页获取录像列表,返回包括列表,总数,页码,每页数量"
+// @Router    /api/recording/get_recordings [get]
+func (r *recordingController) GetRecordingList(ctx *gin.Context) {
+	params := &dto.RecordingListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.System().Recording().GetPageList(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithErr("查询失败", err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// DownloadRecording
+// @Tags      TerminalRecording
+// @Summary   下载web终端会话录像
+// @Description  以附件形式返回asciinema v2格式(.cast)的原始录像，可以直接使用 asciinema play 回放
+// @Security  ApiKeyAuth
+// @Produce   octet-stream
+// @Param     id   path      int  true  "录像id"
+// @Success   200  {file}    file
+// @Router    /api/recording/{id}/download [get]
+func (r *recordingController) DownloadRecording(ctx *gin.Context) {
+	id, err := utils.ParseInt(ctx.Param("id"))
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	record, reader, err := v1.CoreV1.System().Recording().Open(ctx, id)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	defer reader.Close()
+	filename := fmt.Sprintf("%s-%s-%s.cast", record.Pod, record.Container, record.StartedAt.Format("20060102150405"))
+	ctx.Header("Content-Type", "application/x-asciicast")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, reader); err != nil {
+		v1.Log.ErrorWithErr(fmt.Sprintf("下载录像 %d 失败", id), err)
+	}
+}
+
+// PlayRecording
+// @Tags      TerminalRecording
+// @Summary   回放web终端会话录像
+// @Description  返回解析后的录像，header为asciinema v2首行，events中每一项为 [相对开始的秒数, 类型, 数据]，
+// @Description  类型o为终端输出，i为用户输入，r为窗口大小调整(数据为 列x行)，m为控制权移交的标记(之后的输入来自该用户)，前端按时间依次写入终端即可回放；
+// @Description  事件分页返回，more为true时以next作为offset获取下一页，完整的大录像请使用下载接口
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     id      path      int  true   "录像id"
+// @Param     offset  query     int  false  "跳过的事件数量"
+// @Param     limit   query     int  false  "每页事件数量，默认1000，最大5000"
+// @Success   200  {object}  middleware.Response{data=recording.Cast,msg=string}  "解析后的录像"
+// @Router    /api/recording/{id}/play [get]
+func (r *recordingController) PlayRecording(ctx *gin.Context) {
+	id, err := utils.ParseInt(ctx.Param("id"))
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	params := &dto.RecordingPlayInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.System().Recording().Play(ctx, id, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
@@ -0,0 +1,22 @@
+package recording
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type recordingController struct{}
+
+func NewRecordingRouter(ginEngine *gin.RouterGroup) {
+	rec := recordingController{}
+	rec.initRoutes(ginEngine)
+}
+
+func (r *recordingController) initRoutes(ginEngine *gin.RouterGroup) {
+	recordingRoute := ginEngine.Group("/recording")
+	rec := &recordingController{}
+	{
+		recordingRoute.GET("/get_recordings", rec.GetRecordingList)
+		recordingRoute.GET("/:id/download", rec.DownloadRecording)
+		recordingRoute.GET("/:id/play", rec.PlayRecording)
+	}
+}
@@ -1,6 +1,8 @@
 package user
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
 	"strconv"
@@ -104,6 +106,34 @@ func (u *userController) GetUserInfo(ctx *gin.Context) {
 	middleware.ResponseSuccess(ctx, userInfo)
 }
 
+// IssueWebSocketTicket
+// @Tags      SysUser
+// @Summary   签发websocket一次性票据
+// @Description 浏览器无法为websocket设置token请求头，建立websocket连接前先调用该接口获取票据，连接时通过ticket查询参数携带，票据30秒内有效且只能使用一次
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Success   200  {object}  middleware.Response{data=dto.WebSocketTicketOut}  "success"
+// @Router    /api/user/ws_ticket [post]
+func (u *userController) IssueWebSocketTicket(ctx *gin.Context) {
+	claims := utils.GetUserInfo(ctx)
+	if claims == nil {
+		v1.Log.ErrorWithCode(globalError.AuthorizationError, errors.New("获取用户信息失败"))
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.AuthorizationError, errors.New("获取用户信息失败")))
+		return
+	}
+	ticket, err := pkg.WebSocketTicket.Issue(claims)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.ServerError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ServerError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, &dto.WebSocketTicketOut{
+		Ticket:    ticket,
+		ExpiresIn: int64(pkg.WebSocketTicketTTL.Seconds()),
+	})
+}
+
 // SetUserAuthority
 // @Tags      SysUser
 // @Summary   更改用户权限
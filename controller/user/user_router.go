@@ -16,6 +16,7 @@ func (u *userController) initRoutes(ginEngine *gin.RouterGroup) {
 		userRoute.POST("/login", user.Login)
 		userRoute.GET("/loginout", user.LoginOut)
 		userRoute.GET("/getinfo", user.GetUserInfo)
+		userRoute.POST("/ws_ticket", user.IssueWebSocketTicket)
 		userRoute.PUT("/:id/set_auth", user.SetUserAuthority)
 		userRoute.DELETE("/:id/delete_user", user.DeleteUser)
 		userRoute.POST("/:id/change_pwd", user.ChangePassword)
@@ -0,0 +1,109 @@
+package webshell
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// GetPolicyList
+// @Tags      WebShell
+// @Summary   查询web终端命令策略
+// @Description  keyword按策略名称和正则表达式模糊查询
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     data  query     dto.CommandPolicyListInput  true  "分页参数"
+// @Success   200   {object}  middleware.Response{data=dto.CommandPolicyListOutPut,msg=string}  "命令策略列表"
+// @Router    /api/webshell/policy/list [get]
+func (w *webShellController) GetPolicyList(ctx *gin.Context) {
+	params := &dto.CommandPolicyListInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	data, err := v1.CoreV1.System().CommandPolicy().GetPageList(ctx, params)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, data)
+}
+
+// CreatePolicy
+// @Tags      WebShell
+// @Summary   创建web终端命令策略
+// @Description  命令行在回车时匹配正则表达式，action为block时拦截，为confirm时需要再次回车确认；
+// @Description  namespaces和authority_ids为空时对所有命名空间、所有角色生效，修改后对新打开的web终端生效
+// @Security  ApiKeyAuth
+// @Accept    application/json
+// @Produce   application/json
+// @Param     data  body      dto.CommandPolicyInput  true  "命令策略"
+// @Success   200   {object}  middleware.Response{msg=string}  "创建成功"
+// @Router    /api/webshell/policy/create [post]
+func (w *webShellController) CreatePolicy(ctx *gin.Context) {
+	params := &dto.CommandPolicyInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.System().CommandPolicy().Create(ctx, params); err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "创建成功")
+}
+
+// UpdatePolicy
+// @Tags      WebShell
+// @Summary   更新web终端命令策略
+// @Description  修改后对新打开的web终端生效
+// @Security  ApiKeyAuth
+// @Accept    application/json
+// @Produce   application/json
+// @Param     data  body      dto.CommandPolicyUpdateInput  true  "命令策略"
+// @Success   200   {object}  middleware.Response{msg=string}  "更新成功"
+// @Router    /api/webshell/policy/update [put]
+func (w *webShellController) UpdatePolicy(ctx *gin.Context) {
+	params := &dto.CommandPolicyUpdateInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.System().CommandPolicy().Update(ctx, params); err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "更新成功")
+}
+
+// DeletePolicy
+// @Tags      WebShell
+// @Summary   删除web终端命令策略
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     id   query     int  true  "策略ID"
+// @Success   200  {object}  middleware.Response{msg=string}  "删除成功"
+// @Router    /api/webshell/policy/del [delete]
+func (w *webShellController) DeletePolicy(ctx *gin.Context) {
+	params := &dto.CommandPolicyIDInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	if err := v1.CoreV1.System().CommandPolicy().Delete(ctx, params.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	middleware.ResponseSuccess(ctx, "删除成功")
+}
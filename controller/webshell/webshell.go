@@ -0,0 +1,208 @@
+package webshell
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/middleware"
+	"github.com/noovertime7/kubemanage/pkg"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+	"github.com/noovertime7/kubemanage/pkg/types"
+	"github.com/noovertime7/kubemanage/pkg/utils"
+)
+
+// ListSessions
+// @Tags      WebShell
+// @Summary   查询活跃的web终端会话
+// @Description  返回当前实例上所有用户的web终端会话，包括用户、集群、pod、容器、录像id和最后一次输入的时间
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Success   200   {object}  middleware.Response{data=[]types.TerminalSessionMeta,msg=string}  "活跃的会话列表"
+// @Router    /api/webshell/sessions [get]
+func (w *webShellController) ListSessions(ctx *gin.Context) {
+	middleware.ResponseSuccess(ctx, types.TerminalSessions.List())
+}
+
+// KillSession
+// @Tags      WebShell
+// @Summary   终止web终端会话
+// @Description  在被终止的终端中提示操作的管理员，并发送关闭帧断开连接，会话录像正常保存
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     id   path      string  true  "会话id"
+// @Success   200  {object}  middleware.Response{msg=string}  "终止成功"
+// @Router    /api/webshell/session/{id} [delete]
+func (w *webShellController) KillSession(ctx *gin.Context) {
+	claims := utils.GetUserInfo(ctx)
+	id := ctx.Param("id")
+	if err := types.TerminalSessions.Kill(id, claims.Username); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	v1.Log.Info(fmt.Sprintf("管理员 %s 终止web终端会话 %s", claims.Username, id))
+	middleware.ResponseSuccess(ctx, "终止成功")
+}
+
+// ShareSession
+// @Tags      WebShell
+// @Summary   共享web终端会话
+// @Description  只有会话所有者可以共享，会话id在web终端建立后以operation为session的消息推送；
+// @Description  观看者通过返回的地址只读观看会话的输出，观看者变化时向所有者推送operation为viewers的消息
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     id   path      string  true  "会话id"
+// @Success   200  {object}  middleware.Response{data=dto.WebShellShareOut,msg=string}  "共享令牌和观看地址"
+// @Router    /api/webshell/session/{id}/share [post]
+func (w *webShellController) ShareSession(ctx *gin.Context) {
+	claims := utils.GetUserInfo(ctx)
+	token, err := types.TerminalSessions.Share(ctx.Param("id"), claims.ID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.CreateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.CreateError, err))
+		return
+	}
+	v1.Log.Info(fmt.Sprintf("用户 %s 共享web终端会话 %s", claims.Username, ctx.Param("id")))
+	middleware.ResponseSuccess(ctx, &dto.WebShellShareOut{
+		Token:    token,
+		WatchURL: fmt.Sprintf("%s?share=%s", pkg.WebShellWatchURL, token),
+	})
+}
+
+// UnshareSession
+// @Tags      WebShell
+// @Summary   停止共享web终端会话
+// @Description  共享令牌失效，并断开所有观看者，控制权收回给会话所有者
+// @Security  ApiKeyAuth
+// @Produce   application/json
+// @Param     id   path      string  true  "会话id"
+// @Success   200  {object}  middleware.Response{msg=string}  "停止共享成功"
+// @Router    /api/webshell/session/{id}/share [delete]
+func (w *webShellController) UnshareSession(ctx *gin.Context) {
+	claims := utils.GetUserInfo(ctx)
+	if err := types.TerminalSessions.Unshare(ctx.Param("id"), claims.ID); err != nil {
+		v1.Log.ErrorWithCode(globalError.DeleteError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.DeleteError, err))
+		return
+	}
+	v1.Log.Info(fmt.Sprintf("用户 %s 停止共享web终端会话 %s", claims.Username, ctx.Param("id")))
+	middleware.ResponseSuccess(ctx, "停止共享成功")
+}
+
+// HandOverControl
+// @Tags      WebShell
+// @Summary   移交web终端控制权
+// @Description  会话所有者将控制权移交给观看者，之后只接受该观看者的输入，并按观看者角色的命令策略拦截；viewer_id为空时收回控制权，
+// @Description  拥有控制权的观看者离开时自动收回，控制权变化时向所有者和观看者推送operation为control的消息，
+// @Description  并在会话录像中写入标记、在操作记录中记录获得控制权的用户
+// @Security  ApiKeyAuth
+// @accept    application/json
+// @Produce   application/json
+// @Param     id    path      string                    true  "会话id"
+// @Param     data  body      dto.WebShellControlInput  true  "观看者id"
+// @Success   200   {object}  middleware.Response{msg=string}  "移交成功"
+// @Router    /api/webshell/session/{id}/control [post]
+func (w *webShellController) HandOverControl(ctx *gin.Context) {
+	params := &dto.WebShellControlInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	claims := utils.GetUserInfo(ctx)
+	meta, control, err := types.TerminalSessions.HandOver(ctx.Param("id"), claims.ID, params.ViewerID)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.UpdateError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.UpdateError, err))
+		return
+	}
+	v1.Log.Info(fmt.Sprintf("用户 %s 将web终端会话 %s 的控制权移交给观看者 %q(%s)", claims.Username, meta.ID, control.ViewerID, control.Username))
+	recordControlEvent(ctx, claims.ID, meta, control)
+	middleware.ResponseSuccess(ctx, "移交成功")
+}
+
+// recordControlEvent 将控制权的移交写入操作记录，body中记录获得控制权的用户，与录像中的标记对应
+func recordControlEvent(ctx *gin.Context, userID int, meta types.TerminalSessionMeta, control types.TerminalControl) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"session":      meta.ID,
+		"recording_id": meta.RecordingID,
+		"cluster":      meta.Cluster,
+		"namespace":    meta.Namespace,
+		"pod":          meta.Pod,
+		"container":    meta.Container,
+		"viewer_id":    control.ViewerID,
+		"user_id":      control.UserID,
+		"username":     control.Username,
+	})
+	record := model.SysOperationRecord{
+		Ip:     ctx.ClientIP(),
+		Method: ctx.Request.Method,
+		Path:   ctx.Request.URL.Path,
+		Agent:  ctx.Request.UserAgent(),
+		Body:   string(body),
+		Status: http.StatusOK,
+		UserID: userID,
+	}
+	if err := v1.CoreV1.System().Operation().CreateOperationRecord(ctx, &record); err != nil {
+		v1.Log.ErrorWithErr("保存web终端控制权移交记录失败", err)
+	}
+}
+
+// WatchSession
+// @Tags      WebShell
+// @Summary   观看共享的web终端会话
+// @Description  升级为websocket后只读镜像会话的stdout和resize消息，连接建立后推送operation为viewer的消息，data为观看者id；
+// @Description  除接口权限外还需要有会话所在命名空间的web终端权限，获得控制权前发送的stdin会被忽略并推送code为read_only的错误；
+// @Description  获得控制权后的输入按观看者角色的命令策略拦截，命中策略的命令以观看者身份写入操作记录
+// @Security  ApiKeyAuth
+// @Param     share  query  string  true  "共享令牌"
+// @Router    /api/webshell/watch [get]
+func (w *webShellController) WatchSession(ctx *gin.Context) {
+	params := &dto.WebShellWatchInput{}
+	if err := params.BindingValidParams(ctx); err != nil {
+		v1.Log.ErrorWithCode(globalError.ParamBindError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.ParamBindError, err))
+		return
+	}
+	meta, err := types.TerminalSessions.Lookup(params.Share)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	// 观看者同样需要有目标命名空间的web终端权限
+	if err := middleware.CasbinEnforce(ctx, pkg.WebShellNamespaceObj+meta.Namespace, ctx.Request.Method); err != nil {
+		v1.Log.ErrorWithCode(globalError.AuthErr, err)
+		middleware.ResponseError(ctx, err)
+		return
+	}
+	claims := utils.GetUserInfo(ctx)
+	// 观看者获得控制权后，按观看者自己的角色拦截命令
+	rules, err := v1.CoreV1.System().CommandPolicy().CommandRules(ctx, meta.Namespace, claims.AuthorityId)
+	if err != nil {
+		v1.Log.ErrorWithCode(globalError.GetError, err)
+		middleware.ResponseError(ctx, globalError.NewGlobalError(globalError.GetError, err))
+		return
+	}
+	guard := types.NewCommandGuard(rules, func(event types.CommandEvent) {
+		v1.Log.Info(fmt.Sprintf("用户 %s 在 %s 的web终端会话 %s 中执行的命令 %q 命中策略 %s: %s",
+			claims.Username, meta.Username, meta.ID, event.Command, event.Rule, event.Outcome))
+		if err := v1.CoreV1.System().CommandPolicy().RecordCommandEvent(ctx, claims.ID, &meta, event); err != nil {
+			v1.Log.ErrorWithErr("保存web终端命令审计记录失败", err)
+		}
+	})
+	v1.Log.Info(fmt.Sprintf("用户 %s 开始观看 %s 的web终端会话 %s 集群: %s pod: %s/%s", claims.Username, meta.Username, meta.ID, meta.Cluster, meta.Namespace, meta.Pod))
+	err = types.TerminalSessions.Watch(params.Share, types.TerminalViewer{UserID: claims.ID, Username: claims.Username}, guard, ctx.Writer, ctx.Request)
+	v1.Log.Info(fmt.Sprintf("用户 %s 结束观看web终端会话 %s", claims.Username, meta.ID))
+	if err != nil {
+		v1.Log.ErrorWithErr("观看web终端会话失败", err)
+		// 记录到操作历史
+		_ = ctx.Error(err)
+	}
+}
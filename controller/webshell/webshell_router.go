@@ -0,0 +1,29 @@
+package webshell
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type webShellController struct{}
+
+func NewWebShellRouter(ginEngine *gin.RouterGroup) {
+	shell := webShellController{}
+	shell.initRoutes(ginEngine)
+}
+
+func (w *webShellController) initRoutes(ginEngine *gin.RouterGroup) {
+	shellRoute := ginEngine.Group("/webshell")
+	shell := &webShellController{}
+	{
+		shellRoute.GET("/sessions", shell.ListSessions)
+		shellRoute.DELETE("/session/:id", shell.KillSession)
+		shellRoute.POST("/session/:id/share", shell.ShareSession)
+		shellRoute.DELETE("/session/:id/share", shell.UnshareSession)
+		shellRoute.POST("/session/:id/control", shell.HandOverControl)
+		shellRoute.GET("/watch", shell.WatchSession)
+		shellRoute.GET("/policy/list", shell.GetPolicyList)
+		shellRoute.POST("/policy/create", shell.CreatePolicy)
+		shellRoute.PUT("/policy/update", shell.UpdatePolicy)
+		shellRoute.DELETE("/policy/del", shell.DeletePolicy)
+	}
+}
@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+)
+
+type ClusterInterface interface {
+	Save(ctx context.Context, obj *model.Cluster) error
+	Find(ctx context.Context, search *model.Cluster) (*model.Cluster, error)
+	FindList(ctx context.Context, search *model.Cluster) ([]*model.Cluster, error)
+	PageList(ctx context.Context, params *dto.ClusterListInput) ([]*model.Cluster, int, error)
+	Delete(ctx context.Context, id int) error
+}
+
+var _ ClusterInterface = &cluster{}
+
+type cluster struct {
+	db *gorm.DB
+}
+
+func NewCluster(db *gorm.DB) ClusterInterface {
+	return &cluster{db: db}
+}
+
+func (c *cluster) Save(ctx context.Context, obj *model.Cluster) error {
+	return c.db.WithContext(ctx).Save(obj).Error
+}
+
+func (c *cluster) Find(ctx context.Context, search *model.Cluster) (*model.Cluster, error) {
+	out := &model.Cluster{}
+	return out, c.db.WithContext(ctx).Where(search).First(out).Error
+}
+
+func (c *cluster) FindList(ctx context.Context, search *model.Cluster) ([]*model.Cluster, error) {
+	var res []*model.Cluster
+	return res, c.db.WithContext(ctx).Where(search).Find(&res).Error
+}
+
+func (c *cluster) PageList(ctx context.Context, params *dto.ClusterListInput) ([]*model.Cluster, int, error) {
+	var total int64 = 0
+	var list []*model.Cluster
+	offset := (params.Page - 1) * params.Limit
+	query := c.db.WithContext(ctx).Table(model.GetClusterTableName())
+	if params.FilterName != "" {
+		query = query.Where("( name like ?)", "%"+params.FilterName+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Limit(params.Limit).Offset(offset).Order("id desc").Find(&list).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, 0, err
+	}
+	return list, int(total), nil
+}
+
+// Delete 硬删除，kubeconfig 属于敏感凭证，且集群名称唯一，不保留软删除记录
+func (c *cluster) Delete(ctx context.Context, id int) error {
+	return c.db.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&model.Cluster{}).Error
+}
@@ -5,8 +5,11 @@ import (
 
 	"github.com/noovertime7/kubemanage/dao/api"
 	"github.com/noovertime7/kubemanage/dao/authority"
+	"github.com/noovertime7/kubemanage/dao/cluster"
 	"github.com/noovertime7/kubemanage/dao/menu"
 	"github.com/noovertime7/kubemanage/dao/operation"
+	"github.com/noovertime7/kubemanage/dao/policy"
+	"github.com/noovertime7/kubemanage/dao/recording"
 	"github.com/noovertime7/kubemanage/dao/user"
 	"github.com/noovertime7/kubemanage/dao/workflow"
 )
@@ -22,6 +25,9 @@ type ShareDaoFactory interface {
 	AuthorityMenu() authority.AuthorityMenu
 	BaseMenu() menu.BaseMenu
 	Opera() operation.Operation
+	Cluster() cluster.ClusterInterface
+	Recording() recording.Recording
+	CommandPolicy() policy.CommandPolicy
 }
 
 func NewShareDaoFactory(db *gorm.DB) ShareDaoFactory {
@@ -68,3 +74,15 @@ func (s *shareDaoFactory) BaseMenu() menu.BaseMenu {
 func (s *shareDaoFactory) Opera() operation.Operation {
 	return operation.NewOperation(s.db)
 }
+
+func (s *shareDaoFactory) Cluster() cluster.ClusterInterface {
+	return cluster.NewCluster(s.db)
+}
+
+func (s *shareDaoFactory) Recording() recording.Recording {
+	return recording.NewRecording(s.db)
+}
+
+func (s *shareDaoFactory) CommandPolicy() policy.CommandPolicy {
+	return policy.NewCommandPolicy(s.db)
+}
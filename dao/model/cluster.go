@@ -0,0 +1,46 @@
+package model
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(ClusterOrder, &Cluster{})
+}
+
+// Cluster 集群信息，通过上传kubeconfig导入
+type Cluster struct {
+	ID          int    `gorm:"column:id;primary_key;AUTO_INCREMENT;not null" json:"id"`
+	Name        string `json:"name" gorm:"column:name;uniqueIndex;size:64;comment:集群名称"`
+	Description string `json:"description" gorm:"column:description;comment:集群描述"`
+	Version     string `json:"version" gorm:"column:version;comment:导入时的集群版本"`
+	KubeConfig  string `json:"-" gorm:"type:text;column:kube_config;comment:kubeconfig内容"`
+	CommonModel
+}
+
+func (c *Cluster) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(c)
+}
+
+func (c *Cluster) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return true, nil
+}
+
+func (c *Cluster) InitData(ctx context.Context, db *gorm.DB) error {
+	// 集群由用户导入，不需要初始化数据
+	return nil
+}
+
+func (c *Cluster) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(c)
+}
+
+func (c *Cluster) TableName() string {
+	return "t_cluster"
+}
+
+func GetClusterTableName() string {
+	temp := &Cluster{}
+	return temp.TableName()
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(TerminalCommandPolicyOrder, &TerminalCommandPolicy{})
+}
+
+// web终端命令策略的动作
+const (
+	// CommandPolicyBlock 拦截命令
+	CommandPolicyBlock = "block"
+	// CommandPolicyConfirm 再次按回车确认后才执行
+	CommandPolicyConfirm = "confirm"
+)
+
+// TerminalCommandPolicy web终端危险命令策略，命令行匹配Pattern时按Action拦截或要求确认；
+// Namespaces和AuthorityIds为逗号分隔的列表，为空时对所有命名空间、所有角色生效
+type TerminalCommandPolicy struct {
+	ID           int    `gorm:"column:id;primary_key;AUTO_INCREMENT;not null" json:"id"`
+	Name         string `json:"name" gorm:"column:name;size:64;comment:策略名称"`
+	Pattern      string `json:"pattern" gorm:"column:pattern;size:512;comment:匹配命令行的正则表达式"`
+	Action       string `json:"action" gorm:"column:action;size:16;comment:动作 block拦截 confirm确认"`
+	Namespaces   string `json:"namespaces" gorm:"column:namespaces;size:512;comment:生效的命名空间，逗号分隔，为空时对所有命名空间生效"`
+	AuthorityIds string `json:"authority_ids" gorm:"column:authority_ids;size:256;comment:生效的角色id，逗号分隔，为空时对所有角色生效"`
+	Enabled      bool   `json:"enabled" gorm:"column:enabled;comment:是否启用"`
+	Description  string `json:"description" gorm:"column:description;comment:描述"`
+	CommonModel
+}
+
+func (t *TerminalCommandPolicy) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(t)
+}
+
+func (t *TerminalCommandPolicy) InitData(ctx context.Context, db *gorm.DB) error {
+	ok, err := t.IsInitData(ctx, db)
+	if err != nil || ok {
+		return err
+	}
+	return db.WithContext(ctx).Create(TerminalCommandPolicyEntities).Error
+}
+
+func (t *TerminalCommandPolicy) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	var out *TerminalCommandPolicy
+	// 包含已删除的策略，管理员删除默认策略后不再重新初始化
+	if err := db.WithContext(ctx).Unscoped().Limit(1).Find(&out).Error; err != nil {
+		return false, nil
+	}
+	return out.ID != 0, nil
+}
+
+func (t *TerminalCommandPolicy) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(t)
+}
+
+func (t *TerminalCommandPolicy) TableName() string {
+	return "t_terminal_command_policy"
+}
@@ -17,6 +17,9 @@ const (
 	CasbinInitOrder
 	OperatorationOrder
 	WorkFlowOrder
+	ClusterOrder
+	TerminalRecordingOrder
+	TerminalCommandPolicyOrder
 )
 
 // SysUserEntities 用户初始化数据
@@ -141,14 +144,16 @@ func buildCasbinRule(apis []SysApi) []adapter.CasbinRule {
 		{Ptype: "p", V0: pkg.UserDefaultAuthStr, V1: "/api/user/login", V2: "POST"},
 		{Ptype: "p", V0: pkg.UserDefaultAuthStr, V1: "/api/user/loginout", V2: "GET"},
 		{Ptype: "p", V0: pkg.UserDefaultAuthStr, V1: "/api/user/getinfo", V2: "GET"},
+		{Ptype: "p", V0: pkg.UserDefaultAuthStr, V1: "/api/user/ws_ticket", V2: "POST"},
 		{Ptype: "p", V0: pkg.UserDefaultAuthStr, V1: "/api/user/:id/change_pwd", V2: "POST"},
 
 		{Ptype: "p", V0: pkg.UserSubDefaultAuthStr, V1: "/api/user/login", V2: "POST"},
 		{Ptype: "p", V0: pkg.UserSubDefaultAuthStr, V1: "/api/user/loginout", V2: "GET"},
 		{Ptype: "p", V0: pkg.UserSubDefaultAuthStr, V1: "/api/user/getinfo", V2: "GET"},
+		{Ptype: "p", V0: pkg.UserSubDefaultAuthStr, V1: "/api/user/ws_ticket", V2: "POST"},
 		{Ptype: "p", V0: pkg.UserSubDefaultAuthStr, V1: "/api/user/:id/change_pwd", V2: "POST"},
 	}
-	allRules := append(append(out, otherRule...))
+	allRules := append(out, otherRule...)
 	return allRules
 }
 
@@ -160,6 +165,7 @@ var SysApis = []SysApi{
 	{Path: "/api/user/login", Description: "用户登录", ApiGroup: "用户", Method: "POST"},
 	{Path: "/api/user/loginout", Description: "用户退出", ApiGroup: "用户", Method: "GET"},
 	{Path: "/api/user/getinfo", Description: "获取用户信息", ApiGroup: "用户", Method: "GET"},
+	{Path: "/api/user/ws_ticket", Description: "签发websocket一次性票据", ApiGroup: "用户", Method: "POST"},
 	{Path: "/api/user/:id/set_auth", Description: "设置用户权限", ApiGroup: "用户", Method: "PUT"},
 	{Path: "/api/user/:id/delete_user", Description: "删除用户", ApiGroup: "用户", Method: "DELETE"},
 	{Path: "/api/user/:id/change_pwd", Description: "修改密码", ApiGroup: "用户", Method: "POST"},
@@ -168,6 +174,19 @@ var SysApis = []SysApi{
 	{Path: "/api/operation/get_operations", Description: "查询操作记录列表", ApiGroup: "操作审计", Method: "GET"},
 	{Path: "/api/operation/:id/delete_operation", Description: "删除单条记录", ApiGroup: "操作审计", Method: "DELETE"},
 	{Path: "/api/operation/delete_operations", Description: "批量删除记录", ApiGroup: "操作审计", Method: "POST"},
+	{Path: "/api/recording/get_recordings", Description: "查询web终端录像列表", ApiGroup: "操作审计", Method: "GET"},
+	{Path: "/api/recording/:id/download", Description: "下载web终端录像", ApiGroup: "操作审计", Method: "GET"},
+	{Path: "/api/recording/:id/play", Description: "回放web终端录像", ApiGroup: "操作审计", Method: "GET"},
+	{Path: "/api/webshell/sessions", Description: "查询活跃的web终端会话", ApiGroup: "操作审计", Method: "GET"},
+	{Path: "/api/webshell/session/:id", Description: "终止web终端会话", ApiGroup: "操作审计", Method: "DELETE"},
+	{Path: "/api/webshell/session/:id/share", Description: "共享web终端会话", ApiGroup: "web终端共享", Method: "POST"},
+	{Path: "/api/webshell/session/:id/share", Description: "停止共享web终端会话", ApiGroup: "web终端共享", Method: "DELETE"},
+	{Path: "/api/webshell/session/:id/control", Description: "移交web终端控制权", ApiGroup: "web终端共享", Method: "POST"},
+	{Path: "/api/webshell/watch", Description: "观看共享的web终端会话", ApiGroup: "web终端共享", Method: "GET"},
+	{Path: "/api/webshell/policy/list", Description: "查询web终端命令策略", ApiGroup: "web终端命令策略", Method: "GET"},
+	{Path: "/api/webshell/policy/create", Description: "创建web终端命令策略", ApiGroup: "web终端命令策略", Method: "POST"},
+	{Path: "/api/webshell/policy/update", Description: "更新web终端命令策略", ApiGroup: "web终端命令策略", Method: "PUT"},
+	{Path: "/api/webshell/policy/del", Description: "删除web终端命令策略", ApiGroup: "web终端命令策略", Method: "DELETE"},
 	// Other
 	{Path: "/api/swagger/*any", Description: "swagger文档", ApiGroup: "Other", Method: "GET"},
 	// 菜单接口
@@ -179,6 +198,11 @@ var SysApis = []SysApi{
 	{Path: "/api/authority/getPolicyPathByAuthorityId", Description: "获取角色api权限", ApiGroup: "权限", Method: "GET"},
 	{Path: "/api/authority/updateCasbinByAuthority", Description: "更改角色api权限", ApiGroup: "用户", Method: "POST"},
 	{Path: "/api/authority/getAuthorityList", Description: "获取角色列表", ApiGroup: "权限", Method: "GET"},
+	// 集群管理接口
+	{Path: "/api/cluster/create", Description: "导入集群", ApiGroup: "集群", Method: "POST"},
+	{Path: "/api/cluster/del", Description: "删除集群", ApiGroup: "集群", Method: "DELETE"},
+	{Path: "/api/cluster/list", Description: "查询集群列表", ApiGroup: "集群", Method: "GET"},
+	{Path: "/api/cluster/detail", Description: "查询集群详情", ApiGroup: "集群", Method: "GET"},
 	// K8S相关接口
 	{Path: "/api/k8s/deployment/create", Description: "创建deployment", ApiGroup: "Kubernetes", Method: "POST"},
 	{Path: "/api/k8s/deployment/del", Description: "删除deployment", ApiGroup: "Kubernetes", Method: "DELETE"},
@@ -188,22 +212,59 @@ var SysApis = []SysApi{
 	{Path: "/api/k8s/deployment/restart", Description: "重启deployment", ApiGroup: "Kubernetes", Method: "PUT"},
 	{Path: "/api/k8s/deployment/scale", Description: "deployment扩缩容", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/deployment/numnp", Description: "查询deployment数量信息", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/deployment/history", Description: "查询deployment发布历史", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/deployment/rollback", Description: "回滚deployment", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/deployment/pause", Description: "暂停deployment发布", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/deployment/resume", Description: "恢复deployment发布", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/deployment/rollout/status", Description: "查询deployment发布进度", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/deployment/image", Description: "更新deployment容器镜像", ApiGroup: "Kubernetes", Method: "PUT"},
 	{Path: "/api/k8s/pod/list", Description: "查询pod列表", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/pod/detail", Description: "查询pod详情", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/pod/del", Description: "删除pod", ApiGroup: "Kubernetes", Method: "DELETE"},
 	{Path: "/api/k8s/pod/update", Description: "更新pod", ApiGroup: "Kubernetes", Method: "PUT"},
 	{Path: "/api/k8s/pod/container", Description: "获取Pod内容器名", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/pod/log", Description: "获取容器日志", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/pod/log/stream", Description: "实时推送容器日志", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/pod/log/aggregate", Description: "聚合推送多个pod的日志", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/pod/log/download", Description: "下载容器日志", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/pod/numnp", Description: "查询pod数量信息", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/pod/webshell", Description: "web终端", ApiGroup: "Kubernetes", Method: "GET"},
+	// 按命名空间授权web终端时，将*替换为命名空间名称
+	{Path: "/api/k8s/pod/webshell/namespace/*", Description: "web终端(所有命名空间)", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/pod/debug", Description: "注入临时调试容器", ApiGroup: "Kubernetes", Method: "POST"},
+	// 容器文件传输与web终端分开授权
+	{Path: "/api/k8s/pod/file/list", Description: "浏览容器内的目录", ApiGroup: "容器文件", Method: "GET"},
+	{Path: "/api/k8s/pod/file/download", Description: "下载容器内的文件", ApiGroup: "容器文件", Method: "GET"},
+	{Path: "/api/k8s/pod/file/upload", Description: "上传文件到容器", ApiGroup: "容器文件", Method: "POST"},
 	{Path: "/api/k8s/daemonset/del", Description: "删除daemonset", ApiGroup: "Kubernetes", Method: "DELETE"},
 	{Path: "/api/k8s/daemonset/update", Description: "更新daemonset", ApiGroup: "Kubernetes", Method: "PUT"},
 	{Path: "/api/k8s/daemonset/list", Description: "查询daemonset列表", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/daemonset/detail", Description: "查询daemonset详情", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/daemonset/image", Description: "更新daemonset容器镜像", ApiGroup: "Kubernetes", Method: "PUT"},
 	{Path: "/api/k8s/statefulset/del", Description: "删除statefulset", ApiGroup: "Kubernetes", Method: "DELETE"},
 	{Path: "/api/k8s/statefulset/update", Description: "更新statefulset", ApiGroup: "Kubernetes", Method: "PUT"},
 	{Path: "/api/k8s/statefulset/list", Description: "查询statefulset列表", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/statefulset/detail", Description: "查询statefulset详情", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/statefulset/image", Description: "更新statefulset容器镜像", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/job/create", Description: "创建job", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/job/del", Description: "删除job", ApiGroup: "Kubernetes", Method: "DELETE"},
+	{Path: "/api/k8s/job/update", Description: "更新job", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/job/list", Description: "查询job列表", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/job/detail", Description: "查询job详情", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/cronjob/create", Description: "创建cronjob", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/cronjob/del", Description: "删除cronjob", ApiGroup: "Kubernetes", Method: "DELETE"},
+	{Path: "/api/k8s/cronjob/update", Description: "更新cronjob", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/cronjob/list", Description: "查询cronjob列表", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/cronjob/detail", Description: "查询cronjob详情", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/cronjob/suspend", Description: "暂停cronjob调度", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/cronjob/resume", Description: "恢复cronjob调度", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/cronjob/trigger", Description: "立即执行cronjob", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/cronjob/history", Description: "查询cronjob任务历史", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/hpa/create", Description: "创建hpa", ApiGroup: "Kubernetes", Method: "POST"},
+	{Path: "/api/k8s/hpa/del", Description: "删除hpa", ApiGroup: "Kubernetes", Method: "DELETE"},
+	{Path: "/api/k8s/hpa/update", Description: "更新hpa", ApiGroup: "Kubernetes", Method: "PUT"},
+	{Path: "/api/k8s/hpa/list", Description: "查询hpa列表", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/hpa/detail", Description: "查询hpa详情", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/node/list", Description: "查询node列表", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/node/detail", Description: "查询node详情", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/namespace/create", Description: "创建namespace", ApiGroup: "Kubernetes", Method: "PUT"},
@@ -241,4 +302,19 @@ var SysApis = []SysApi{
 	{Path: "/api/k8s/workflow/del", Description: "删除workflow", ApiGroup: "Kubernetes", Method: "DELETE"},
 	{Path: "/api/k8s/workflow/list", Description: "查询workflow列表", ApiGroup: "Kubernetes", Method: "GET"},
 	{Path: "/api/k8s/workflow/id", Description: "查看workflow", ApiGroup: "Kubernetes", Method: "GET"},
+	{Path: "/api/k8s/apply", Description: "提交yaml/json清单", ApiGroup: "Kubernetes", Method: "POST"},
+	// 端口转发与反向代理，代理接口按请求方法分别授权
+	{Path: "/api/k8s/pod/portforward", Description: "通过websocket转发pod端口", ApiGroup: "端口转发", Method: "GET"},
+	{Path: "/api/k8s/proxy_ticket", Description: "签发反向代理票据", ApiGroup: "端口转发", Method: "POST"},
+	{Path: "/api/k8s/proxy/*", Description: "反向代理到pod或service", ApiGroup: "端口转发", Method: "GET"},
+}
+
+// TerminalCommandPolicyEntities web终端危险命令的默认策略
+var TerminalCommandPolicyEntities = []TerminalCommandPolicy{
+	{Name: "删除根目录", Pattern: `\brm\s+(-\S+\s+)*-[a-zA-Z]*[rR][a-zA-Z]*\s+(-\S+\s+)*/\*?(\s|;|&|\||$)`, Action: CommandPolicyBlock, Enabled: true, Description: "rm -rf / 等递归删除根目录的命令"},
+	{Name: "关机重启", Pattern: `(^|[;&|]\s*|\bsudo\s+)(shutdown|reboot|halt|poweroff|init\s+[06])\b`, Action: CommandPolicyBlock, Enabled: true, Description: "shutdown、reboot、halt、poweroff、init 0/6"},
+	{Name: "格式化文件系统", Pattern: `(^|[;&|]\s*|\bsudo\s+)mkfs(\.\w+)?\b`, Action: CommandPolicyBlock, Enabled: true, Description: "mkfs 格式化磁盘"},
+	{Name: "fork炸弹", Pattern: `:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`, Action: CommandPolicyBlock, Enabled: true, Description: ":(){ :|:& };:"},
+	{Name: "结束1号进程", Pattern: `\bkill\s+(-\S+\s+)*(-\s*)?1(\s|;|&|\||$)`, Action: CommandPolicyConfirm, Enabled: true, Description: "kill 1 会结束容器的主进程导致容器重启"},
+	{Name: "写入块设备", Pattern: `\bdd\s+.*\bof=/dev/`, Action: CommandPolicyConfirm, Enabled: true, Description: "dd 直接写入设备文件"},
 }
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	RegisterInitializer(TerminalRecordingOrder, &TerminalRecording{})
+}
+
+// TerminalRecording web终端会话录像的索引，录像内容保存在存储后端中
+type TerminalRecording struct {
+	ID        int    `gorm:"column:id;primary_key;AUTO_INCREMENT;not null" json:"id"`
+	UserID    int    `json:"user_id" gorm:"column:user_id;index;comment:用户id"`
+	Username  string `json:"username" gorm:"column:username;size:64;index;comment:用户名"`
+	Cluster   string `json:"cluster" gorm:"column:cluster;size:64;comment:集群名称"`
+	Namespace string `json:"namespace" gorm:"column:namespace;size:64;comment:命名空间"`
+	Pod       string `json:"pod" gorm:"column:pod;comment:pod名称"`
+	Container string `json:"container" gorm:"column:container;comment:容器名"`
+	Command   string `json:"command" gorm:"column:command;comment:自定义命令，为空时为shell"`
+	Storage   string `json:"storage" gorm:"column:storage;size:32;comment:存储后端"`
+	// StorageKey 录像在存储后端中的路径，不对外展示
+	StorageKey string     `json:"-" gorm:"column:storage_key;comment:录像路径"`
+	Size       int64      `json:"size" gorm:"column:size;comment:录像大小"`
+	Duration   float64    `json:"duration" gorm:"column:duration;comment:会话时长(秒)"`
+	StartedAt  time.Time  `json:"started_at" gorm:"column:started_at;index;comment:开始时间"`
+	FinishedAt *time.Time `json:"finished_at" gorm:"column:finished_at;comment:结束时间，为空时会话未结束或异常中断"`
+	CommonModel
+}
+
+func (t *TerminalRecording) MigrateTable(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).AutoMigrate(t)
+}
+
+func (t *TerminalRecording) InitData(ctx context.Context, db *gorm.DB) error {
+	// 审计表，不需要初始化数据
+	return nil
+}
+
+func (t *TerminalRecording) IsInitData(ctx context.Context, db *gorm.DB) (bool, error) {
+	return true, nil
+}
+
+func (t *TerminalRecording) TableCreated(ctx context.Context, db *gorm.DB) bool {
+	return db.WithContext(ctx).Migrator().HasTable(t)
+}
+
+func (t *TerminalRecording) TableName() string {
+	return "t_terminal_recording"
+}
@@ -11,6 +11,7 @@ func init() {
 
 type Workflow struct {
 	ID          int    `gorm:"column:id;primary_key;AUTO_INCREMENT;not null" json:"id"`
+	Cluster     string `json:"cluster" gorm:"column:cluster"`
 	Name        string `json:"name" gorm:"column:name"`
 	NameSpace   string `json:"namespace" gorm:"column:namespace"`
 	Replicas    int32  `json:"replicas" gorm:"column:replicas"`
@@ -18,6 +19,7 @@ type Workflow struct {
 	Service     string `json:"service" gorm:"column:service"`
 	Ingress     string `json:"ingress" gorm:"column:ingress"`
 	ServiceType string `json:"service_type" gorm:"column:service_type"`
+	HPA         string `json:"hpa" gorm:"column:hpa"`
 	CommonModel
 }
 
@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+)
+
+type CommandPolicy interface {
+	Save(ctx context.Context, obj *model.TerminalCommandPolicy) error
+	Find(ctx context.Context, search *model.TerminalCommandPolicy) (*model.TerminalCommandPolicy, error)
+	// FindEnabled 查询所有启用的策略
+	FindEnabled(ctx context.Context) ([]*model.TerminalCommandPolicy, error)
+	PageList(ctx context.Context, params *dto.CommandPolicyListInput) ([]*model.TerminalCommandPolicy, int64, error)
+	Delete(ctx context.Context, id int) error
+}
+
+var _ CommandPolicy = &commandPolicy{}
+
+type commandPolicy struct {
+	db *gorm.DB
+}
+
+func NewCommandPolicy(db *gorm.DB) CommandPolicy {
+	return &commandPolicy{db: db}
+}
+
+func (c *commandPolicy) Save(ctx context.Context, obj *model.TerminalCommandPolicy) error {
+	return c.db.WithContext(ctx).Save(obj).Error
+}
+
+func (c *commandPolicy) Find(ctx context.Context, search *model.TerminalCommandPolicy) (*model.TerminalCommandPolicy, error) {
+	out := &model.TerminalCommandPolicy{}
+	return out, c.db.WithContext(ctx).Where(search).First(out).Error
+}
+
+func (c *commandPolicy) FindEnabled(ctx context.Context) ([]*model.TerminalCommandPolicy, error) {
+	var list []*model.TerminalCommandPolicy
+	return list, c.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&list).Error
+}
+
+func (c *commandPolicy) PageList(ctx context.Context, params *dto.CommandPolicyListInput) ([]*model.TerminalCommandPolicy, int64, error) {
+	var total int64 = 0
+	limit := params.PageSize
+	offset := params.PageSize * (params.Page - 1)
+	query := c.db.WithContext(ctx).Model(&model.TerminalCommandPolicy{})
+	var list []*model.TerminalCommandPolicy
+	if params.Keyword != "" {
+		query = query.Where("( name like ? or pattern like ? )", "%"+params.Keyword+"%", "%"+params.Keyword+"%")
+	}
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (c *commandPolicy) Delete(ctx context.Context, id int) error {
+	return c.db.WithContext(ctx).Where("id = ?", id).Delete(&model.TerminalCommandPolicy{}).Error
+}
@@ -0,0 +1,64 @@
+package recording
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+)
+
+type Recording interface {
+	Find(ctx context.Context, in *model.TerminalRecording) (*model.TerminalRecording, error)
+	PageList(ctx context.Context, params *dto.RecordingListInput) ([]*model.TerminalRecording, int64, error)
+	Save(ctx context.Context, in *model.TerminalRecording) error
+}
+
+var _ Recording = &recording{}
+
+type recording struct {
+	db *gorm.DB
+}
+
+func NewRecording(db *gorm.DB) *recording {
+	return &recording{db: db}
+}
+
+func (r *recording) Find(ctx context.Context, in *model.TerminalRecording) (*model.TerminalRecording, error) {
+	out := &model.TerminalRecording{}
+	return out, r.db.WithContext(ctx).Where(in).First(out).Error
+}
+
+func (r *recording) Save(ctx context.Context, in *model.TerminalRecording) error {
+	return r.db.WithContext(ctx).Save(in).Error
+}
+
+func (r *recording) PageList(ctx context.Context, params *dto.RecordingListInput) ([]*model.TerminalRecording, int64, error) {
+	var total int64 = 0
+	limit := params.PageSize
+	offset := params.PageSize * (params.Page - 1)
+	query := r.db.WithContext(ctx).Model(&model.TerminalRecording{})
+	var list []*model.TerminalRecording
+	if params.Username != "" {
+		query = query.Where("username = ?", params.Username)
+	}
+	if params.Cluster != "" {
+		query = query.Where("cluster = ?", params.Cluster)
+	}
+	if params.Namespace != "" {
+		query = query.Where("namespace = ?", params.Namespace)
+	}
+	if params.Pod != "" {
+		query = query.Where("pod like ?", "%"+params.Pod+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
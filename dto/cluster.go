@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// ClusterCreateInput 导入集群接口入参，kubeconfig 以文件形式上传
+type ClusterCreateInput struct {
+	Name        string `json:"name" form:"name" comment:"集群名称" validate:"required"`
+	Description string `json:"description" form:"description" comment:"集群描述"`
+}
+
+type ClusterListInput struct {
+	FilterName string `json:"filter_name" form:"filter_name" validate:"" comment:"过滤名"`
+	Limit      int    `json:"limit" form:"limit" validate:"" comment:"分页限制"`
+	Page       int    `json:"page" form:"page" validate:"" comment:"页码"`
+}
+
+type ClusterIDInput struct {
+	ID int `json:"id" form:"id" comment:"集群ID" validate:"required"`
+}
+
+// BindingValidParams 绑定并校验参数
+func (params *ClusterCreateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// BindingValidParams 绑定并校验参数
+func (params *ClusterListInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// BindingValidParams 绑定并校验参数
+func (params *ClusterIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
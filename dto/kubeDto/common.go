@@ -0,0 +1,30 @@
+package kubeDto
+
+// ViewTable 列表接口的精简视图，只返回类似kubectl get的摘要行，完整对象通过详情接口获取
+const ViewTable = "table"
+
+// DataSelectInput 资源列表接口通用的过滤、排序、分页参数
+type DataSelectInput struct {
+	// FilterName 按名称模糊过滤
+	FilterName string `json:"filter_name" form:"filter_name" validate:"" comment:"过滤名"`
+	// LabelSelector 标签选择器，语法与kubectl一致，如 app=web,tier!=db
+	LabelSelector string `json:"label_selector" form:"label_selector" validate:"" comment:"标签选择器"`
+	// FieldSelector 字段选择器，语法与kubectl一致，如 spec.nodeName=node1,status.phase!=Running
+	FieldSelector string `json:"field_selector" form:"field_selector" validate:"" comment:"字段选择器"`
+	// Status 按资源的派生状态过滤，多个状态用逗号分隔，满足其一即可，如 Running,NotReady
+	Status string `json:"status" form:"status" validate:"" comment:"状态"`
+	// SortBy 排序字段，格式为 字段:方向，多个字段用逗号分隔，如 restarts:desc,name:asc，缺省按创建时间倒序
+	SortBy string `json:"sort_by" form:"sort_by" validate:"" comment:"排序"`
+	// View 返回视图，为空时返回完整对象，为table时返回摘要行
+	View  string `json:"view" form:"view" validate:"omitempty,oneof=table" comment:"视图"`
+	Limit int    `json:"limit" form:"limit" validate:"" comment:"分页限制"`
+	Page  int    `json:"page" form:"page" validate:"" comment:"页码"`
+}
+
+// DryRunInput 更新和apply接口通用的预览参数
+type DryRunInput struct {
+	// DryRun 为true时以DryRun: All提交，不会真正修改集群，返回与线上对象的差异
+	DryRun bool `json:"dry_run" form:"dry_run" validate:"" comment:"预览"`
+	// UnifiedDiff 预览时是否同时返回unified格式的yaml差异
+	UnifiedDiff bool `json:"unified_diff" form:"unified_diff" validate:"" comment:"统一差异格式"`
+}
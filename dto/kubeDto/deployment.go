@@ -38,6 +38,7 @@ type DeployCreateInput struct {
 type UpdateDeployInput struct {
 	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
 	Content   string `json:"content" form:"content" validate:"required" comment:"更新内容"`
+	DryRunInput
 }
 
 type DeployScaleInput struct {
@@ -46,11 +47,25 @@ type DeployScaleInput struct {
 	ScaleNum  int    `json:"scale_num" form:"scale_num" comment:"期望副本数" validate:"required"`
 }
 
+// DeployRollbackInput 回滚deployment接口的入参结构
+type DeployRollbackInput struct {
+	Name      string `json:"deployment_name" form:"deployment_name" comment:"无状态控制器名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// Revision 目标版本号，为0时回滚到上一个版本
+	Revision int64 `json:"revision" form:"revision" comment:"版本号" validate:"min=0"`
+}
+
+// DeployRolloutStatusInput 查询deployment发布进度接口的入参结构
+type DeployRolloutStatusInput struct {
+	Name      string `json:"deployment_name" form:"deployment_name" comment:"无状态控制器名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// Timeout websocket推送的最长时间，单位秒，为0时一直推送到发布完成或失败
+	Timeout int `json:"timeout" form:"timeout" comment:"超时时间" validate:"min=0"`
+}
+
 type DeployListInput struct {
-	FilterName string `json:"filter_name" form:"filter_name" validate:"" comment:"过滤名"`
-	NameSpace  string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
-	Limit      int    `json:"limit" form:"limit" validate:"" comment:"分页限制"`
-	Page       int    `json:"page" form:"page" validate:"" comment:"页码"`
+	NameSpace string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
+	DataSelectInput
 }
 
 func (params *DeployListInput) BindingValidParams(c *gin.Context) error {
@@ -72,3 +87,11 @@ func (params *DeployScaleInput) BindingValidParams(c *gin.Context) error {
 func (params *DeploymentNameNS) BindingValidParams(c *gin.Context) error {
 	return pkg.DefaultGetValidParams(c, params)
 }
+
+func (params *DeployRollbackInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *DeployRolloutStatusInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
@@ -0,0 +1,56 @@
+package kubeDto
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type HPANameNS struct {
+	Name      string `json:"name" form:"name" comment:"HPA名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+}
+
+// HPACreateInput 创建hpa接口的入参结构，cpu与内存的目标使用率至少填写一项
+type HPACreateInput struct {
+	Name      string `json:"name" form:"name" comment:"HPA名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// Deployment 扩缩容的目标deployment
+	Deployment string `json:"deployment" validate:"required" comment:"目标deployment"`
+	// MinReplicas 最小副本数，为0时为1
+	MinReplicas int32 `json:"min_replicas" validate:"min=0" comment:"最小副本数"`
+	// MaxReplicas 最大副本数
+	MaxReplicas int32 `json:"max_replicas" validate:"required,min=1" comment:"最大副本数"`
+	// CpuUtilization cpu目标平均使用率(百分比)，为0时不按cpu扩缩容
+	CpuUtilization int32 `json:"cpu_utilization" validate:"min=0" comment:"cpu目标使用率"`
+	// MemoryUtilization 内存目标平均使用率(百分比)，为0时不按内存扩缩容
+	MemoryUtilization int32 `json:"memory_utilization" validate:"min=0" comment:"内存目标使用率"`
+	// Labels 标签
+	Labels map[string]string `json:"label" validate:"" comment:"标签"`
+}
+
+type HPAUpdateInput struct {
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Content   string `json:"content" validate:"required" comment:"更新内容"`
+	DryRunInput
+}
+
+type HPAListInput struct {
+	NameSpace string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
+	DataSelectInput
+}
+
+func (params *HPANameNS) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *HPACreateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *HPAUpdateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *HPAListInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
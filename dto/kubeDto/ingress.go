@@ -28,13 +28,12 @@ type IngressNameNS struct {
 type IngressUpdateInput struct {
 	Content   string `json:"content" form:"content" validate:"required" comment:"更新内容"`
 	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	DryRunInput
 }
 
 type IngressListInput struct {
-	FilterName string `json:"filter_name" form:"filter_name" validate:"" comment:"过滤名"`
-	NameSpace  string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
-	Limit      int    `json:"limit" form:"limit" validate:"" comment:"分页限制"`
-	Page       int    `json:"page" form:"page" validate:"" comment:"页码"`
+	NameSpace string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
+	DataSelectInput
 }
 
 func (params *IngressCreteInput) BindingValidParams(c *gin.Context) error {
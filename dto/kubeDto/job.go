@@ -0,0 +1,115 @@
+package kubeDto
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type JobNameNS struct {
+	Name      string `json:"name" form:"name" comment:"任务名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+}
+
+// JobCreateInput 创建job接口的入参结构，cronjob的任务模板复用该结构
+type JobCreateInput struct {
+	Name      string `json:"name" form:"name" comment:"任务名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// Image 镜像
+	Image string `json:"image" validate:"required" comment:"镜像名"`
+	// Command 容器的启动命令，为空时使用镜像的ENTRYPOINT
+	Command []string `json:"command" validate:"" comment:"启动命令"`
+	// Args 启动命令的参数
+	Args []string `json:"args" validate:"" comment:"启动参数"`
+	// Labels 标签
+	Labels map[string]string `json:"label" validate:"" comment:"标签"`
+	// Cpu Cpu限制，为空时不限制
+	Cpu string `json:"cpu" validate:"" comment:"Cpu限制"`
+	// Memory 内存限制，为空时不限制
+	Memory string `json:"memory" validate:"" comment:"内存限制"`
+	// RestartPolicy 容器失败后的重启策略，默认为Never
+	RestartPolicy string `json:"restart_policy" validate:"omitempty,oneof=Never OnFailure" comment:"重启策略"`
+	// Completions 需要成功完成的pod数，为0时为1
+	Completions int32 `json:"completions" validate:"min=0" comment:"完成数"`
+	// Parallelism 同时运行的pod数，为0时为1
+	Parallelism int32 `json:"parallelism" validate:"min=0" comment:"并行数"`
+	// BackoffLimit 失败重试次数，为空时使用kubernetes的默认值6
+	BackoffLimit *int32 `json:"backoff_limit" validate:"omitempty,min=0" comment:"重试次数"`
+	// ActiveDeadlineSeconds 任务的最长运行时间，为0时不限制
+	ActiveDeadlineSeconds int64 `json:"active_deadline_seconds" validate:"min=0" comment:"最长运行时间"`
+	// TTLSecondsAfterFinished 任务结束后多久自动删除，为空时不自动删除
+	TTLSecondsAfterFinished *int32 `json:"ttl_seconds_after_finished" validate:"omitempty,min=0" comment:"结束后保留时间"`
+}
+
+type JobUpdateInput struct {
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Content   string `json:"content" validate:"required" comment:"更新内容"`
+	DryRunInput
+}
+
+type JobListInput struct {
+	NameSpace string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
+	DataSelectInput
+}
+
+type CronJobNameNS struct {
+	Name      string `json:"name" form:"name" comment:"定时任务名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+}
+
+// CronJobCreateInput 创建cronjob接口的入参结构，Name为定时任务名称，其余字段为任务模板
+type CronJobCreateInput struct {
+	JobCreateInput
+	// Schedule cron格式的调度时间，如 */5 * * * *
+	Schedule string `json:"schedule" validate:"required" comment:"调度时间"`
+	// ConcurrencyPolicy 上一次任务未结束时的处理方式，默认为Allow
+	ConcurrencyPolicy string `json:"concurrency_policy" validate:"omitempty,oneof=Allow Forbid Replace" comment:"并发策略"`
+	// Suspend 创建后是否暂停调度
+	Suspend bool `json:"suspend" validate:"" comment:"暂停调度"`
+	// SuccessfulJobsHistoryLimit 保留的成功任务数，为空时使用kubernetes的默认值3
+	SuccessfulJobsHistoryLimit *int32 `json:"successful_jobs_history_limit" validate:"omitempty,min=0" comment:"保留的成功任务数"`
+	// FailedJobsHistoryLimit 保留的失败任务数，为空时使用kubernetes的默认值1
+	FailedJobsHistoryLimit *int32 `json:"failed_jobs_history_limit" validate:"omitempty,min=0" comment:"保留的失败任务数"`
+}
+
+type CronJobUpdateInput struct {
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Content   string `json:"content" validate:"required" comment:"更新内容"`
+	DryRunInput
+}
+
+type CronJobListInput struct {
+	NameSpace string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
+	DataSelectInput
+}
+
+func (params *JobNameNS) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *JobCreateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *JobUpdateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *JobListInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *CronJobNameNS) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *CronJobCreateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *CronJobUpdateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *CronJobListInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
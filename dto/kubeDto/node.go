@@ -10,9 +10,7 @@ type NodeNameInput struct {
 }
 
 type NodeListInput struct {
-	FilterName string `json:"filter_name" form:"filter_name" validate:"" comment:"过滤名"`
-	Limit      int    `json:"limit" form:"limit" validate:"" comment:"分页限制"`
-	Page       int    `json:"page" form:"page" validate:"" comment:"页码"`
+	DataSelectInput
 }
 
 func (params *NodeNameInput) BindingValidParams(c *gin.Context) error {
@@ -7,17 +7,17 @@ import (
 )
 
 type PodListInput struct {
-	FilterName string `json:"filter_name" form:"filter_name" validate:"" comment:"过滤名"`
-	NameSpace  string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
-	Limit      int    `json:"limit" form:"limit" validate:"" comment:"分页限制"`
-	Page       int    `json:"page" form:"page" validate:"" comment:"页码"`
+	NameSpace string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
+	DataSelectInput
 }
 
 // WebShellOptions ws API 参数定义
 type WebShellOptions struct {
-	Namespace string `form:"namespace"`
-	Pod       string `form:"pod_name"`
+	Namespace string `form:"namespace" validate:"required"`
+	Pod       string `form:"pod_name" validate:"required"`
 	Container string `form:"container_name"`
+	// Command 自定义执行的命令，按空白分隔参数，命令及全部参数需要与配置的允许列表中的某一项完全一致，为空时自动探测shell
+	Command string `form:"command"`
 }
 
 func (params *WebShellOptions) BindingValidParams(c *gin.Context) error {
@@ -37,6 +37,7 @@ type PodUpdateInput struct {
 	PodName   string `json:"pod_name" form:"pod_name" comment:"POD名称" validate:"required"`
 	NameSpace string `json:"name_space" form:"namespace" comment:"命名空间" validate:"required"`
 	Content   string `json:"content" form:"content" comment:"内容" validate:"required"`
+	DryRunInput
 }
 
 type PodGetLogInput struct {
@@ -45,6 +46,34 @@ type PodGetLogInput struct {
 	ContainerName string `json:"container_name" form:"container_name" comment:"容器名称" validate:"required"`
 }
 
+// PodLogOptionsInput 日志接口通用的查询参数
+type PodLogOptionsInput struct {
+	// Follow 是否持续跟踪新产生的日志
+	Follow bool `json:"follow" form:"follow" comment:"跟踪日志" validate:""`
+	// TailLines 返回最后的行数，为0时使用配置文件中的podLogTailLine，为-1时返回全部日志
+	TailLines int64 `json:"tail_lines" form:"tail_lines" comment:"日志行数" validate:"min=-1"`
+	// SinceSeconds 只返回最近多少秒内的日志，与SinceTime二选一
+	SinceSeconds int64 `json:"since_seconds" form:"since_seconds" comment:"最近秒数" validate:"min=0"`
+	// SinceTime 只返回该时间之后的日志，RFC3339格式
+	SinceTime string `json:"since_time" form:"since_time" comment:"起始时间" validate:""`
+	// Timestamps 每行日志前是否附带时间戳
+	Timestamps bool `json:"timestamps" form:"timestamps" comment:"时间戳" validate:""`
+	// Previous 是否查看上一次重启前的容器日志
+	Previous bool `json:"previous" form:"previous" comment:"上一个容器" validate:""`
+}
+
+// PodLogStreamInput websocket日志流接口的入参结构
+type PodLogStreamInput struct {
+	PodName       string `json:"pod_name" form:"pod_name" comment:"POD名称" validate:"required"`
+	NameSpace     string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	ContainerName string `json:"container_name" form:"container_name" comment:"容器名称" validate:"required"`
+	PodLogOptionsInput
+}
+
+func (params *PodLogStreamInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
 func (params *PodNameNsInput) BindingValidParams(c *gin.Context) error {
 	return pkg.DefaultGetValidParams(c, params)
 }
@@ -56,3 +85,78 @@ func (params *PodGetLogInput) BindingValidParams(c *gin.Context) error {
 func (params *PodUpdateInput) BindingValidParams(c *gin.Context) error {
 	return pkg.DefaultGetValidParams(c, params)
 }
+
+// PodLogDownloadInput 下载日志接口的入参结构
+type PodLogDownloadInput struct {
+	PodName       string `json:"pod_name" form:"pod_name" comment:"POD名称" validate:"required"`
+	NameSpace     string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	ContainerName string `json:"container_name" form:"container_name" comment:"容器名称" validate:"required"`
+	// TailLines 返回最后的行数，为0时返回全部日志
+	TailLines int64 `json:"tail_lines" form:"tail_lines" comment:"日志行数" validate:"min=0"`
+	// SinceSeconds 只返回最近多少秒内的日志，与SinceTime二选一
+	SinceSeconds int64 `json:"since_seconds" form:"since_seconds" comment:"最近秒数" validate:"min=0"`
+	// SinceTime 只返回该时间之后的日志，RFC3339格式
+	SinceTime string `json:"since_time" form:"since_time" comment:"起始时间" validate:""`
+	// UntilTime 只返回该时间之前的日志，RFC3339格式
+	UntilTime string `json:"until_time" form:"until_time" comment:"结束时间" validate:""`
+	// Include 只保留匹配该正则的行
+	Include string `json:"include" form:"include" comment:"包含" validate:""`
+	// Exclude 去掉匹配该正则的行
+	Exclude string `json:"exclude" form:"exclude" comment:"排除" validate:""`
+	// Timestamps 每行日志前是否附带时间戳
+	Timestamps bool `json:"timestamps" form:"timestamps" comment:"时间戳" validate:""`
+	// Previous 是否下载上一次重启前的容器日志
+	Previous bool `json:"previous" form:"previous" comment:"上一个容器" validate:""`
+	// Gzip 是否以gzip压缩下载
+	Gzip bool `json:"gzip" form:"gzip" comment:"gzip压缩" validate:""`
+}
+
+func (params *PodLogDownloadInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// PodFileInput 容器文件浏览、上传、下载接口的入参结构
+type PodFileInput struct {
+	PodName   string `json:"pod_name" form:"pod_name" comment:"POD名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// ContainerName 容器名称，为空时使用pod的默认容器
+	ContainerName string `json:"container_name" form:"container_name" comment:"容器名称" validate:""`
+	// Path 容器内的绝对路径，浏览和上传时为目录，下载时为文件或目录
+	Path string `json:"path" form:"path" comment:"路径" validate:"required,startswith=/"`
+}
+
+func (params *PodFileInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// PodDebugInput 向pod中注入临时调试容器接口的入参结构
+type PodDebugInput struct {
+	PodName   string `json:"pod_name" form:"pod_name" comment:"POD名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// Image 调试容器的镜像，为空时使用配置文件中的debugImage
+	Image string `json:"image" form:"image" comment:"镜像" validate:""`
+	// TargetContainer 共享进程命名空间的目标容器，为空时不共享
+	TargetContainer string `json:"target_container" form:"target_container" comment:"目标容器" validate:""`
+}
+
+func (params *PodDebugInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// PodLogAggregateInput 聚合日志接口的入参结构，通过标签选择器或工作负载选择pod
+type PodLogAggregateInput struct {
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// LabelSelector 标签选择器，与工作负载二选一
+	LabelSelector string `json:"label_selector" form:"label_selector" comment:"标签选择器" validate:"required_without=WorkloadName"`
+	// WorkloadKind 工作负载类型
+	WorkloadKind string `json:"workload_kind" form:"workload_kind" comment:"工作负载类型" validate:"required_with=WorkloadName,omitempty,oneof=deployment statefulset daemonset"`
+	// WorkloadName 工作负载名称，使用其spec.selector选择pod
+	WorkloadName string `json:"workload_name" form:"workload_name" comment:"工作负载名称" validate:""`
+	// Container 容器名称的正则表达式，为空时跟踪所有容器
+	Container string `json:"container" form:"container" comment:"容器" validate:""`
+	PodLogOptionsInput
+}
+
+func (params *PodLogAggregateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
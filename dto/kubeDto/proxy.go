@@ -0,0 +1,56 @@
+package kubeDto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// PodPortForwardInput 通过websocket转发pod端口接口的入参结构
+type PodPortForwardInput struct {
+	PodName   string `json:"pod_name" form:"pod_name" comment:"POD名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	Port      int32  `json:"port" form:"port" comment:"端口" validate:"required,min=1,max=65535"`
+}
+
+func (params *PodPortForwardInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// ProxyTicketInput 签发反向代理票据接口的入参结构，集群由cluster查询参数指定
+type ProxyTicketInput struct {
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// Kind 代理的资源类型，pods或services
+	Kind string `json:"kind" form:"kind" comment:"资源类型" validate:"required,oneof=pods services"`
+	Name string `json:"name" form:"name" comment:"名称" validate:"required"`
+	// Port 端口号，service也可以使用端口名称
+	Port string `json:"port" form:"port" comment:"端口" validate:"required"`
+}
+
+// ProxyTicketOut 签发反向代理票据接口出参
+type ProxyTicketOut struct {
+	// Ticket 代理票据，已包含在URL中，需要与接口下发的cookie一起使用
+	Ticket string `json:"ticket"`
+	// URL 反向代理的地址，在浏览器中直接打开，之后追加目标路径即可
+	URL string `json:"url"`
+	// ExpiresIn 有效期，单位秒
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+func (params *ProxyTicketInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// ProxyInput 反向代理接口的路径参数，请求体原样转发，不做绑定
+type ProxyInput struct {
+	NameSpace string `uri:"namespace" comment:"命名空间" validate:"required"`
+	// Kind 代理的资源类型，pods或services
+	Kind string `uri:"kind" comment:"资源类型" validate:"required,oneof=pods services"`
+	Name string `uri:"name" comment:"名称" validate:"required"`
+	// Port 端口号，service也可以使用端口名称
+	Port string `uri:"port" comment:"端口" validate:"required"`
+}
+
+func (params *ProxyInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidUriParams(c, params)
+}
@@ -23,13 +23,12 @@ type ServiceNameNS struct {
 type ServiceUpdateInput struct {
 	Content   string `json:"content" validate:"required" comment:"更新内容"`
 	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	DryRunInput
 }
 
 type ServiceListInput struct {
-	FilterName string `json:"filter_name" form:"filter_name" validate:"" comment:"过滤名"`
-	NameSpace  string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
-	Limit      int    `json:"limit" form:"limit" validate:"" comment:"分页限制"`
-	Page       int    `json:"page" form:"page" validate:"" comment:"页码"`
+	NameSpace string `json:"namespace" form:"namespace" validate:"" comment:"命名空间"`
+	DataSelectInput
 }
 
 func (params *ServiceNameNS) BindingValidParams(c *gin.Context) error {
@@ -12,6 +12,7 @@ type WorkFlowListInput struct {
 }
 
 type WorkFlowCreateInput struct {
+	Cluster       string                 `json:"cluster"`
 	Name          string                 `json:"name"`
 	NameSpace     string                 `json:"namespace"`
 	Replicas      int32                  `json:"replicas"`
@@ -27,6 +28,11 @@ type WorkFlowCreateInput struct {
 	Port          int32                  `json:"port"`
 	NodePort      int32                  `json:"node_port"`
 	Hosts         map[string][]*HttpPath `json:"hosts"`
+	// 以下为可选的hpa配置，MaxReplicas大于0时同时创建hpa，MinReplicas为0时使用Replicas
+	MinReplicas       int32 `json:"min_replicas" validate:"min=0" comment:"最小副本数"`
+	MaxReplicas       int32 `json:"max_replicas" validate:"min=0" comment:"最大副本数"`
+	CpuUtilization    int32 `json:"cpu_utilization" validate:"min=0" comment:"cpu目标使用率"`
+	MemoryUtilization int32 `json:"memory_utilization" validate:"min=0" comment:"内存目标使用率"`
 }
 
 type WorkFlowIDInput struct {
@@ -0,0 +1,36 @@
+package kubeDto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// SetImageInput 更新工作负载(deployment、statefulset、daemonset)容器镜像接口的入参结构
+type SetImageInput struct {
+	Name      string `json:"name" form:"name" comment:"工作负载名称" validate:"required"`
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:"required"`
+	// Images 容器名称到新镜像的映射，支持同时更新多个容器(包括init容器)
+	Images map[string]string `json:"images" form:"images" comment:"镜像" validate:"required,min=1"`
+	// ChangeCause 变更原因，记录在kubernetes.io/change-cause注解中，为空时自动生成
+	ChangeCause string `json:"change_cause" form:"change_cause" comment:"变更原因" validate:""`
+}
+
+func (params *SetImageInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// ApplyInput apply清单接口的入参结构
+type ApplyInput struct {
+	// Content 多文档的yaml或json清单，文档之间用---分隔
+	Content string `json:"content" form:"content" comment:"清单内容" validate:"required"`
+	// NameSpace 清单中命名空间级资源未指定命名空间时使用的命名空间，为空时使用default
+	NameSpace string `json:"namespace" form:"namespace" comment:"命名空间" validate:""`
+	// Force 与其他字段管理者冲突时是否强制覆盖
+	Force bool `json:"force" form:"force" comment:"强制覆盖" validate:""`
+	DryRunInput
+}
+
+func (params *ApplyInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+type RecordingListInput struct {
+	PageInfo
+	Username  string `json:"username" form:"username"`   // 用户名
+	Cluster   string `json:"cluster" form:"cluster"`     // 集群名称
+	Namespace string `json:"namespace" form:"namespace"` // 命名空间
+	Pod       string `json:"pod" form:"pod"`             // pod名称
+}
+
+type RecordingListOutPut struct {
+	Total         int64                      `json:"total"`
+	RecordingList []*model.TerminalRecording `json:"list"`
+	PageInfo
+}
+
+// RecordingPlayInput 分页回放录像，limit为0时每页返回1000个事件
+type RecordingPlayInput struct {
+	Offset int `json:"offset" form:"offset" validate:"min=0"`        // 跳过的事件数量
+	Limit  int `json:"limit" form:"limit" validate:"min=0,max=5000"` // 每页事件数量
+}
+
+// BindingValidParams 绑定并校验参数
+func (r *RecordingListInput) BindingValidParams(ctx *gin.Context) error {
+	return pkg.DefaultGetValidParams(ctx, r)
+}
+
+// BindingValidParams 绑定并校验参数
+func (r *RecordingPlayInput) BindingValidParams(ctx *gin.Context) error {
+	return pkg.DefaultGetValidParams(ctx, r)
+}
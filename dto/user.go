@@ -20,6 +20,14 @@ type AdminLoginOut struct {
 	Token string `form:"token" json:"token" comment:"token"  example:"token"`
 }
 
+// WebSocketTicketOut 签发websocket一次性票据接口出参
+type WebSocketTicketOut struct {
+	// Ticket 建立websocket连接时通过ticket查询参数携带，只能使用一次
+	Ticket string `json:"ticket"`
+	// ExpiresIn 有效期，单位秒
+	ExpiresIn int64 `json:"expires_in"`
+}
+
 type UserInfoOut struct {
 	User      model.SysUser   `json:"user"`
 	Menus     []model.SysMenu `json:"menus"`
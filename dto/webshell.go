@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// WebShellShareOut 共享web终端会话接口出参
+type WebShellShareOut struct {
+	// Token 共享令牌，会话结束或停止共享后失效
+	Token string `json:"token"`
+	// WatchURL 观看者建立websocket连接的地址，认证方式与web终端相同
+	WatchURL string `json:"watch_url"`
+}
+
+// WebShellControlInput 移交web终端控制权接口入参
+type WebShellControlInput struct {
+	// ViewerID 获得控制权的观看者id，为空时收回给会话所有者
+	ViewerID string `json:"viewer_id" form:"viewer_id"`
+}
+
+// WebShellWatchInput 观看共享web终端会话接口入参
+type WebShellWatchInput struct {
+	Share string `json:"share" form:"share" validate:"required"`
+}
+
+// CommandPolicyListInput 查询web终端命令策略接口入参，Keyword按名称和正则模糊查询
+type CommandPolicyListInput struct {
+	PageInfo
+	Action string `json:"action" form:"action" validate:"omitempty,oneof=block confirm"` // 动作
+}
+
+type CommandPolicyListOutPut struct {
+	Total      int64                          `json:"total"`
+	PolicyList []*model.TerminalCommandPolicy `json:"list"`
+	PageInfo
+}
+
+// CommandPolicyInput 创建web终端命令策略接口入参
+type CommandPolicyInput struct {
+	Name    string `json:"name" form:"name" comment:"策略名称" validate:"required"`
+	Pattern string `json:"pattern" form:"pattern" comment:"匹配命令行的正则表达式" validate:"required"`
+	Action  string `json:"action" form:"action" comment:"动作" validate:"required,oneof=block confirm"`
+	// Namespaces 生效的命名空间，为空时对所有命名空间生效
+	Namespaces []string `json:"namespaces" form:"namespaces" comment:"命名空间"`
+	// AuthorityIds 生效的角色id，为空时对所有角色生效
+	AuthorityIds []uint `json:"authority_ids" form:"authority_ids" comment:"角色id"`
+	Enabled      bool   `json:"enabled" form:"enabled" comment:"是否启用"`
+	Description  string `json:"description" form:"description" comment:"描述"`
+}
+
+// CommandPolicyUpdateInput 更新web终端命令策略接口入参
+type CommandPolicyUpdateInput struct {
+	ID int `json:"id" form:"id" comment:"策略ID" validate:"required"`
+	CommandPolicyInput
+}
+
+type CommandPolicyIDInput struct {
+	ID int `json:"id" form:"id" comment:"策略ID" validate:"required"`
+}
+
+func (params *WebShellControlInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+func (params *WebShellWatchInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// BindingValidParams 绑定并校验参数
+func (params *CommandPolicyListInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// BindingValidParams 绑定并校验参数
+func (params *CommandPolicyInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// BindingValidParams 绑定并校验参数
+func (params *CommandPolicyUpdateInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
+
+// BindingValidParams 绑定并校验参数
+func (params *CommandPolicyIDInput) BindingValidParams(c *gin.Context) error {
+	return pkg.DefaultGetValidParams(c, params)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/noovertime7/kubemanage/pkg"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
 	"github.com/noovertime7/kubemanage/pkg/globalError"
 	"github.com/noovertime7/kubemanage/pkg/utils"
@@ -17,26 +18,27 @@ func CasbinHandler() gin.HandlerFunc {
 		if AlwaysAllowPath.Has(c.Request.URL.Path) {
 			return
 		}
-		waitUse, err := utils.GetClaims(c)
-		if err != nil {
-			ResponseError(c, globalError.NewGlobalError(globalError.ServerError, err))
-			c.Abort()
-			return
-		}
-		// 获取请求的PATH
-		obj := c.Request.URL.Path
-		// 获取请求方法
-		act := c.Request.Method
-		// 获取用户的角色
-		sub := strconv.Itoa(int(waitUse.AuthorityId))
-		e := v1.CoreV1.System().CasbinService().Casbin() // 判断策略中是否存在
-		success, _ := e.Enforce(sub, obj, act)
-		if success {
-			c.Next()
-		} else {
-			ResponseError(c, globalError.NewGlobalError(globalError.AuthErr, fmt.Errorf("角色ID %d 请求 %s %s 无权限", waitUse.AuthorityId, act, obj)))
+		if err := CasbinEnforce(c, c.Request.URL.Path, c.Request.Method); err != nil {
+			ResponseError(c, err)
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
+
+// CasbinEnforce 校验当前用户的角色是否有权访问obj，除请求路径外也用于按命名空间等细粒度授权，
+// 无权限时返回AuthErr错误
+func CasbinEnforce(c *gin.Context, obj, act string) error {
+	waitUse, err := utils.GetClaims(c)
+	if err != nil {
+		return globalError.NewGlobalError(globalError.ServerError, err)
+	}
+	// 获取用户的角色
+	sub := strconv.Itoa(int(waitUse.AuthorityId))
+	e := v1.CoreV1.System().CasbinService().Casbin() // 判断策略中是否存在
+	if success, _ := e.Enforce(sub, obj, act); !success {
+		return globalError.NewGlobalError(globalError.AuthErr, fmt.Errorf("角色ID %d 请求 %s %s 无权限", waitUse.AuthorityId, act, pkg.RedactProxyTicket(obj)))
 	}
+	return nil
 }
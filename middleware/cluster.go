@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/pkg"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"github.com/noovertime7/kubemanage/pkg/globalError"
+)
+
+// K8sCluster 根据请求中的cluster参数，从集群注册表中选出对应集群的客户端，保存到上下文中
+// 路径中的cluster参数优先于查询参数，未携带cluster参数时使用默认集群
+func K8sCluster() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("cluster")
+		if name == "" {
+			name = c.Query("cluster")
+		}
+		cli, err := kube.Clusters.Get(name)
+		if err != nil {
+			ResponseError(c, globalError.NewGlobalError(globalError.ClusterNotExistError, err))
+			c.Abort()
+			return
+		}
+		c.Set(pkg.ClusterKey, cli)
+		c.Next()
+	}
+}
+
+// GetK8sClient 取出 K8sCluster 中间件选定的集群客户端
+func GetK8sClient(c *gin.Context) *kube.K8sClient {
+	return c.MustGet(pkg.ClusterKey).(*kube.K8sClient)
+}
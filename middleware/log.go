@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/pkg"
 	"github.com/noovertime7/kubemanage/pkg/logger"
 	"go.uber.org/zap"
 	"time"
@@ -11,7 +12,8 @@ import (
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
+		// 反向代理路径中的票据不写入日志
+		path := pkg.RedactProxyTicket(c.Request.URL.Path)
 		query := c.Request.URL.RawQuery
 		c.Next()
 
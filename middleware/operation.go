@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,8 +14,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/pkg"
 	"github.com/noovertime7/kubemanage/pkg/logger"
 	"github.com/noovertime7/kubemanage/pkg/utils"
 )
@@ -37,8 +40,10 @@ func OperationRecord() gin.HandlerFunc {
 			userId int
 			body   []byte
 		)
+		// 上传文件的请求不读取body，请求处理完成后只记录表单字段和文件名
+		isMultipart := strings.HasPrefix(c.ContentType(), "multipart/form-data")
 		//如果请求不是get请求，从body中获取数据
-		if c.Request.Method != http.MethodGet {
+		if c.Request.Method != http.MethodGet && !isMultipart {
 			var err error
 			body, err = io.ReadAll(c.Request.Body)
 			if err != nil {
@@ -46,7 +51,7 @@ func OperationRecord() gin.HandlerFunc {
 			} else {
 				c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
 			}
-		} else {
+		} else if c.Request.Method == http.MethodGet {
 			query := c.Request.URL.RawQuery
 			query, _ = url.QueryUnescape(query)
 			split := strings.Split(query, "&")
@@ -74,7 +79,8 @@ func OperationRecord() gin.HandlerFunc {
 		record := model.SysOperationRecord{
 			Ip:     c.ClientIP(),
 			Method: c.Request.Method,
-			Path:   c.Request.URL.Path,
+			// 反向代理路径中的票据不保存到操作记录
+			Path:   pkg.RedactProxyTicket(c.Request.URL.Path),
 			Agent:  c.Request.UserAgent(),
 			Body:   string(body),
 			UserID: userId,
@@ -89,8 +95,12 @@ func OperationRecord() gin.HandlerFunc {
 		writer := responseBodyWriter{
 			ResponseWriter: c.Writer,
 			body:           &bytes.Buffer{},
+			streaming:      streamResponsePaths.Has(c.FullPath()),
+		}
+		// 反向代理的响应来自集群内的服务，不记录响应内容
+		if !strings.HasPrefix(c.Request.URL.Path, pkg.K8sProxyURLPrefix) {
+			c.Writer = writer
 		}
-		c.Writer = writer
 		now := time.Now()
 
 		c.Next()
@@ -100,6 +110,9 @@ func OperationRecord() gin.HandlerFunc {
 		record.Status = c.Writer.Status()
 		record.Latency = latency
 		record.Resp = writer.body.String()
+		if isMultipart {
+			record.Body = multipartSummary(c.Request.MultipartForm)
+		}
 		//
 		//if len(record.Resp) > 1024 {
 		//	// 截断
@@ -115,12 +128,59 @@ func OperationRecord() gin.HandlerFunc {
 	}
 }
 
+// multipartSummary 将上传请求的表单字段和文件名、大小序列化为json，不包含文件内容
+func multipartSummary(form *multipart.Form) string {
+	if form == nil {
+		return ""
+	}
+	summary := make(map[string]interface{}, len(form.Value)+len(form.File))
+	for k, v := range form.Value {
+		if len(v) > 0 {
+			summary[k] = v[0]
+		}
+	}
+	for k, headers := range form.File {
+		files := make([]map[string]interface{}, 0, len(headers))
+		for _, h := range headers {
+			files = append(files, map[string]interface{}{"filename": h.Filename, "size": h.Size})
+		}
+		summary[k] = files
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		logger.LG.Error("marshal body error:", zap.Error(err))
+		return ""
+	}
+	return string(data)
+}
+
+// maxRecordRespSize 流式接口在操作记录中保存的响应内容上限，避免流式响应被完整缓存在内存中
+const maxRecordRespSize = 4096
+
+// streamResponsePaths 流式返回日志或附件的接口
+var streamResponsePaths = sets.NewString(
+	"/api/k8s/pod/log/download",
+	"/api/k8s/pod/file/download",
+)
+
 type responseBodyWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
+	// streaming 为true时附件内容不记录，其他响应只记录开头的 maxRecordRespSize 字节
+	streaming bool
 }
 
 func (r responseBodyWriter) Write(b []byte) (int, error) {
-	r.body.Write(b)
+	if !r.streaming {
+		r.body.Write(b)
+		return r.ResponseWriter.Write(b)
+	}
+	if remain := maxRecordRespSize - r.body.Len(); remain > 0 && r.Header().Get("Content-Disposition") == "" {
+		if len(b) > remain {
+			r.body.Write(b[:remain])
+		} else {
+			r.body.Write(b)
+		}
+	}
 	return r.ResponseWriter.Write(b)
 }
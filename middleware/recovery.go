@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/noovertime7/kubemanage/pkg"
 	"github.com/noovertime7/kubemanage/pkg/logger"
 	"net"
 	"net/http"
@@ -29,9 +30,14 @@ func Recovery(stack bool) gin.HandlerFunc {
 					}
 				}
 
-				httpRequest, _ := httputil.DumpRequest(c.Request, false)
+				// 反向代理路径中的票据不写入日志
+				req := c.Request.Clone(c.Request.Context())
+				req.RequestURI = pkg.RedactProxyTicket(req.RequestURI)
+				req.URL.Path = pkg.RedactProxyTicket(req.URL.Path)
+				req.URL.RawPath = ""
+				httpRequest, _ := httputil.DumpRequest(req, false)
 				if brokenPipe {
-					logger.LG.Error(c.Request.URL.Path,
+					logger.LG.Error(pkg.RedactProxyTicket(c.Request.URL.Path),
 						zap.Any("error", err),
 						zap.String("request", string(httpRequest)),
 					)
@@ -44,11 +44,15 @@ func ResponseError(c *gin.Context, err error) {
 	//判断错误类型
 	// As - 获取错误的具体实现
 	var code ResponseCode
+	var data interface{} = ""
 	var myError = new(globalError.GlobalError)
 	if errors.As(err, &myError) {
 		code = ResponseCode(myError.Code)
+		if myError.Data != nil {
+			data = myError.Data
+		}
 	}
-	resp := &Response{Code: code, Msg: err.Error(), RealErr: myError.RealErrorMessage, Data: ""}
+	resp := &Response{Code: code, Msg: err.Error(), RealErr: myError.RealErrorMessage, Data: data}
 	// 即使发生错误，也只是在请求的response中展示，请求本身的响应状态码还应该设置为200
 	c.JSON(200, resp)
 	// 将resp对象，序列化成json格式
@@ -5,14 +5,25 @@ import "strconv"
 const (
 	ValidatorKey  = "ValidatorKey"
 	TranslatorKey = "TranslatorKey"
+	ClusterKey    = "ClusterKey"
 )
 
 const (
-	LoginURL    = "/api/user/login"
-	LogoutURL   = "/api/user/logout"
-	WebShellURL = "/api/k8s/pod/webshell"
+	LoginURL  = "/api/user/login"
+	LogoutURL = "/api/user/logout"
+	// K8sProxyURLPrefix 反向代理到pod或service端口的路径前缀
+	K8sProxyURLPrefix = "/api/k8s/proxy/"
+	// WebShellNamespaceObj 按命名空间授权web终端时casbin使用的资源路径
+	WebShellNamespaceObj = "/api/k8s/pod/webshell/namespace/"
+	// WebShellWatchURL 观看共享web终端会话的websocket地址
+	WebShellWatchURL = "/api/webshell/watch"
+	// PodLogURL 获取容器日志的地址，用于在任务历史等页面中生成日志链接
+	PodLogURL = "/api/k8s/pod/log"
 )
 
+// WebSocketTokenProtocolPrefix 浏览器无法为websocket设置请求头，可以在Sec-WebSocket-Protocol中携带 token.<jwt> 形式的子协议
+const WebSocketTokenProtocolPrefix = "token."
+
 var (
 	AdminDefaultAuth      uint = 111
 	AdminDefaultAuthStr        = strconv.Itoa(int(AdminDefaultAuth))
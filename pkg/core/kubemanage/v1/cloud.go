@@ -18,9 +18,13 @@ type cloud struct {
 	factory dao.ShareDaoFactory
 }
 
-func (c *cloud) Pods(cloud string) kube.PodInterface {
-	// TODO 临时添加，需要重构
-	return kube.NewPods(nil, "", c.factory)
+// Pods 根据集群名称从集群注册表中取出客户端，集群不存在时返回错误
+func (c *cloud) Pods(cloud string) (kube.PodInterface, error) {
+	cli, err := kube.Clusters.Get(cloud)
+	if err != nil {
+		return nil, err
+	}
+	return kube.NewPods(cli, cloud, c.factory), nil
 }
 
 func NewCloud(c *KubeManage) CloudInterface {
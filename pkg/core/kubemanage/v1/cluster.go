@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+)
+
+// ClusterServiceGetter ClusterService对象获取器
+type ClusterServiceGetter interface {
+	Cluster() ClusterService
+}
+
+// ClusterService 集群管理，负责集群信息的持久化以及集群注册表的维护
+type ClusterService interface {
+	// Create 导入集群，校验kubeconfig可用后入库并注册到集群注册表
+	Create(ctx context.Context, in *dto.ClusterCreateInput, kubeConfig []byte) error
+	// Delete 删除集群，同时从集群注册表中移除
+	Delete(ctx context.Context, id int) error
+	Find(ctx context.Context, id int) (*model.Cluster, error)
+	FindList(ctx context.Context, in *dto.ClusterListInput) (*ClusterResp, error)
+	// Register 服务启动时将数据库中的集群全部注册到集群注册表
+	Register(ctx context.Context) error
+}
+
+type ClusterResp struct {
+	Items []*model.Cluster `json:"items"`
+	Total int              `json:"total"`
+}
+
+type clusterService struct {
+	app     *KubeManage
+	factory dao.ShareDaoFactory
+}
+
+var _ ClusterService = &clusterService{}
+
+func NewClusterService(app *KubeManage) *clusterService {
+	return &clusterService{
+		app:     app,
+		factory: app.Factory,
+	}
+}
+
+func (c *clusterService) Create(ctx context.Context, in *dto.ClusterCreateInput, kubeConfig []byte) error {
+	// default为本地集群在注册表中的名称，导入同名集群会覆盖本地集群
+	if in.Name == kube.DefaultClusterName {
+		return fmt.Errorf("集群名称 %s 为本地集群保留名称", in.Name)
+	}
+	if exist, err := c.factory.Cluster().FindList(ctx, &model.Cluster{Name: in.Name}); err != nil {
+		return err
+	} else if len(exist) != 0 {
+		return fmt.Errorf("集群 %s 已存在", in.Name)
+	}
+	cli, err := kube.NewK8sClientFromKubeConfig(in.Name, kubeConfig)
+	if err != nil {
+		return err
+	}
+	// 导入前先确认集群可以连通
+	version, err := cli.ClientSet.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("连接集群 %s 失败: %v", in.Name, err)
+	}
+	cluster := &model.Cluster{
+		Name:        in.Name,
+		Description: in.Description,
+		Version:     version.GitVersion,
+		KubeConfig:  string(kubeConfig),
+	}
+	if err = c.factory.Cluster().Save(ctx, cluster); err != nil {
+		return err
+	}
+	kube.Clusters.Set(cli)
+	return nil
+}
+
+func (c *clusterService) Delete(ctx context.Context, id int) error {
+	cluster, err := c.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = c.factory.Cluster().Delete(ctx, id); err != nil {
+		return err
+	}
+	kube.Clusters.Delete(cluster.Name)
+	return nil
+}
+
+func (c *clusterService) Find(ctx context.Context, id int) (*model.Cluster, error) {
+	return c.factory.Cluster().Find(ctx, &model.Cluster{ID: id})
+}
+
+func (c *clusterService) FindList(ctx context.Context, in *dto.ClusterListInput) (*ClusterResp, error) {
+	clusters, total, err := c.factory.Cluster().PageList(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return &ClusterResp{
+		Items: clusters,
+		Total: total,
+	}, nil
+}
+
+func (c *clusterService) Register(ctx context.Context) error {
+	clusters, err := c.factory.Cluster().FindList(ctx, &model.Cluster{})
+	if err != nil {
+		return err
+	}
+	for _, cluster := range clusters {
+		// 历史数据中可能存在与本地集群同名的集群，跳过以免覆盖本地集群
+		if cluster.Name == kube.DefaultClusterName {
+			Log.Warn(fmt.Sprintf("集群名称 %s 为本地集群保留名称，跳过注册", cluster.Name))
+			continue
+		}
+		cli, err := kube.NewK8sClientFromKubeConfig(cluster.Name, []byte(cluster.KubeConfig))
+		if err != nil {
+			// 单个集群配置有误不影响其他集群
+			Log.ErrorWithErr(fmt.Sprintf("注册集群 %s 失败", cluster.Name), err)
+			continue
+		}
+		kube.Clusters.Set(cli)
+	}
+	return nil
+}
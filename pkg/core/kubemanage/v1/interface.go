@@ -11,6 +11,7 @@ type CoreService interface {
 	WorkFlowServiceGetter
 	CloudGetter
 	SystemGetter
+	ClusterServiceGetter
 }
 
 func New(cfg *config.Config, factory dao.ShareDaoFactory) CoreService {
@@ -41,3 +42,7 @@ func (c *KubeManage) Cloud() CloudInterface {
 func (c *KubeManage) System() SystemInterface {
 	return NewSystem(c)
 }
+
+func (c *KubeManage) Cluster() ClusterService {
+	return NewClusterService(c)
+}
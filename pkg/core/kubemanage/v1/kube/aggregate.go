@@ -0,0 +1,248 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// maxAggregateStreams 聚合日志时同时跟踪的容器数量上限，避免一次打开过多日志流
+const maxAggregateStreams = 100
+
+// AggregateQuery 聚合日志的查询条件
+type AggregateQuery struct {
+	NameSpace string
+	Selector  labels.Selector
+	// Container 容器名称的正则表达式，为nil时跟踪所有容器
+	Container *regexp.Regexp
+	Options   *kubeDto.PodLogOptionsInput
+}
+
+// NewAggregateQuery 将聚合日志的入参转换为查询条件，指定工作负载时使用其spec.selector
+func NewAggregateQuery(cli *K8sClient, in *kubeDto.PodLogAggregateInput) (*AggregateQuery, error) {
+	query := &AggregateQuery{NameSpace: in.NameSpace, Options: &in.PodLogOptionsInput}
+	// 提前校验日志参数，避免每个容器都报同样的错误
+	if _, err := NewPodLogOptions("", &in.PodLogOptionsInput); err != nil {
+		return nil, err
+	}
+	var err error
+	if in.WorkloadName != "" {
+		query.Selector, err = WorkloadSelector(cli, in.WorkloadKind, in.WorkloadName, in.NameSpace)
+	} else {
+		query.Selector, err = labels.Parse(in.LabelSelector)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if query.Selector.Empty() {
+		return nil, fmt.Errorf("标签选择器不能为空")
+	}
+	if in.Container != "" {
+		if query.Container, err = regexp.Compile(in.Container); err != nil {
+			return nil, fmt.Errorf("container格式有误: %v", err)
+		}
+	}
+	return query, nil
+}
+
+// AggregatePodLogs 同时读取所有匹配pod中容器的日志，每行以 [pod/container] 为前缀通过send推送，operation为stdout；
+// 开始和停止跟踪某个容器时推送operation为event的消息，内容分别以 + 和 - 开头。
+// follow模式下持续监听pod的变化，新增或重启的容器自动加入，删除的pod停止跟踪，直到ctx结束
+func (p *pod) AggregatePodLogs(ctx context.Context, cli *K8sClient, query *AggregateQuery, send func(operation, data string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	a := &logAggregator{
+		ctx:    ctx,
+		cancel: cancel,
+		cli:    cli,
+		query:  query,
+		send:   send,
+		tails:  map[string]context.CancelFunc{},
+		ended:  map[string]time.Time{},
+	}
+
+	if !query.Options.Follow {
+		pods, err := cli.ClientSet.CoreV1().Pods(query.NameSpace).List(ctx, metaV1.ListOptions{LabelSelector: query.Selector.String()})
+		if err != nil {
+			return err
+		}
+		for i := range pods.Items {
+			a.sync(&pods.Items[i])
+		}
+		a.wg.Wait()
+		return a.sendError()
+	}
+
+	lw := cache.NewFilteredListWatchFromClient(cli.ClientSet.CoreV1().RESTClient(), "pods", query.NameSpace, func(options *metaV1.ListOptions) {
+		options.LabelSelector = query.Selector.String()
+	})
+	_, controller := cache.NewInformer(lw, &coreV1.Pod{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			a.sync(obj.(*coreV1.Pod))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			a.sync(obj.(*coreV1.Pod))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if pod, ok := obj.(*coreV1.Pod); ok {
+				a.drop(pod.Name)
+			}
+		},
+	})
+	controller.Run(ctx.Done())
+	a.wg.Wait()
+	return a.sendError()
+}
+
+// logAggregator 管理聚合日志中每个容器的日志流，key为 pod/container
+type logAggregator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	cli    *K8sClient
+	query  *AggregateQuery
+	send   func(operation, data string) error
+
+	lock  sync.Mutex
+	tails map[string]context.CancelFunc
+	// ended 容器日志流上一次结束的时间，重新打开时从该时间开始读取，避免重复推送已经推送过的日志
+	ended map[string]time.Time
+	// limited 是否已经提示过超出跟踪上限
+	limited bool
+	err     error
+	// sendLock websocket同一时间只允许一个写入者
+	sendLock sync.Mutex
+	wg       sync.WaitGroup
+}
+
+// sync 为pod中正在运行的容器打开日志流，非follow模式下已退出的容器也会读取
+func (a *logAggregator) sync(pod *coreV1.Pod) {
+	statuses := append(append([]coreV1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if a.query.Container != nil && !a.query.Container.MatchString(status.Name) {
+			continue
+		}
+		if status.State.Running != nil || (!a.query.Options.Follow && status.State.Terminated != nil) {
+			a.tail(pod.Name, status.Name)
+		}
+	}
+}
+
+// tail 跟踪单个容器的日志，已在跟踪或超出上限时忽略；日志流结束后移除，容器重启后由下一次sync重新打开，
+// 并从上一次日志流结束的时间继续读取
+func (a *logAggregator) tail(podName, container string) {
+	key := podName + "/" + container
+	a.lock.Lock()
+	if _, ok := a.tails[key]; ok || a.ctx.Err() != nil {
+		a.lock.Unlock()
+		return
+	}
+	if len(a.tails) >= maxAggregateStreams {
+		limited := a.limited
+		a.limited = true
+		a.lock.Unlock()
+		if !limited {
+			a.emit("event", fmt.Sprintf("! 跟踪的容器数量超过上限 %d，忽略 %s", maxAggregateStreams, key))
+		}
+		return
+	}
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.tails[key] = cancel
+	ended, reopen := a.ended[key]
+	a.wg.Add(1)
+	a.lock.Unlock()
+
+	go func() {
+		defer a.wg.Done()
+		defer func() {
+			cancel()
+			a.lock.Lock()
+			delete(a.tails, key)
+			a.ended[key] = time.Now()
+			a.lock.Unlock()
+			a.emit("event", "- "+key)
+		}()
+		a.emit("event", "+ "+key)
+		opts, err := NewPodLogOptions(container, a.query.Options)
+		if err != nil {
+			a.emit("event", fmt.Sprintf("! %s: %v", key, err))
+			return
+		}
+		// 重新打开时不再按tail_lines读取，只读取上一次日志流结束之后的日志
+		if reopen {
+			since := metaV1.NewTime(ended)
+			opts.TailLines, opts.SinceSeconds, opts.SinceTime = nil, nil, &since
+		}
+		stream, err := Pod.StreamPodLog(ctx, a.cli, podName, a.query.NameSpace, opts)
+		if err != nil {
+			a.emit("event", fmt.Sprintf("! %s: %v", key, err))
+			return
+		}
+		defer stream.Close()
+		prefix := "[" + key + "] "
+		if err := PumpLog(stream, func(data string) error {
+			return a.emit("stdout", prefixLines(prefix, data))
+		}); err != nil && ctx.Err() == nil {
+			a.emit("event", fmt.Sprintf("! %s: %v", key, err))
+		}
+	}()
+}
+
+// drop 停止跟踪pod中的所有容器
+func (a *logAggregator) drop(podName string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for key, cancel := range a.tails {
+		if strings.HasPrefix(key, podName+"/") {
+			cancel()
+		}
+	}
+}
+
+// emit 串行推送消息，推送失败说明客户端已断开或读取过慢，结束整个聚合
+func (a *logAggregator) emit(operation, data string) error {
+	a.sendLock.Lock()
+	defer a.sendLock.Unlock()
+	if a.err != nil {
+		return a.err
+	}
+	if err := a.send(operation, data); err != nil {
+		a.err = err
+		a.cancel()
+		return err
+	}
+	return nil
+}
+
+func (a *logAggregator) sendError() error {
+	a.sendLock.Lock()
+	defer a.sendLock.Unlock()
+	return a.err
+}
+
+// prefixLines 为每一行日志加上前缀
+func prefixLines(prefix, data string) string {
+	lines := strings.SplitAfter(data, "\n")
+	var b strings.Builder
+	b.Grow(len(data) + len(lines)*len(prefix))
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		b.WriteString(prefix)
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/noovertime7/kubemanage/pkg/logger"
+)
+
+// ErrCacheNotSynced 集群缓存尚未同步完成
+var ErrCacheNotSynced = errors.New("集群缓存尚未同步完成，请稍后重试")
+
+// registerInformers 注册需要缓存的资源，列表接口都从这些资源的本地缓存中读取
+// informer 必须在 Informers.Start 之前注册，否则不会被启动
+func (k *K8sClient) registerInformers() []cache.InformerSynced {
+	return []cache.InformerSynced{
+		k.Informers.Core().V1().Pods().Informer().HasSynced,
+		k.Informers.Core().V1().Nodes().Informer().HasSynced,
+		k.Informers.Core().V1().Namespaces().Informer().HasSynced,
+		k.Informers.Core().V1().Services().Informer().HasSynced,
+		k.Informers.Core().V1().ConfigMaps().Informer().HasSynced,
+		k.Informers.Core().V1().Secrets().Informer().HasSynced,
+		k.Informers.Core().V1().PersistentVolumes().Informer().HasSynced,
+		k.Informers.Core().V1().PersistentVolumeClaims().Informer().HasSynced,
+		k.Informers.Apps().V1().Deployments().Informer().HasSynced,
+		k.Informers.Apps().V1().DaemonSets().Informer().HasSynced,
+		k.Informers.Apps().V1().StatefulSets().Informer().HasSynced,
+		k.Informers.Batch().V1().Jobs().Informer().HasSynced,
+		k.Informers.Networking().V1().Ingresses().Informer().HasSynced,
+	}
+}
+
+// Run 启动集群的 informer，缓存同步完成后将集群标记为就绪，不会阻塞调用方
+func (k *K8sClient) Run() {
+	if k.stopCh != nil {
+		return
+	}
+	k.stopCh = make(chan struct{})
+	synced := k.registerInformers()
+	k.Informers.Start(k.stopCh)
+	go func() {
+		if !cache.WaitForCacheSync(k.stopCh, synced...) {
+			// 只有在同步完成前集群被移除时才会走到这里
+			return
+		}
+		atomic.StoreInt32(&k.synced, 1)
+		logger.New().Info(fmt.Sprintf("集群 %s 缓存同步完成", k.Name))
+	}()
+}
+
+// Stop 停止集群的 informer，集群被移除或替换时调用
+func (k *K8sClient) Stop() {
+	if k.stopCh == nil {
+		return
+	}
+	close(k.stopCh)
+	k.stopCh = nil
+	atomic.StoreInt32(&k.synced, 0)
+}
+
+// HasSynced 集群缓存是否同步完成
+func (k *K8sClient) HasSynced() bool {
+	return atomic.LoadInt32(&k.synced) == 1
+}
+
+// CacheSynced 缓存未同步完成时返回 ErrCacheNotSynced
+func (k *K8sClient) CacheSynced() error {
+	if !k.HasSynced() {
+		return ErrCacheNotSynced
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Clusters 全局的集群注册表，缓存每个集群的客户端，增删集群时同步更新，无需重启服务
+var Clusters = &clusterStore{clients: map[string]*K8sClient{}}
+
+// clusterStore 按集群名称保存客户端
+type clusterStore struct {
+	lock    sync.RWMutex
+	clients map[string]*K8sClient
+}
+
+// Get 根据集群名称获取客户端，名称为空时返回默认集群
+func (s *clusterStore) Get(name string) (*K8sClient, error) {
+	if name == "" {
+		name = DefaultClusterName
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	cli, ok := s.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("集群 %s 不存在", name)
+	}
+	return cli, nil
+}
+
+// Set 注册集群客户端并启动其 informer，同名集群会被替换，旧客户端的 informer 随之停止
+func (s *clusterStore) Set(cli *K8sClient) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if old, ok := s.clients[cli.Name]; ok && old != cli {
+		old.Stop()
+	}
+	s.clients[cli.Name] = cli
+	cli.Run()
+}
+
+// Delete 从注册表中移除集群，并停止其 informer
+func (s *clusterStore) Delete(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if cli, ok := s.clients[name]; ok {
+		cli.Stop()
+	}
+	delete(s.clients, name)
+}
+
+// Names 获取已注册的集群名称列表
+func (s *clusterStore) Names() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	names := make([]string, 0, len(s.clients))
+	for name := range s.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Synced 获取每个集群的缓存同步状态
+func (s *clusterStore) Synced() map[string]bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	synced := make(map[string]bool, len(s.clients))
+	for name, cli := range s.clients {
+		synced[name] = cli.HasSynced()
+	}
+	return synced
+}
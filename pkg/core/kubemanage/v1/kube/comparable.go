@@ -0,0 +1,79 @@
+package kube
+
+import (
+	"strings"
+	"time"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComparableValue 排序字段的值，同一字段的值类型一致，可以相互比较
+type ComparableValue interface {
+	// Compare 小于other时返回-1，等于返回0，大于返回1
+	Compare(other ComparableValue) int
+}
+
+type StdComparableInt int64
+
+func (v StdComparableInt) Compare(other ComparableValue) int {
+	o := other.(StdComparableInt)
+	return intsCompare(int64(v), int64(o))
+}
+
+type StdComparableFloat float64
+
+func (v StdComparableFloat) Compare(other ComparableValue) int {
+	o := other.(StdComparableFloat)
+	switch {
+	case v < o:
+		return -1
+	case v > o:
+		return 1
+	}
+	return 0
+}
+
+type StdComparableString string
+
+func (v StdComparableString) Compare(other ComparableValue) int {
+	o := other.(StdComparableString)
+	return strings.Compare(string(v), string(o))
+}
+
+type StdComparableTime time.Time
+
+func (v StdComparableTime) Compare(other ComparableValue) int {
+	o := other.(StdComparableTime)
+	return intsCompare(time.Time(v).UnixNano(), time.Time(o).UnixNano())
+}
+
+func intsCompare(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// objectMetaProperty 所有资源通用的排序字段：name、namespace、creation
+func objectMetaProperty(meta metaV1.ObjectMeta, name string) ComparableValue {
+	switch name {
+	case "name":
+		return StdComparableString(meta.Name)
+	case "namespace":
+		return StdComparableString(meta.Namespace)
+	case "creation":
+		return StdComparableTime(meta.CreationTimestamp.Time)
+	}
+	return nil
+}
+
+// readyRatio 计算就绪比例，期望数为0时视为全部就绪
+func readyRatio(ready, desired int32) StdComparableFloat {
+	if desired == 0 {
+		return 1
+	}
+	return StdComparableFloat(float64(ready) / float64(desired))
+}
@@ -3,8 +3,11 @@ package kube
 import (
 	"context"
 	"encoding/json"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var Configmap configmap
@@ -14,6 +17,8 @@ type configmap struct{}
 type ConfigmapResp struct {
 	Total int                `json:"total"`
 	Items []coreV1.ConfigMap `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []ConfigmapRow `json:"rows,omitempty"`
 }
 
 type ConfigmapNp struct {
@@ -21,10 +26,10 @@ type ConfigmapNp struct {
 	ConfigmapNum int    `json:"configmap_num"`
 }
 
-func (d *configmap) toCells(Configmaps []coreV1.ConfigMap) []DataCell {
+func (d *configmap) toCells(Configmaps []*coreV1.ConfigMap) []DataCell {
 	cells := make([]DataCell, len(Configmaps))
 	for i := range Configmaps {
-		cells[i] = configmapCell(Configmaps[i])
+		cells[i] = configmapCell(*Configmaps[i])
 	}
 	return cells
 }
@@ -37,50 +42,60 @@ func (d *configmap) FromCells(cells []DataCell) []coreV1.ConfigMap {
 	return Configmaps
 }
 
-func (d *configmap) GetConfigmaps(filterName, namespace string, limit, page int) (*ConfigmapResp, error) {
-	ConfigmapList, err := K8sCli.ClientSet.CoreV1().ConfigMaps(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (d *configmap) GetConfigmaps(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*ConfigmapResp, error) {
+	query, err := NewDataSelectQuery(in, configmapCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	ConfigmapList, err := cli.Informers.Core().V1().ConfigMaps().Lister().ConfigMaps(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	selectableData := &dataSelector{
-		GenericDataList: d.toCells(ConfigmapList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: d.toCells(ConfigmapList),
+		DataSelect:      query,
 	}
 	filterd := selectableData.Filter()
 	total := len(filterd.GenericDataList)
 	data := filterd.Sort().Paginate()
 	Configmaps := d.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]ConfigmapRow, len(Configmaps))
+		for i := range Configmaps {
+			rows[i] = toConfigmapRow(Configmaps[i])
+		}
+		return &ConfigmapResp{Total: total, Rows: rows}, nil
+	}
 	return &ConfigmapResp{
 		Total: total,
 		Items: Configmaps,
 	}, nil
 }
 
-func (d *configmap) GetConfigmapDetail(name, namespace string) (*coreV1.ConfigMap, error) {
-	data, err := K8sCli.ClientSet.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+func (d *configmap) GetConfigmapDetail(cli *K8sClient, name, namespace string) (*coreV1.ConfigMap, error) {
+	data, err := cli.ClientSet.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (d *configmap) DeleteConfigmap(name, namespace string) error {
-	return K8sCli.ClientSet.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (d *configmap) DeleteConfigmap(cli *K8sClient, name, namespace string) error {
+	return cli.ClientSet.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
 
-func (d *configmap) UpdateConfigmap(content, namespace string) error {
+func (d *configmap) UpdateConfigmap(cli *K8sClient, content, namespace string, opt kubeDto.DryRunInput) (*Preview, error) {
 	var Configmap = &coreV1.ConfigMap{}
 	if err := json.Unmarshal([]byte(content), Configmap); err != nil {
-		return err
-	}
-	if _, err := K8sCli.ClientSet.CoreV1().ConfigMaps(namespace).Update(context.TODO(), Configmap, metaV1.UpdateOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.CoreV1().ConfigMaps(namespace)
+	return updateWithPreview(opt, Configmap, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), Configmap.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), Configmap, opts)
+	})
 }
@@ -0,0 +1,306 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	batchV1 "k8s.io/api/batch/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// manualJobAnnotation 手动触发的job的注解，与kubectl create job --from=cronjob一致
+const manualJobAnnotation = "cronjob.kubernetes.io/instantiate"
+
+var CronJob cronJob
+
+type cronJob struct{}
+
+type CronJobResp struct {
+	Total int               `json:"total"`
+	Items []batchV1.CronJob `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []CronJobRow `json:"rows,omitempty"`
+}
+
+// CronJobRun cronjob创建的一次任务
+type CronJobRun struct {
+	Name string `json:"name"`
+	// Status 任务状态，取值为Running、Succeeded、Failed、Suspended
+	Status string `json:"status"`
+	// Manual 是否为手动触发的任务
+	Manual         bool         `json:"manual"`
+	Completions    string       `json:"completions"`
+	Active         int32        `json:"active"`
+	Succeeded      int32        `json:"succeeded"`
+	Failed         int32        `json:"failed"`
+	StartTime      *metaV1.Time `json:"start_time"`
+	CompletionTime *metaV1.Time `json:"completion_time"`
+	Duration       string       `json:"duration"`
+	Pods           []JobPod     `json:"pods"`
+	created        metaV1.Time
+}
+
+// JobPod 任务创建的pod，LogURLs为每个容器的日志地址
+type JobPod struct {
+	Name    string            `json:"name"`
+	Phase   string            `json:"phase"`
+	LogURLs map[string]string `json:"log_urls"`
+}
+
+func (c *cronJob) toCells(cronJobs []batchV1.CronJob) []DataCell {
+	cells := make([]DataCell, len(cronJobs))
+	for i := range cronJobs {
+		cells[i] = cronJobCell(cronJobs[i])
+	}
+	return cells
+}
+
+func (c *cronJob) FromCells(cells []DataCell) []batchV1.CronJob {
+	cronJobs := make([]batchV1.CronJob, len(cells))
+	for i := range cells {
+		cronJobs[i] = batchV1.CronJob(cells[i].(cronJobCell))
+	}
+	return cronJobs
+}
+
+// GetCronJobs batch/v1 版本的cronjob需要kubernetes 1.21及以上版本，为了不影响低版本集群的缓存同步，cronjob不注册informer，直接从apiserver查询
+func (c *cronJob) GetCronJobs(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*CronJobResp, error) {
+	query, err := NewDataSelectQuery(in, cronJobCell{})
+	if err != nil {
+		return nil, err
+	}
+	cronJobList, err := cli.ClientSet.BatchV1().CronJobs(namespace).List(context.TODO(), metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	selectableData := &dataSelector{
+		GenericDataList: c.toCells(cronJobList.Items),
+		DataSelect:      query,
+	}
+	filterd := selectableData.Filter()
+	total := len(filterd.GenericDataList)
+	data := filterd.Sort().Paginate()
+	cronJobs := c.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]CronJobRow, len(cronJobs))
+		for i := range cronJobs {
+			rows[i] = toCronJobRow(cronJobs[i])
+		}
+		return &CronJobResp{Total: total, Rows: rows}, nil
+	}
+	return &CronJobResp{
+		Total: total,
+		Items: cronJobs,
+	}, nil
+}
+
+func (c *cronJob) GetCronJobDetail(cli *K8sClient, name, namespace string) (*batchV1.CronJob, error) {
+	data, err := cli.ClientSet.BatchV1().CronJobs(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// CreateCronJob 新增cronjob，任务模板与创建job的参数一致
+func (c *cronJob) CreateCronJob(cli *K8sClient, data *kubeDto.CronJobCreateInput) error {
+	spec, err := jobSpecOf(&data.JobCreateInput)
+	if err != nil {
+		return err
+	}
+	cronJob := &batchV1.CronJob{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      data.Name,
+			Namespace: data.NameSpace,
+			Labels:    data.Labels,
+		},
+		Spec: batchV1.CronJobSpec{
+			Schedule:                   data.Schedule,
+			ConcurrencyPolicy:          batchV1.ConcurrencyPolicy(data.ConcurrencyPolicy),
+			Suspend:                    &data.Suspend,
+			SuccessfulJobsHistoryLimit: data.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     data.FailedJobsHistoryLimit,
+			JobTemplate: batchV1.JobTemplateSpec{
+				ObjectMeta: metaV1.ObjectMeta{
+					Labels: data.Labels,
+				},
+				Spec: spec,
+			},
+		},
+	}
+	if _, err := cli.ClientSet.BatchV1().CronJobs(data.NameSpace).Create(context.TODO(), cronJob, metaV1.CreateOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteCronJob 删除cronjob，其创建的job和pod在后台一起删除
+func (c *cronJob) DeleteCronJob(cli *K8sClient, name, namespace string) error {
+	propagation := metaV1.DeletePropagationBackground
+	return cli.ClientSet.BatchV1().CronJobs(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+func (c *cronJob) UpdateCronJob(cli *K8sClient, content, namespace string, opt kubeDto.DryRunInput) (*Preview, error) {
+	var cronJob = &batchV1.CronJob{}
+	if err := json.Unmarshal([]byte(content), cronJob); err != nil {
+		return nil, err
+	}
+	client := cli.ClientSet.BatchV1().CronJobs(namespace)
+	return updateWithPreview(opt, cronJob, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), cronJob.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), cronJob, opts)
+	})
+}
+
+// SuspendCronJob 暂停cronjob的调度，已经创建的任务不受影响
+func (c *cronJob) SuspendCronJob(cli *K8sClient, name, namespace string) error {
+	return c.setSuspend(cli, name, namespace, true)
+}
+
+// ResumeCronJob 恢复cronjob的调度
+func (c *cronJob) ResumeCronJob(cli *K8sClient, name, namespace string) error {
+	return c.setSuspend(cli, name, namespace, false)
+}
+
+func (c *cronJob) setSuspend(cli *K8sClient, name, namespace string, suspend bool) error {
+	patchByte, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"suspend": suspend,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = cli.ClientSet.BatchV1().CronJobs(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patchByte, metaV1.PatchOptions{})
+	return err
+}
+
+// TriggerCronJob 立即以cronjob的任务模板创建一个job，与kubectl create job --from=cronjob一致，
+// job属于该cronjob，会出现在任务历史中，暂停调度的cronjob也可以手动触发
+func (c *cronJob) TriggerCronJob(cli *K8sClient, name, namespace string) (*batchV1.Job, error) {
+	cronJob, err := cli.ClientSet.BatchV1().CronJobs(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	suffix := fmt.Sprintf("-manual-%d", time.Now().Unix())
+	// job名称会作为pod的标签值，不能超过63个字符
+	prefix := cronJob.Name
+	if len(prefix)+len(suffix) > 63 {
+		prefix = prefix[:63-len(suffix)]
+	}
+	annotations := map[string]string{manualJobAnnotation: "manual"}
+	for k, v := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	job := &batchV1.Job{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:            prefix + suffix,
+			Namespace:       namespace,
+			Labels:          cronJob.Spec.JobTemplate.Labels,
+			Annotations:     annotations,
+			OwnerReferences: []metaV1.OwnerReference{*metaV1.NewControllerRef(cronJob, batchV1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+	return cli.ClientSet.BatchV1().Jobs(namespace).Create(context.TODO(), job, metaV1.CreateOptions{})
+}
+
+// GetCronJobHistory 查询cronjob创建的任务，按创建时间倒序，包含每个任务的pod和容器日志地址；
+// 保留的任务数由cronjob的successfulJobsHistoryLimit和failedJobsHistoryLimit决定
+func (c *cronJob) GetCronJobHistory(cli *K8sClient, name, namespace string) ([]CronJobRun, error) {
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	cronJob, err := cli.ClientSet.BatchV1().CronJobs(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	jobList, err := cli.Informers.Batch().V1().Jobs().Lister().Jobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]CronJobRun, 0)
+	for _, job := range jobList {
+		if !metaV1.IsControlledBy(job, cronJob) {
+			continue
+		}
+		pods, err := jobPods(cli, job)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, CronJobRun{
+			Name:           job.Name,
+			Status:         jobStatus(*job),
+			Manual:         job.Annotations[manualJobAnnotation] == "manual",
+			Completions:    fmt.Sprintf("%d/%d", job.Status.Succeeded, jobCompletions(*job)),
+			Active:         job.Status.Active,
+			Succeeded:      job.Status.Succeeded,
+			Failed:         job.Status.Failed,
+			StartTime:      job.Status.StartTime,
+			CompletionTime: job.Status.CompletionTime,
+			Duration:       jobDuration(*job),
+			Pods:           pods,
+			created:        job.CreationTimestamp,
+		})
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[j].created.Before(&runs[i].created)
+	})
+	return runs, nil
+}
+
+// jobPods 查询job的pod，并生成每个容器的日志地址
+func jobPods(cli *K8sClient, job *batchV1.Job) ([]JobPod, error) {
+	if job.Spec.Selector == nil {
+		return []JobPod{}, nil
+	}
+	selector, err := metaV1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	podList, err := cli.Informers.Core().V1().Pods().Lister().Pods(job.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(podList, func(i, j int) bool {
+		return podList[i].CreationTimestamp.Before(&podList[j].CreationTimestamp)
+	})
+	pods := make([]JobPod, 0, len(podList))
+	for _, pod := range podList {
+		logURLs := make(map[string]string, len(pod.Spec.Containers))
+		for _, container := range pod.Spec.Containers {
+			query := url.Values{}
+			query.Set("cluster", cli.Name)
+			query.Set("namespace", pod.Namespace)
+			query.Set("pod_name", pod.Name)
+			query.Set("container_name", container.Name)
+			logURLs[container.Name] = pkg.PodLogURL + "?" + query.Encode()
+		}
+		pods = append(pods, JobPod{Name: pod.Name, Phase: string(pod.Status.Phase), LogURLs: logURLs})
+	}
+	return pods, nil
+}
+
+// jobDuration 任务的运行时长，运行中的任务计算到当前时间，与kubectl get job的DURATION列一致
+func jobDuration(job batchV1.Job) string {
+	if job.Status.StartTime == nil {
+		return ""
+	}
+	end := time.Now()
+	if job.Status.CompletionTime != nil {
+		end = job.Status.CompletionTime.Time
+	}
+	return duration.HumanDuration(end.Sub(job.Status.StartTime.Time))
+}
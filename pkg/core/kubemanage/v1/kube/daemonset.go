@@ -3,8 +3,11 @@ package kube
 import (
 	"context"
 	"encoding/json"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	appsV1 "k8s.io/api/apps/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var DaemonSet daemonSet
@@ -15,6 +18,8 @@ type daemonSet struct {
 type DaemonSetResp struct {
 	Total int                `json:"total"`
 	Items []appsV1.DaemonSet `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []DaemonSetRow `json:"rows,omitempty"`
 }
 
 type DaemonSetNp struct {
@@ -22,10 +27,10 @@ type DaemonSetNp struct {
 	DaemonSetNum int    `json:"daemonset_num"`
 }
 
-func (d *daemonSet) toCells(daemonsets []appsV1.DaemonSet) []DataCell {
+func (d *daemonSet) toCells(daemonsets []*appsV1.DaemonSet) []DataCell {
 	cells := make([]DataCell, len(daemonsets))
 	for i := range daemonsets {
-		cells[i] = daemonSetCell(daemonsets[i])
+		cells[i] = daemonSetCell(*daemonsets[i])
 	}
 	return cells
 }
@@ -38,50 +43,60 @@ func (d *daemonSet) FromCells(cells []DataCell) []appsV1.DaemonSet {
 	return daemonSets
 }
 
-func (d *daemonSet) GetDaemonSets(filterName, namespace string, limit, page int) (*DaemonSetResp, error) {
-	daemonSetList, err := K8sCli.ClientSet.AppsV1().DaemonSets(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (d *daemonSet) GetDaemonSets(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*DaemonSetResp, error) {
+	query, err := NewDataSelectQuery(in, daemonSetCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	daemonSetList, err := cli.Informers.Apps().V1().DaemonSets().Lister().DaemonSets(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	selectableData := &dataSelector{
-		GenericDataList: d.toCells(daemonSetList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: d.toCells(daemonSetList),
+		DataSelect:      query,
 	}
 	filterd := selectableData.Filter()
 	total := len(filterd.GenericDataList)
 	data := filterd.Sort().Paginate()
 	daemonSets := d.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]DaemonSetRow, len(daemonSets))
+		for i := range daemonSets {
+			rows[i] = toDaemonSetRow(daemonSets[i])
+		}
+		return &DaemonSetResp{Total: total, Rows: rows}, nil
+	}
 	return &DaemonSetResp{
 		Total: total,
 		Items: daemonSets,
 	}, nil
 }
 
-func (d *daemonSet) GetDaemonSetDetail(name, namespace string) (*appsV1.DaemonSet, error) {
-	data, err := K8sCli.ClientSet.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+func (d *daemonSet) GetDaemonSetDetail(cli *K8sClient, name, namespace string) (*appsV1.DaemonSet, error) {
+	data, err := cli.ClientSet.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (d *daemonSet) DeleteDaemonSet(name, namespace string) error {
-	return K8sCli.ClientSet.AppsV1().DaemonSets(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (d *daemonSet) DeleteDaemonSet(cli *K8sClient, name, namespace string) error {
+	return cli.ClientSet.AppsV1().DaemonSets(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
 
-func (d *daemonSet) UpdateDaemonSet(content, namespace string) error {
+func (d *daemonSet) UpdateDaemonSet(cli *K8sClient, content, namespace string, opt kubeDto.DryRunInput) (*Preview, error) {
 	var daemonset = &appsV1.DaemonSet{}
 	if err := json.Unmarshal([]byte(content), daemonset); err != nil {
-		return err
-	}
-	if _, err := K8sCli.ClientSet.AppsV1().DaemonSets(namespace).Update(context.TODO(), daemonset, metaV1.UpdateOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.AppsV1().DaemonSets(namespace)
+	return updateWithPreview(opt, daemonset, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), daemonset.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), daemonset, opts)
+	})
 }
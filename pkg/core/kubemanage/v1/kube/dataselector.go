@@ -1,13 +1,21 @@
 package kube
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 	"time"
 
 	appsV1 "k8s.io/api/apps/v1"
+	autoscalingV2 "k8s.io/api/autoscaling/v2"
+	batchV1 "k8s.io/api/batch/v1"
 	coreV1 "k8s.io/api/core/v1"
 	nwV1 "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 )
 
 // 用于封装排序、过滤、分页方法
@@ -20,16 +28,40 @@ type dataSelector struct {
 type DataCell interface {
 	GetCreation() time.Time
 	GetName() string
+	// GetLabels 获取资源的标签，用于标签选择器过滤
+	GetLabels() map[string]string
+	// GetFields 获取资源可用于字段选择器过滤的字段
+	GetFields() fields.Set
+	// GetStatus 获取资源的派生状态，如pod的Running、NotReady，用于状态过滤
+	GetStatus() []string
+	// GetProperty 获取资源可用于排序的字段值，不支持的字段返回nil
+	GetProperty(name string) ComparableValue
 }
 
-// DataSelectQuery 定义过滤和分页的结构体
+// DataSelectQuery 定义过滤、排序和分页的结构体
 type DataSelectQuery struct {
 	Filter     *FilterQuery
+	Sort       *SortQuery
 	Paginatite *PaginateQuery
 }
 
+// FilterQuery 过滤条件，各条件之间为且的关系，为空的条件不参与过滤
 type FilterQuery struct {
-	Name string
+	Name          string
+	LabelSelector labels.Selector
+	FieldSelector fields.Selector
+	Status        []string
+}
+
+// SortQuery 排序条件，按SortByList的顺序依次比较，前一个字段相等时才比较下一个字段
+type SortQuery struct {
+	SortByList []SortBy
+}
+
+// SortBy 单个排序字段
+type SortBy struct {
+	Property  string
+	Ascending bool
 }
 
 type PaginateQuery struct {
@@ -37,6 +69,99 @@ type PaginateQuery struct {
 	Page  int
 }
 
+// NewDataSelectQuery 根据列表接口的入参构造过滤和分页条件，sample为资源对应的空DataCell，用于校验排序字段
+func NewDataSelectQuery(in *kubeDto.DataSelectInput, sample DataCell) (*DataSelectQuery, error) {
+	filter := &FilterQuery{Name: in.FilterName}
+	if in.LabelSelector != "" {
+		selector, err := labels.Parse(in.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("标签选择器格式有误: %v", err)
+		}
+		filter.LabelSelector = selector
+	}
+	if in.FieldSelector != "" {
+		selector, err := fields.ParseSelector(in.FieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("字段选择器格式有误: %v", err)
+		}
+		filter.FieldSelector = selector
+	}
+	for _, status := range strings.Split(in.Status, ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			filter.Status = append(filter.Status, status)
+		}
+	}
+	sortQuery, err := NewSortQuery(in.SortBy, sample)
+	if err != nil {
+		return nil, err
+	}
+	return &DataSelectQuery{
+		Filter:     filter,
+		Sort:       sortQuery,
+		Paginatite: &PaginateQuery{Limit: in.Limit, Page: in.Page},
+	}, nil
+}
+
+// NewSortQuery 解析排序参数，格式为 字段:方向，多个字段用逗号分隔，如 restarts:desc,name:asc，方向缺省时为asc；
+// 资源不支持的排序字段返回错误，与标签、字段选择器的格式校验保持一致
+func NewSortQuery(sortBy string, sample DataCell) (*SortQuery, error) {
+	query := &SortQuery{}
+	for _, item := range strings.Split(sortBy, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		property, direction := item, "asc"
+		if idx := strings.Index(item, ":"); idx >= 0 {
+			property, direction = item[:idx], strings.ToLower(item[idx+1:])
+		}
+		if property == "" {
+			return nil, fmt.Errorf("排序参数格式有误: %s", item)
+		}
+		if direction != "asc" && direction != "desc" {
+			return nil, fmt.Errorf("排序方向只支持asc或desc: %s", item)
+		}
+		property = strings.ToLower(property)
+		if sample.GetProperty(property) == nil {
+			return nil, fmt.Errorf("不支持的排序字段: %s", property)
+		}
+		query.SortByList = append(query.SortByList, SortBy{
+			Property:  property,
+			Ascending: direction == "asc",
+		})
+	}
+	return query, nil
+}
+
+// Match 判断数据是否满足所有过滤条件
+func (f *FilterQuery) Match(cell DataCell) bool {
+	if f.Name != "" && !strings.Contains(cell.GetName(), f.Name) {
+		return false
+	}
+	if f.LabelSelector != nil && !f.LabelSelector.Matches(labels.Set(cell.GetLabels())) {
+		return false
+	}
+	if f.FieldSelector != nil && !f.FieldSelector.Matches(cell.GetFields()) {
+		return false
+	}
+	if len(f.Status) != 0 && !f.matchStatus(cell.GetStatus()) {
+		return false
+	}
+	return true
+}
+
+// matchStatus 资源的派生状态中包含任意一个过滤状态即为匹配，不区分大小写
+func (f *FilterQuery) matchStatus(status []string) bool {
+	for _, want := range f.Status {
+		for _, s := range status {
+			if strings.EqualFold(want, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 //  实现自定义结构的排序。需要重新len、swap、less方法
 
 // Len 方法用于获取数组的长度
@@ -49,29 +174,40 @@ func (d *dataSelector) Swap(i, j int) {
 	d.GenericDataList[i], d.GenericDataList[j] = d.GenericDataList[j], d.GenericDataList[i]
 }
 
-// Less 比较大小
+// Less 比较大小，依次按排序字段比较，全部相等时按创建时间倒序
 func (d *dataSelector) Less(i, j int) bool {
+	if d.DataSelect.Sort != nil {
+		for _, sortBy := range d.DataSelect.Sort.SortByList {
+			a := d.GenericDataList[i].GetProperty(sortBy.Property)
+			b := d.GenericDataList[j].GetProperty(sortBy.Property)
+			if a == nil || b == nil {
+				continue
+			}
+			cmp := a.Compare(b)
+			if cmp == 0 {
+				continue
+			}
+			if sortBy.Ascending {
+				return cmp < 0
+			}
+			return cmp > 0
+		}
+	}
 	a := d.GenericDataList[i].GetCreation()
 	b := d.GenericDataList[j].GetCreation()
 	return b.Before(a)
 }
 
 func (d *dataSelector) Sort() *dataSelector {
-	sort.Sort(d)
+	sort.Stable(d)
 	return d
 }
 
-// Filter 用于过滤数据，比较数据的name属性，若包含，则返回
+// Filter 用于过滤数据，名称、标签选择器、字段选择器、状态全部匹配的数据才会返回
 func (d *dataSelector) Filter() *dataSelector {
-	//判断是否为空，若为空，则返回所有数据
-	if d.DataSelect.Filter.Name == "" {
-		return d
-	}
-	//若不为空，则按照入参name进行过滤,若name包含，则吧数据放进新数组中返回出去
 	var filtered []DataCell
 	for _, v := range d.GenericDataList {
-		objName := v.GetName()
-		if !strings.Contains(objName, d.DataSelect.Filter.Name) {
+		if !d.DataSelect.Filter.Match(v) {
 			continue
 		}
 		filtered = append(filtered, v)
@@ -115,6 +251,67 @@ func (p podCell) GetName() string {
 	return p.Name
 }
 
+func (p podCell) GetLabels() map[string]string {
+	return p.Labels
+}
+
+func (p podCell) GetFields() fields.Set {
+	return mergeFields(objectMetaFields(p.ObjectMeta), fields.Set{
+		"spec.nodeName":           p.Spec.NodeName,
+		"spec.restartPolicy":      string(p.Spec.RestartPolicy),
+		"spec.schedulerName":      p.Spec.SchedulerName,
+		"spec.serviceAccountName": p.Spec.ServiceAccountName,
+		"status.phase":            string(p.Status.Phase),
+		"status.podIP":            p.Status.PodIP,
+		"status.hostIP":           p.Status.HostIP,
+	})
+}
+
+// GetStatus pod的状态包括phase、Ready/NotReady、Terminating以及容器的等待或终止原因，如CrashLoopBackOff、OOMKilled
+func (p podCell) GetStatus() []string {
+	status := []string{string(p.Status.Phase)}
+	ready := "NotReady"
+	for _, cond := range p.Status.Conditions {
+		if cond.Type == coreV1.PodReady && cond.Status == coreV1.ConditionTrue {
+			ready = "Ready"
+		}
+	}
+	status = append(status, ready)
+	if p.DeletionTimestamp != nil {
+		status = append(status, "Terminating")
+	}
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			status = append(status, cs.State.Waiting.Reason)
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.Reason != "" {
+			status = append(status, cs.State.Terminated.Reason)
+		}
+	}
+	return status
+}
+
+// GetProperty pod支持按restarts、node、status、ip、cpu(requests)、memory(requests)排序
+func (p podCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "restarts":
+		return StdComparableInt(podRestarts(coreV1.Pod(p)))
+	case "node":
+		return StdComparableString(p.Spec.NodeName)
+	case "status":
+		return StdComparableString(p.Status.Phase)
+	case "ip":
+		return StdComparableString(p.Status.PodIP)
+	case "cpu":
+		cpu, _ := podRequests(coreV1.Pod(p))
+		return StdComparableInt(cpu)
+	case "memory":
+		_, memory := podRequests(coreV1.Pod(p))
+		return StdComparableInt(memory)
+	}
+	return objectMetaProperty(p.ObjectMeta, name)
+}
+
 type deploymentCell appsV1.Deployment
 
 func (d deploymentCell) GetCreation() time.Time {
@@ -125,6 +322,37 @@ func (d deploymentCell) GetName() string {
 	return d.Name
 }
 
+func (d deploymentCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d deploymentCell) GetFields() fields.Set {
+	return objectMetaFields(d.ObjectMeta)
+}
+
+// GetStatus 存在不可用副本时为Unavailable，否则为Available，暂停发布时附加Paused
+func (d deploymentCell) GetStatus() []string {
+	status := []string{"Available"}
+	if d.Status.UnavailableReplicas > 0 {
+		status[0] = "Unavailable"
+	}
+	if d.Spec.Paused {
+		status = append(status, "Paused")
+	}
+	return status
+}
+
+// GetProperty deployment支持按ready(就绪比例)、replicas排序
+func (d deploymentCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "ready":
+		return readyRatio(d.Status.ReadyReplicas, d.Status.Replicas)
+	case "replicas":
+		return StdComparableInt(d.Status.Replicas)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type daemonSetCell appsV1.DaemonSet
 
 func (d daemonSetCell) GetCreation() time.Time {
@@ -135,6 +363,33 @@ func (d daemonSetCell) GetName() string {
 	return d.Name
 }
 
+func (d daemonSetCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d daemonSetCell) GetFields() fields.Set {
+	return objectMetaFields(d.ObjectMeta)
+}
+
+// GetStatus 所有调度的pod均就绪时为Available，否则为Unavailable
+func (d daemonSetCell) GetStatus() []string {
+	if d.Status.NumberUnavailable > 0 || d.Status.NumberReady < d.Status.DesiredNumberScheduled {
+		return []string{"Unavailable"}
+	}
+	return []string{"Available"}
+}
+
+// GetProperty daemonset支持按ready(就绪比例)、replicas排序
+func (d daemonSetCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "ready":
+		return readyRatio(d.Status.NumberReady, d.Status.DesiredNumberScheduled)
+	case "replicas":
+		return StdComparableInt(d.Status.DesiredNumberScheduled)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type statefulSetCell appsV1.StatefulSet
 
 func (d statefulSetCell) GetCreation() time.Time {
@@ -145,6 +400,153 @@ func (d statefulSetCell) GetName() string {
 	return d.Name
 }
 
+func (d statefulSetCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d statefulSetCell) GetFields() fields.Set {
+	return objectMetaFields(d.ObjectMeta)
+}
+
+// GetStatus 就绪副本数达到期望副本数时为Available，否则为Unavailable
+func (d statefulSetCell) GetStatus() []string {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	if d.Status.ReadyReplicas < replicas {
+		return []string{"Unavailable"}
+	}
+	return []string{"Available"}
+}
+
+// GetProperty statefulset支持按ready(就绪比例)、replicas排序
+func (d statefulSetCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "ready":
+		return readyRatio(d.Status.ReadyReplicas, d.Status.Replicas)
+	case "replicas":
+		return StdComparableInt(d.Status.Replicas)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
+type jobCell batchV1.Job
+
+func (d jobCell) GetCreation() time.Time {
+	return d.CreationTimestamp.Time
+}
+
+func (d jobCell) GetName() string {
+	return d.Name
+}
+
+func (d jobCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d jobCell) GetFields() fields.Set {
+	return objectMetaFields(d.ObjectMeta)
+}
+
+// GetStatus job的状态为Running、Succeeded、Failed、Suspended
+func (d jobCell) GetStatus() []string {
+	return []string{jobStatus(batchV1.Job(d))}
+}
+
+// GetProperty job支持按status、succeeded(成功的pod数)排序
+func (d jobCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "status":
+		return StdComparableString(jobStatus(batchV1.Job(d)))
+	case "succeeded":
+		return StdComparableInt(d.Status.Succeeded)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
+type cronJobCell batchV1.CronJob
+
+func (d cronJobCell) GetCreation() time.Time {
+	return d.CreationTimestamp.Time
+}
+
+func (d cronJobCell) GetName() string {
+	return d.Name
+}
+
+func (d cronJobCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d cronJobCell) GetFields() fields.Set {
+	return mergeFields(objectMetaFields(d.ObjectMeta), fields.Set{
+		"spec.schedule": d.Spec.Schedule,
+	})
+}
+
+// GetStatus 暂停调度时为Suspended，否则为Scheduled
+func (d cronJobCell) GetStatus() []string {
+	if d.Spec.Suspend != nil && *d.Spec.Suspend {
+		return []string{"Suspended"}
+	}
+	return []string{"Scheduled"}
+}
+
+// GetProperty cronjob支持按active(运行中的任务数)、last_schedule(最近调度时间)排序
+func (d cronJobCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "active":
+		return StdComparableInt(len(d.Status.Active))
+	case "last_schedule":
+		if d.Status.LastScheduleTime == nil {
+			return StdComparableTime(time.Time{})
+		}
+		return StdComparableTime(d.Status.LastScheduleTime.Time)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
+type hpaCell autoscalingV2.HorizontalPodAutoscaler
+
+func (d hpaCell) GetCreation() time.Time {
+	return d.CreationTimestamp.Time
+}
+
+func (d hpaCell) GetName() string {
+	return d.Name
+}
+
+func (d hpaCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d hpaCell) GetFields() fields.Set {
+	return mergeFields(objectMetaFields(d.ObjectMeta), fields.Set{
+		"spec.scaleTargetRef.kind": d.Spec.ScaleTargetRef.Kind,
+		"spec.scaleTargetRef.name": d.Spec.ScaleTargetRef.Name,
+	})
+}
+
+// GetStatus 副本数达到最大值时为MaxReplicas，否则为Scaling
+func (d hpaCell) GetStatus() []string {
+	if d.Status.CurrentReplicas >= d.Spec.MaxReplicas {
+		return []string{"MaxReplicas"}
+	}
+	return []string{"Scaling"}
+}
+
+// GetProperty hpa支持按replicas(当前副本数)、max_replicas(最大副本数)排序
+func (d hpaCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "replicas":
+		return StdComparableInt(d.Status.CurrentReplicas)
+	case "max_replicas":
+		return StdComparableInt(d.Spec.MaxReplicas)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type nodeCell coreV1.Node
 
 func (d nodeCell) GetCreation() time.Time {
@@ -155,6 +557,43 @@ func (d nodeCell) GetName() string {
 	return d.Name
 }
 
+func (d nodeCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d nodeCell) GetFields() fields.Set {
+	return mergeFields(objectMetaFields(d.ObjectMeta), fields.Set{
+		"spec.unschedulable": fmt.Sprint(d.Spec.Unschedulable),
+	})
+}
+
+// GetStatus 节点的状态包括Ready/NotReady，禁止调度时附加SchedulingDisabled
+func (d nodeCell) GetStatus() []string {
+	status := []string{"NotReady"}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == coreV1.NodeReady && cond.Status == coreV1.ConditionTrue {
+			status[0] = "Ready"
+		}
+	}
+	if d.Spec.Unschedulable {
+		status = append(status, "SchedulingDisabled")
+	}
+	return status
+}
+
+// GetProperty node支持按status、cpu(allocatable)、memory(allocatable)排序
+func (d nodeCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "status":
+		return StdComparableString(d.GetStatus()[0])
+	case "cpu":
+		return StdComparableInt(d.Status.Allocatable.Cpu().MilliValue())
+	case "memory":
+		return StdComparableInt(d.Status.Allocatable.Memory().Value())
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type namespaceCell coreV1.Namespace
 
 func (d namespaceCell) GetCreation() time.Time {
@@ -165,6 +604,27 @@ func (d namespaceCell) GetName() string {
 	return d.Name
 }
 
+func (d namespaceCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d namespaceCell) GetFields() fields.Set {
+	return mergeFields(objectMetaFields(d.ObjectMeta), fields.Set{
+		"status.phase": string(d.Status.Phase),
+	})
+}
+
+func (d namespaceCell) GetStatus() []string {
+	return []string{string(d.Status.Phase)}
+}
+
+func (d namespaceCell) GetProperty(name string) ComparableValue {
+	if name == "status" {
+		return StdComparableString(d.Status.Phase)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type persistentvolumesCell coreV1.PersistentVolume
 
 func (d persistentvolumesCell) GetCreation() time.Time {
@@ -175,6 +635,32 @@ func (d persistentvolumesCell) GetName() string {
 	return d.Name
 }
 
+func (d persistentvolumesCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d persistentvolumesCell) GetFields() fields.Set {
+	return mergeFields(objectMetaFields(d.ObjectMeta), fields.Set{
+		"spec.storageClassName": d.Spec.StorageClassName,
+		"status.phase":          string(d.Status.Phase),
+	})
+}
+
+func (d persistentvolumesCell) GetStatus() []string {
+	return []string{string(d.Status.Phase)}
+}
+
+// GetProperty pv支持按status、capacity排序
+func (d persistentvolumesCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "status":
+		return StdComparableString(d.Status.Phase)
+	case "capacity":
+		return StdComparableInt(d.Spec.Capacity.Storage().Value())
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type serviceCell coreV1.Service
 
 func (d serviceCell) GetCreation() time.Time {
@@ -185,6 +671,29 @@ func (d serviceCell) GetName() string {
 	return d.Name
 }
 
+func (d serviceCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d serviceCell) GetFields() fields.Set {
+	return mergeFields(objectMetaFields(d.ObjectMeta), fields.Set{
+		"spec.type":      string(d.Spec.Type),
+		"spec.clusterIP": d.Spec.ClusterIP,
+	})
+}
+
+// GetStatus service以类型作为状态，如ClusterIP、NodePort、LoadBalancer
+func (d serviceCell) GetStatus() []string {
+	return []string{string(d.Spec.Type)}
+}
+
+func (d serviceCell) GetProperty(name string) ComparableValue {
+	if name == "type" {
+		return StdComparableString(d.Spec.Type)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type ingressCell nwV1.Ingress
 
 func (d ingressCell) GetCreation() time.Time {
@@ -195,6 +704,22 @@ func (d ingressCell) GetName() string {
 	return d.Name
 }
 
+func (d ingressCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d ingressCell) GetFields() fields.Set {
+	return objectMetaFields(d.ObjectMeta)
+}
+
+func (d ingressCell) GetStatus() []string {
+	return nil
+}
+
+func (d ingressCell) GetProperty(name string) ComparableValue {
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type configmapCell coreV1.ConfigMap
 
 func (d configmapCell) GetCreation() time.Time {
@@ -205,6 +730,22 @@ func (d configmapCell) GetName() string {
 	return d.Name
 }
 
+func (d configmapCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d configmapCell) GetFields() fields.Set {
+	return objectMetaFields(d.ObjectMeta)
+}
+
+func (d configmapCell) GetStatus() []string {
+	return nil
+}
+
+func (d configmapCell) GetProperty(name string) ComparableValue {
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type persistentVolumeClaimCell coreV1.PersistentVolumeClaim
 
 func (d persistentVolumeClaimCell) GetCreation() time.Time {
@@ -215,6 +756,37 @@ func (d persistentVolumeClaimCell) GetName() string {
 	return d.Name
 }
 
+func (d persistentVolumeClaimCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d persistentVolumeClaimCell) GetFields() fields.Set {
+	storageClassName := ""
+	if d.Spec.StorageClassName != nil {
+		storageClassName = *d.Spec.StorageClassName
+	}
+	return mergeFields(objectMetaFields(d.ObjectMeta), fields.Set{
+		"spec.storageClassName": storageClassName,
+		"spec.volumeName":       d.Spec.VolumeName,
+		"status.phase":          string(d.Status.Phase),
+	})
+}
+
+func (d persistentVolumeClaimCell) GetStatus() []string {
+	return []string{string(d.Status.Phase)}
+}
+
+// GetProperty pvc支持按status、capacity排序
+func (d persistentVolumeClaimCell) GetProperty(name string) ComparableValue {
+	switch name {
+	case "status":
+		return StdComparableString(d.Status.Phase)
+	case "capacity":
+		return StdComparableInt(d.Status.Capacity.Storage().Value())
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
 type secretCell coreV1.Secret
 
 func (d secretCell) GetCreation() time.Time {
@@ -224,3 +796,59 @@ func (d secretCell) GetCreation() time.Time {
 func (d secretCell) GetName() string {
 	return d.Name
 }
+
+func (d secretCell) GetLabels() map[string]string {
+	return d.Labels
+}
+
+func (d secretCell) GetFields() fields.Set {
+	return mergeFields(objectMetaFields(d.ObjectMeta), fields.Set{
+		"type": string(d.Type),
+	})
+}
+
+// GetStatus secret以类型作为状态，如Opaque、kubernetes.io/tls
+func (d secretCell) GetStatus() []string {
+	return []string{string(d.Type)}
+}
+
+func (d secretCell) GetProperty(name string) ComparableValue {
+	if name == "type" {
+		return StdComparableString(d.Type)
+	}
+	return objectMetaProperty(d.ObjectMeta, name)
+}
+
+// podRestarts 计算pod中所有容器的重启次数之和
+func podRestarts(pod coreV1.Pod) int64 {
+	var restarts int64
+	for _, cs := range pod.Status.ContainerStatuses {
+		restarts += int64(cs.RestartCount)
+	}
+	return restarts
+}
+
+// podRequests 计算pod中所有容器的cpu(毫核)和内存(字节)请求之和
+func podRequests(pod coreV1.Pod) (cpu, memory int64) {
+	for _, c := range pod.Spec.Containers {
+		cpu += c.Resources.Requests.Cpu().MilliValue()
+		memory += c.Resources.Requests.Memory().Value()
+	}
+	return cpu, memory
+}
+
+// objectMetaFields 所有资源通用的字段选择器字段
+func objectMetaFields(meta metaV1.ObjectMeta) fields.Set {
+	return fields.Set{
+		"metadata.name":      meta.Name,
+		"metadata.namespace": meta.Namespace,
+	}
+}
+
+// mergeFields 将资源特有的字段合并到通用字段中
+func mergeFields(set fields.Set, extra fields.Set) fields.Set {
+	for k, v := range extra {
+		set[k] = v
+	}
+	return set
+}
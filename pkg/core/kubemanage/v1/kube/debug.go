@@ -0,0 +1,133 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/noovertime7/kubemanage/cmd/app/config"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+const (
+	// defaultDebugImage 配置文件未设置debugImage时使用的调试镜像
+	defaultDebugImage = "busybox:1.36"
+	// debugContainerTimeout 等待调试容器启动的最长时间
+	debugContainerTimeout = 60 * time.Second
+)
+
+// DebugContainer 注入的临时调试容器，State 取值为 running、waiting
+type DebugContainer struct {
+	Name            string `json:"name"`
+	Image           string `json:"image"`
+	TargetContainer string `json:"target_container,omitempty"`
+	State           string `json:"state"`
+	Message         string `json:"message,omitempty"`
+}
+
+// DefaultDebugImage 获取配置文件中的debugImage，未配置时返回busybox
+func DefaultDebugImage() string {
+	if config.SysConfig != nil && config.SysConfig.Default.DebugImage != "" {
+		return config.SysConfig.Default.DebugImage
+	}
+	return defaultDebugImage
+}
+
+// DebugPod 通过ephemeralcontainers子资源向运行中的pod注入临时调试容器，并等待其启动；
+// 调试容器开启了stdin和tty，web终端指定该容器时通过attach连接，退出shell后容器随之结束且不能重启
+func (p *pod) DebugPod(cli *K8sClient, in *kubeDto.PodDebugInput) (*DebugContainer, error) {
+	pod, err := cli.ClientSet.CoreV1().Pods(in.NameSpace).Get(context.TODO(), in.PodName, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pod.Status.Phase != coreV1.PodRunning {
+		return nil, fmt.Errorf("pod %s 未处于运行状态，当前状态为 %s", in.PodName, pod.Status.Phase)
+	}
+	if in.TargetContainer != "" && !hasContainer(pod, in.TargetContainer) {
+		return nil, fmt.Errorf("pod %s 中不存在容器 %s", in.PodName, in.TargetContainer)
+	}
+
+	debug := &DebugContainer{
+		Name:            fmt.Sprintf("debugger-%s", utilrand.String(5)),
+		Image:           in.Image,
+		TargetContainer: in.TargetContainer,
+	}
+	if debug.Image == "" {
+		debug.Image = DefaultDebugImage()
+	}
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, coreV1.EphemeralContainer{
+		EphemeralContainerCommon: coreV1.EphemeralContainerCommon{
+			Name:                     debug.Name,
+			Image:                    debug.Image,
+			ImagePullPolicy:          coreV1.PullIfNotPresent,
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: coreV1.TerminationMessageFallbackToLogsOnError,
+		},
+		TargetContainerName: in.TargetContainer,
+	})
+	if _, err := cli.ClientSet.CoreV1().Pods(in.NameSpace).UpdateEphemeralContainers(context.TODO(), in.PodName, pod, metaV1.UpdateOptions{}); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return nil, fmt.Errorf("集群不支持临时容器，需要kubernetes 1.23及以上版本: %v", err)
+		}
+		return nil, err
+	}
+
+	// 镜像拉取失败或容器退出时立即返回错误，超时仍未启动时返回waiting状态，由前端稍后重试连接
+	debug.State = "waiting"
+	err = wait.PollImmediate(time.Second, debugContainerTimeout, func() (bool, error) {
+		pod, err := cli.ClientSet.CoreV1().Pods(in.NameSpace).Get(context.TODO(), in.PodName, metaV1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name != debug.Name {
+				continue
+			}
+			switch {
+			case status.State.Running != nil:
+				debug.State = "running"
+				return true, nil
+			case status.State.Terminated != nil:
+				return false, fmt.Errorf("调试容器已退出: %s %s", status.State.Terminated.Reason, status.State.Terminated.Message)
+			case status.State.Waiting != nil:
+				switch status.State.Waiting.Reason {
+				case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+					return false, fmt.Errorf("调试容器镜像 %s 拉取失败: %s", debug.Image, status.State.Waiting.Message)
+				}
+				debug.Message = status.State.Waiting.Message
+			}
+		}
+		return false, nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		return nil, err
+	}
+	return debug, nil
+}
+
+// hasContainer pod的普通容器中是否存在指定名称的容器
+func hasContainer(pod *coreV1.Pod, name string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEphemeralContainer 指定名称的容器是否为临时容器
+func isEphemeralContainer(pod *coreV1.Pod, name string) bool {
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
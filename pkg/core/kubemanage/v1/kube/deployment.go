@@ -3,6 +3,8 @@ package kube
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -10,7 +12,14 @@ import (
 	coreV1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
 
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
 )
@@ -24,6 +33,8 @@ type deployment struct{}
 type DeploymentResp struct {
 	Total int                 `json:"total"`
 	Items []appsV1.Deployment `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []DeploymentRow `json:"rows,omitempty"`
 }
 
 type DeployNp struct {
@@ -31,10 +42,10 @@ type DeployNp struct {
 	DeployNum int    `json:"deployment_num"`
 }
 
-func (d *deployment) toCells(deployments []appsV1.Deployment) []DataCell {
+func (d *deployment) toCells(deployments []*appsV1.Deployment) []DataCell {
 	cells := make([]DataCell, len(deployments))
 	for i := range deployments {
-		cells[i] = deploymentCell(deployments[i])
+		cells[i] = deploymentCell(*deployments[i])
 	}
 	return cells
 }
@@ -47,25 +58,33 @@ func (d *deployment) FromCells(cells []DataCell) []appsV1.Deployment {
 	return deployments
 }
 
-func (d *deployment) GetDeployments(filterName, namespace string, limit, page int) (*DeploymentResp, error) {
-	deploymentList, err := K8sCli.ClientSet.AppsV1().Deployments(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (d *deployment) GetDeployments(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*DeploymentResp, error) {
+	query, err := NewDataSelectQuery(in, deploymentCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	deploymentList, err := cli.Informers.Apps().V1().Deployments().Lister().Deployments(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	selectableData := &dataSelector{
-		GenericDataList: d.toCells(deploymentList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: d.toCells(deploymentList),
+		DataSelect:      query,
 	}
 	filterd := selectableData.Filter()
 	total := len(filterd.GenericDataList)
 	data := filterd.Sort().Paginate()
 	deployments := d.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]DeploymentRow, len(deployments))
+		for i := range deployments {
+			rows[i] = toDeploymentRow(deployments[i])
+		}
+		return &DeploymentResp{Total: total, Rows: rows}, nil
+	}
 	return &DeploymentResp{
 		Total: total,
 		Items: deployments,
@@ -73,8 +92,8 @@ func (d *deployment) GetDeployments(filterName, namespace string, limit, page in
 }
 
 // GetDeploymentDetail 获取deployment详情
-func (d *deployment) GetDeploymentDetail(deployName, namespace string) (*appsV1.Deployment, error) {
-	deploy, err := K8sCli.ClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), deployName, metaV1.GetOptions{})
+func (d *deployment) GetDeploymentDetail(cli *K8sClient, deployName, namespace string) (*appsV1.Deployment, error) {
+	deploy, err := cli.ClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), deployName, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -82,15 +101,15 @@ func (d *deployment) GetDeploymentDetail(deployName, namespace string) (*appsV1.
 }
 
 // ScaleDeployment 设置deployment副本数
-func (d *deployment) ScaleDeployment(deployName, namespace string, scaleNum int) (int32, error) {
-	scale, err := K8sCli.ClientSet.AppsV1().Deployments(namespace).GetScale(context.TODO(), deployName, metaV1.GetOptions{})
+func (d *deployment) ScaleDeployment(cli *K8sClient, deployName, namespace string, scaleNum int) (int32, error) {
+	scale, err := cli.ClientSet.AppsV1().Deployments(namespace).GetScale(context.TODO(), deployName, metaV1.GetOptions{})
 	if err != nil {
 		return 0, err
 	}
 	//修改副本数
 	scale.Spec.Replicas = int32(scaleNum)
 	//更新副本数，传入scale对象
-	newScale, err := K8sCli.ClientSet.AppsV1().Deployments(namespace).UpdateScale(context.TODO(), deployName, scale, metaV1.UpdateOptions{})
+	newScale, err := cli.ClientSet.AppsV1().Deployments(namespace).UpdateScale(context.TODO(), deployName, scale, metaV1.UpdateOptions{})
 	if err != nil {
 		return 0, err
 	}
@@ -98,7 +117,7 @@ func (d *deployment) ScaleDeployment(deployName, namespace string, scaleNum int)
 }
 
 // CreateDeployment 新增deployment,接收deployCreate的对象
-func (d *deployment) CreateDeployment(data *kubeDto.DeployCreateInput) error {
+func (d *deployment) CreateDeployment(cli *K8sClient, data *kubeDto.DeployCreateInput) error {
 	//初始化appsV1.deployment类型的对象
 	deployment := &appsV1.Deployment{
 		ObjectMeta: metaV1.ObjectMeta{
@@ -183,30 +202,32 @@ func (d *deployment) CreateDeployment(data *kubeDto.DeployCreateInput) error {
 		coreV1.ResourceMemory: resource.MustParse(data.Memory),
 	}
 	//调用sdk去更新deployment
-	if _, err := K8sCli.ClientSet.AppsV1().Deployments(data.NameSpace).Create(context.TODO(), deployment, metaV1.CreateOptions{}); err != nil {
+	if _, err := cli.ClientSet.AppsV1().Deployments(data.NameSpace).Create(context.TODO(), deployment, metaV1.CreateOptions{}); err != nil {
 		return err
 	}
 	return nil
 }
 
 // DeleteDeployment 删除deployment
-func (d *deployment) DeleteDeployment(deployName, namespace string) error {
-	return K8sCli.ClientSet.AppsV1().Deployments(namespace).Delete(context.TODO(), deployName, metaV1.DeleteOptions{})
+func (d *deployment) DeleteDeployment(cli *K8sClient, deployName, namespace string) error {
+	return cli.ClientSet.AppsV1().Deployments(namespace).Delete(context.TODO(), deployName, metaV1.DeleteOptions{})
 }
 
-// UpdateDeployment 更新deployment
-func (d *deployment) UpdateDeployment(namespace, content string) error {
+// UpdateDeployment 更新deployment，预览模式下只返回变更差异
+func (d *deployment) UpdateDeployment(cli *K8sClient, namespace, content string, opt kubeDto.DryRunInput) (*Preview, error) {
 	var deploy = &appsV1.Deployment{}
 	if err := json.Unmarshal([]byte(content), deploy); err != nil {
-		return err
-	}
-	if _, err := K8sCli.ClientSet.AppsV1().Deployments(namespace).Update(context.TODO(), deploy, metaV1.UpdateOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.AppsV1().Deployments(namespace)
+	return updateWithPreview(opt, deploy, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), deploy.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), deploy, opts)
+	})
 }
 
-func (d *deployment) RestartDeployment(deployName, namespace string) error {
+func (d *deployment) RestartDeployment(cli *K8sClient, deployName, namespace string) error {
 	//随便改一个无关的值,就会触发重启
 	patchData := map[string]interface{}{
 		"spec": map[string]interface{}{
@@ -231,30 +252,301 @@ func (d *deployment) RestartDeployment(deployName, namespace string) error {
 		return err
 	}
 	//调用patch方法更新deployment
-	if _, err := K8sCli.ClientSet.AppsV1().Deployments(namespace).Patch(context.TODO(), deployName, "application/strategic-merge-patch+json", patchByte, metaV1.PatchOptions{}); err != nil {
+	if _, err := cli.ClientSet.AppsV1().Deployments(namespace).Patch(context.TODO(), deployName, "application/strategic-merge-patch+json", patchByte, metaV1.PatchOptions{}); err != nil {
 		return err
 	}
 	return nil
 }
 
 // GetDeployNumPerNS 获取每个namespace下的deploy数量
-func (d *deployment) GetDeployNumPerNS() ([]*DeployNp, error) {
-	namespaceList, err := K8sCli.ClientSet.CoreV1().Namespaces().List(context.TODO(), metaV1.ListOptions{})
+func (d *deployment) GetDeployNumPerNS(cli *K8sClient) ([]*DeployNp, error) {
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	namespaceList, err := cli.Informers.Core().V1().Namespaces().Lister().List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	var deploys []*DeployNp
-	for _, namespace := range namespaceList.Items {
-		deployList, err := K8sCli.ClientSet.AppsV1().Deployments(namespace.Name).List(context.TODO(), metaV1.ListOptions{})
+	for _, namespace := range namespaceList {
+		deployList, err := cli.Informers.Apps().V1().Deployments().Lister().Deployments(namespace.Name).List(labels.Everything())
 		if err != nil {
 			return nil, err
 		}
 		//组装数据
 		deployNp := &DeployNp{
 			NameSpace: namespace.Name,
-			DeployNum: len(deployList.Items),
+			DeployNum: len(deployList),
 		}
 		deploys = append(deploys, deployNp)
 	}
 	return deploys, nil
 }
+
+const (
+	// RevisionAnnotation deployment controller记录在deployment和replicaSet上的版本号
+	RevisionAnnotation = "deployment.kubernetes.io/revision"
+	// ChangeCauseAnnotation 记录变更原因，与kubectl rollout history展示的CHANGE-CAUSE一致
+	ChangeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// DeploymentRevision deployment的一个历史版本，对应一个replicaSet
+type DeploymentRevision struct {
+	Revision     int64       `json:"revision"`
+	ReplicaSet   string      `json:"replica_set"`
+	ChangeCause  string      `json:"change_cause"`
+	Images       []string    `json:"images"`
+	Replicas     int32       `json:"replicas"`
+	CreationTime metaV1.Time `json:"creation_time"`
+	// Current 是否为当前正在使用的版本
+	Current bool `json:"current"`
+}
+
+// GetDeploymentHistory 获取deployment的发布历史，按版本号倒序
+func (d *deployment) GetDeploymentHistory(cli *K8sClient, deployName, namespace string) ([]DeploymentRevision, error) {
+	deploy, err := cli.ClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), deployName, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	rsList, err := d.replicaSets(cli, deploy)
+	if err != nil {
+		return nil, err
+	}
+	current := revisionOf(deploy)
+	revisions := make([]DeploymentRevision, 0, len(rsList))
+	for _, rs := range rsList {
+		images := make([]string, 0, len(rs.Spec.Template.Spec.Containers))
+		for _, c := range rs.Spec.Template.Spec.Containers {
+			images = append(images, c.Image)
+		}
+		revision := revisionOf(&rs)
+		revisions = append(revisions, DeploymentRevision{
+			Revision:     revision,
+			ReplicaSet:   rs.Name,
+			ChangeCause:  rs.Annotations[ChangeCauseAnnotation],
+			Images:       images,
+			Replicas:     rs.Status.Replicas,
+			CreationTime: rs.CreationTimestamp,
+			Current:      revision == current,
+		})
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// RollbackDeployment 将deployment回滚到指定版本，revision为0时回滚到上一个版本，返回回滚到的版本号
+func (d *deployment) RollbackDeployment(cli *K8sClient, deployName, namespace string, revision int64) (int64, error) {
+	deploy, err := cli.ClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), deployName, metaV1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	if deploy.Spec.Paused {
+		return 0, fmt.Errorf("deployment %s 已暂停，请恢复后再回滚", deployName)
+	}
+	rsList, err := d.replicaSets(cli, deploy)
+	if err != nil {
+		return 0, err
+	}
+	target, err := findRevision(rsList, revisionOf(deploy), revision)
+	if err != nil {
+		return 0, err
+	}
+
+	// 使用目标replicaSet的pod模板替换deployment的模板，去掉replicaSet特有的pod-template-hash标签
+	template := target.Spec.Template.DeepCopy()
+	delete(template.Labels, appsV1.DefaultDeploymentUniqueLabelKey)
+	annotations := map[string]string{}
+	for k, v := range deploy.Annotations {
+		annotations[k] = v
+	}
+	if cause, ok := target.Annotations[ChangeCauseAnnotation]; ok {
+		annotations[ChangeCauseAnnotation] = cause
+	} else {
+		delete(annotations, ChangeCauseAnnotation)
+	}
+	patch := []map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+		{"op": "replace", "path": "/metadata/annotations", "value": annotations},
+	}
+	patchByte, err := json.Marshal(patch)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := cli.ClientSet.AppsV1().Deployments(namespace).Patch(context.TODO(), deployName, types.JSONPatchType, patchByte, metaV1.PatchOptions{}); err != nil {
+		return 0, err
+	}
+	return revisionOf(target), nil
+}
+
+// replicaSets 获取属于deployment的所有replicaSet
+func (d *deployment) replicaSets(cli *K8sClient, deploy *appsV1.Deployment) ([]appsV1.ReplicaSet, error) {
+	selector, err := metaV1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	rsList, err := cli.ClientSet.AppsV1().ReplicaSets(deploy.Namespace).List(context.TODO(), metaV1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var owned []appsV1.ReplicaSet
+	for _, rs := range rsList.Items {
+		if ref := metaV1.GetControllerOf(&rs); ref != nil && ref.UID == deploy.UID {
+			owned = append(owned, rs)
+		}
+	}
+	return owned, nil
+}
+
+// findRevision 查找指定版本的replicaSet，revision为0时查找当前版本之前的最新版本
+func findRevision(rsList []appsV1.ReplicaSet, current, revision int64) (*appsV1.ReplicaSet, error) {
+	var target *appsV1.ReplicaSet
+	for i := range rsList {
+		r := revisionOf(&rsList[i])
+		if revision != 0 && r == revision {
+			return &rsList[i], nil
+		}
+		if revision == 0 && r < current && (target == nil || r > revisionOf(target)) {
+			target = &rsList[i]
+		}
+	}
+	if target == nil {
+		if revision == 0 {
+			return nil, fmt.Errorf("没有可回滚的历史版本")
+		}
+		return nil, fmt.Errorf("版本 %d 不存在", revision)
+	}
+	return target, nil
+}
+
+// revisionOf 从注解中解析版本号，解析失败时返回0
+func revisionOf(obj metaV1.Object) int64 {
+	revision, _ := strconv.ParseInt(obj.GetAnnotations()[RevisionAnnotation], 10, 64)
+	return revision
+}
+
+// PauseDeployment 暂停deployment的发布，暂停期间对模板的修改不会触发滚动更新
+func (d *deployment) PauseDeployment(cli *K8sClient, deployName, namespace string) error {
+	return d.setPaused(cli, deployName, namespace, true)
+}
+
+// ResumeDeployment 恢复deployment的发布
+func (d *deployment) ResumeDeployment(cli *K8sClient, deployName, namespace string) error {
+	return d.setPaused(cli, deployName, namespace, false)
+}
+
+func (d *deployment) setPaused(cli *K8sClient, deployName, namespace string, paused bool) error {
+	patchByte, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"paused": paused,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = cli.ClientSet.AppsV1().Deployments(namespace).Patch(context.TODO(), deployName, types.StrategicMergePatchType, patchByte, metaV1.PatchOptions{})
+	return err
+}
+
+// RolloutStatus deployment的发布进度，与kubectl rollout status的判断逻辑一致
+type RolloutStatus struct {
+	Revision  int64 `json:"revision"`
+	Desired   int32 `json:"desired"`
+	Updated   int32 `json:"updated"`
+	Ready     int32 `json:"ready"`
+	Available int32 `json:"available"`
+	Paused    bool  `json:"paused"`
+	// Conditions deployment的Progressing和Available状态
+	Conditions []appsV1.DeploymentCondition `json:"conditions"`
+	// Done 发布已完成
+	Done bool `json:"done"`
+	// Failed 发布超过progressDeadlineSeconds仍未完成
+	Failed  bool   `json:"failed"`
+	Message string `json:"message"`
+}
+
+// GetRolloutStatus 获取deployment当前的发布进度
+func (d *deployment) GetRolloutStatus(cli *K8sClient, deployName, namespace string) (*RolloutStatus, error) {
+	deploy, err := cli.ClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), deployName, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return rolloutStatusOf(deploy), nil
+}
+
+// WatchRolloutStatus 持续监听deployment的发布进度，每次变化都调用send推送，发布完成、失败或ctx结束时返回
+func (d *deployment) WatchRolloutStatus(ctx context.Context, cli *K8sClient, deployName, namespace string, send func(*RolloutStatus) error) error {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", deployName).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metaV1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return cli.ClientSet.AppsV1().Deployments(namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metaV1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return cli.ClientSet.AppsV1().Deployments(namespace).Watch(ctx, options)
+		},
+	}
+	_, err := watchtools.UntilWithSync(ctx, lw, &appsV1.Deployment{}, nil, func(event watch.Event) (bool, error) {
+		switch event.Type {
+		case watch.Deleted:
+			return false, fmt.Errorf("deployment %s 已被删除", deployName)
+		case watch.Added, watch.Modified:
+			deploy, ok := event.Object.(*appsV1.Deployment)
+			if !ok {
+				return false, fmt.Errorf("未知的对象类型 %T", event.Object)
+			}
+			status := rolloutStatusOf(deploy)
+			if err := send(status); err != nil {
+				return false, err
+			}
+			return status.Done || status.Failed, nil
+		}
+		return false, nil
+	})
+	return err
+}
+
+// rolloutStatusOf 根据deployment的状态计算发布进度
+func rolloutStatusOf(deploy *appsV1.Deployment) *RolloutStatus {
+	desired := int32(1)
+	if deploy.Spec.Replicas != nil {
+		desired = *deploy.Spec.Replicas
+	}
+	status := &RolloutStatus{
+		Revision:  revisionOf(deploy),
+		Desired:   desired,
+		Updated:   deploy.Status.UpdatedReplicas,
+		Ready:     deploy.Status.ReadyReplicas,
+		Available: deploy.Status.AvailableReplicas,
+		Paused:    deploy.Spec.Paused,
+	}
+	var progressing *appsV1.DeploymentCondition
+	for i, cond := range deploy.Status.Conditions {
+		if cond.Type == appsV1.DeploymentProgressing || cond.Type == appsV1.DeploymentAvailable {
+			status.Conditions = append(status.Conditions, cond)
+		}
+		if cond.Type == appsV1.DeploymentProgressing {
+			progressing = &deploy.Status.Conditions[i]
+		}
+	}
+
+	switch {
+	case deploy.Generation > deploy.Status.ObservedGeneration:
+		status.Message = "等待deployment的最新配置被控制器处理"
+	case progressing != nil && progressing.Reason == "ProgressDeadlineExceeded":
+		status.Failed = true
+		status.Message = fmt.Sprintf("deployment %s 超过发布期限仍未完成", deploy.Name)
+	case status.Updated < desired:
+		status.Message = fmt.Sprintf("等待发布完成: %d/%d 个副本已更新", status.Updated, desired)
+	case deploy.Status.Replicas > status.Updated:
+		status.Message = fmt.Sprintf("等待发布完成: %d 个旧副本等待终止", deploy.Status.Replicas-status.Updated)
+	case status.Available < status.Updated:
+		status.Message = fmt.Sprintf("等待发布完成: %d/%d 个已更新副本可用", status.Available, status.Updated)
+	default:
+		status.Done = true
+		status.Message = fmt.Sprintf("deployment %s 发布成功", deploy.Name)
+	}
+	return status
+}
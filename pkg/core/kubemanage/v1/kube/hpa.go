@@ -0,0 +1,336 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	autoscalingV2 "k8s.io/api/autoscaling/v2"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+var HPA hpa
+
+type hpa struct{}
+
+type HPAResp struct {
+	Total int                                     `json:"total"`
+	Items []autoscalingV2.HorizontalPodAutoscaler `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []HPARow `json:"rows,omitempty"`
+}
+
+// HPADetail hpa详情，Metrics为每个指标的当前值与目标值，Events为hpa的扩缩容事件
+type HPADetail struct {
+	HPA     *autoscalingV2.HorizontalPodAutoscaler `json:"hpa"`
+	Metrics []HPAMetric                            `json:"metrics"`
+	Events  []coreV1.Event                         `json:"events"`
+}
+
+// HPAMetric 单个扩缩容指标，Current在指标尚未采集到时为<unknown>
+type HPAMetric struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Current string `json:"current"`
+	Target  string `json:"target"`
+}
+
+func (h *hpa) toCells(hpas []autoscalingV2.HorizontalPodAutoscaler) []DataCell {
+	cells := make([]DataCell, len(hpas))
+	for i := range hpas {
+		cells[i] = hpaCell(hpas[i])
+	}
+	return cells
+}
+
+func (h *hpa) FromCells(cells []DataCell) []autoscalingV2.HorizontalPodAutoscaler {
+	hpas := make([]autoscalingV2.HorizontalPodAutoscaler, len(cells))
+	for i := range cells {
+		hpas[i] = autoscalingV2.HorizontalPodAutoscaler(cells[i].(hpaCell))
+	}
+	return hpas
+}
+
+// GetHPAs 查询hpa列表
+// autoscaling/v2 需要kubernetes 1.23及以上版本，为了不影响低版本集群的缓存同步，hpa不注册informer，直接从apiserver查询
+func (h *hpa) GetHPAs(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*HPAResp, error) {
+	query, err := NewDataSelectQuery(in, hpaCell{})
+	if err != nil {
+		return nil, err
+	}
+	hpaList, err := cli.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), metaV1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	selectableData := &dataSelector{
+		GenericDataList: h.toCells(hpaList.Items),
+		DataSelect:      query,
+	}
+	filterd := selectableData.Filter()
+	total := len(filterd.GenericDataList)
+	data := filterd.Sort().Paginate()
+	hpas := h.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]HPARow, len(hpas))
+		for i := range hpas {
+			rows[i] = toHPARow(hpas[i])
+		}
+		return &HPAResp{Total: total, Rows: rows}, nil
+	}
+	return &HPAResp{
+		Total: total,
+		Items: hpas,
+	}, nil
+}
+
+// GetHPADetail 查询hpa详情，附带各指标的当前值、目标值以及扩缩容事件
+func (h *hpa) GetHPADetail(cli *K8sClient, name, namespace string) (*HPADetail, error) {
+	data, err := cli.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	events, err := h.getHPAEvents(cli, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &HPADetail{
+		HPA:     data,
+		Metrics: hpaMetrics(*data),
+		Events:  events,
+	}, nil
+}
+
+// CreateHPA 新增hpa，扩缩容目标为同命名空间下的deployment
+func (h *hpa) CreateHPA(cli *K8sClient, data *kubeDto.HPACreateInput) error {
+	hpa, err := hpaOf(data)
+	if err != nil {
+		return err
+	}
+	if _, err := cli.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(data.NameSpace).Create(context.TODO(), hpa, metaV1.CreateOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate 校验创建hpa的入参，用于和其他资源一起创建时提前发现错误
+func (h *hpa) Validate(data *kubeDto.HPACreateInput) error {
+	_, err := hpaOf(data)
+	return err
+}
+
+func (h *hpa) DeleteHPA(cli *K8sClient, name, namespace string) error {
+	return cli.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+}
+
+func (h *hpa) UpdateHPA(cli *K8sClient, content, namespace string, opt kubeDto.DryRunInput) (*Preview, error) {
+	var hpa = &autoscalingV2.HorizontalPodAutoscaler{}
+	if err := json.Unmarshal([]byte(content), hpa); err != nil {
+		return nil, err
+	}
+	client := cli.ClientSet.AutoscalingV2().HorizontalPodAutoscalers(namespace)
+	return updateWithPreview(opt, hpa, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), hpa.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), hpa, opts)
+	})
+}
+
+// getHPAEvents 查询hpa的事件，按最近发生时间升序排列，与kubectl describe保持一致
+func (h *hpa) getHPAEvents(cli *K8sClient, name, namespace string) ([]coreV1.Event, error) {
+	selector := fields.Set{
+		"involvedObject.kind": "HorizontalPodAutoscaler",
+		"involvedObject.name": name,
+	}.AsSelector().String()
+	eventList, err := cli.ClientSet.CoreV1().Events(namespace).List(context.TODO(), metaV1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	events := eventList.Items
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	return events, nil
+}
+
+// eventTime 事件最近一次发生的时间，新版events接口只设置EventTime
+func eventTime(event coreV1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// hpaOf 根据入参生成hpa，cpu与内存均按平均使用率扩缩容
+func hpaOf(data *kubeDto.HPACreateInput) (*autoscalingV2.HorizontalPodAutoscaler, error) {
+	minReplicas := data.MinReplicas
+	if minReplicas == 0 {
+		minReplicas = 1
+	}
+	if data.MaxReplicas < minReplicas {
+		return nil, fmt.Errorf("最大副本数 %d 不能小于最小副本数 %d", data.MaxReplicas, minReplicas)
+	}
+	var metrics []autoscalingV2.MetricSpec
+	for name, utilization := range map[coreV1.ResourceName]int32{coreV1.ResourceCPU: data.CpuUtilization, coreV1.ResourceMemory: data.MemoryUtilization} {
+		if utilization == 0 {
+			continue
+		}
+		utilization := utilization
+		metrics = append(metrics, autoscalingV2.MetricSpec{
+			Type: autoscalingV2.ResourceMetricSourceType,
+			Resource: &autoscalingV2.ResourceMetricSource{
+				Name: name,
+				Target: autoscalingV2.MetricTarget{
+					Type:               autoscalingV2.UtilizationMetricType,
+					AverageUtilization: &utilization,
+				},
+			},
+		})
+	}
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("cpu与内存的目标使用率至少需要填写一项")
+	}
+	//map遍历无序，按指标名称排序保证生成的spec稳定
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Resource.Name < metrics[j].Resource.Name
+	})
+	return &autoscalingV2.HorizontalPodAutoscaler{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      data.Name,
+			Namespace: data.NameSpace,
+			Labels:    data.Labels,
+		},
+		Spec: autoscalingV2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingV2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       data.Deployment,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: data.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}, nil
+}
+
+// hpaMetrics 将spec中的目标值与status中的当前值按指标类型和名称对应起来
+func hpaMetrics(hpa autoscalingV2.HorizontalPodAutoscaler) []HPAMetric {
+	metrics := make([]HPAMetric, 0, len(hpa.Spec.Metrics))
+	for _, spec := range hpa.Spec.Metrics {
+		name, target := metricSpecValue(spec)
+		metric := HPAMetric{
+			Type:    string(spec.Type),
+			Name:    name,
+			Current: "<unknown>",
+			Target:  target,
+		}
+		for _, status := range hpa.Status.CurrentMetrics {
+			if statusName, current := metricStatusValue(status); status.Type == spec.Type && statusName == name {
+				metric.Current = current
+				break
+			}
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+func metricSpecValue(spec autoscalingV2.MetricSpec) (string, string) {
+	switch spec.Type {
+	case autoscalingV2.ResourceMetricSourceType:
+		if spec.Resource != nil {
+			return string(spec.Resource.Name), metricTargetValue(spec.Resource.Target)
+		}
+	case autoscalingV2.ContainerResourceMetricSourceType:
+		if spec.ContainerResource != nil {
+			return spec.ContainerResource.Container + "/" + string(spec.ContainerResource.Name), metricTargetValue(spec.ContainerResource.Target)
+		}
+	case autoscalingV2.PodsMetricSourceType:
+		if spec.Pods != nil {
+			return spec.Pods.Metric.Name, metricTargetValue(spec.Pods.Target)
+		}
+	case autoscalingV2.ObjectMetricSourceType:
+		if spec.Object != nil {
+			return spec.Object.Metric.Name, metricTargetValue(spec.Object.Target)
+		}
+	case autoscalingV2.ExternalMetricSourceType:
+		if spec.External != nil {
+			return spec.External.Metric.Name, metricTargetValue(spec.External.Target)
+		}
+	}
+	return "", "<unknown>"
+}
+
+func metricStatusValue(status autoscalingV2.MetricStatus) (string, string) {
+	switch status.Type {
+	case autoscalingV2.ResourceMetricSourceType:
+		if status.Resource != nil {
+			return string(status.Resource.Name), metricValueStatus(status.Resource.Current)
+		}
+	case autoscalingV2.ContainerResourceMetricSourceType:
+		if status.ContainerResource != nil {
+			return status.ContainerResource.Container + "/" + string(status.ContainerResource.Name), metricValueStatus(status.ContainerResource.Current)
+		}
+	case autoscalingV2.PodsMetricSourceType:
+		if status.Pods != nil {
+			return status.Pods.Metric.Name, metricValueStatus(status.Pods.Current)
+		}
+	case autoscalingV2.ObjectMetricSourceType:
+		if status.Object != nil {
+			return status.Object.Metric.Name, metricValueStatus(status.Object.Current)
+		}
+	case autoscalingV2.ExternalMetricSourceType:
+		if status.External != nil {
+			return status.External.Metric.Name, metricValueStatus(status.External.Current)
+		}
+	}
+	return "", "<unknown>"
+}
+
+// metricTargetValue 使用率以百分比展示，其余以数量展示，平均值带上(avg)后缀
+func metricTargetValue(target autoscalingV2.MetricTarget) string {
+	switch {
+	case target.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *target.AverageUtilization)
+	case target.AverageValue != nil:
+		return target.AverageValue.String() + " (avg)"
+	case target.Value != nil:
+		return target.Value.String()
+	}
+	return "<unknown>"
+}
+
+func metricValueStatus(current autoscalingV2.MetricValueStatus) string {
+	switch {
+	case current.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *current.AverageUtilization)
+	case current.AverageValue != nil:
+		return current.AverageValue.String() + " (avg)"
+	case current.Value != nil:
+		return current.Value.String()
+	}
+	return "<unknown>"
+}
+
+// hpaTargets 将各指标拼接为 cpu: 30%/80% 的形式，与kubectl get hpa的TARGETS列一致
+func hpaTargets(hpa autoscalingV2.HorizontalPodAutoscaler) string {
+	metrics := hpaMetrics(hpa)
+	if len(metrics) == 0 {
+		return "<none>"
+	}
+	targets := make([]string, len(metrics))
+	for i, metric := range metrics {
+		targets[i] = fmt.Sprintf("%s: %s/%s", metric.Name, metric.Current, metric.Target)
+	}
+	return strings.Join(targets, ", ")
+}
@@ -0,0 +1,202 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+const (
+	// revisionPollInterval 更新镜像后查询新版本号的间隔
+	revisionPollInterval = 500 * time.Millisecond
+	// revisionPollTimeout 等待控制器生成新版本的最长时间，超时后返回的版本号为0
+	revisionPollTimeout = 10 * time.Second
+)
+
+// SetImage 更新deployment中指定容器的镜像，并记录变更原因，返回新的版本号
+func (d *deployment) SetImage(cli *K8sClient, in *kubeDto.SetImageInput) (int64, error) {
+	client := cli.ClientSet.AppsV1().Deployments(in.NameSpace)
+	deploy, err := client.Get(context.TODO(), in.Name, metaV1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	patchByte, err := imagePatch(&deploy.Spec.Template.Spec, in)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = client.Patch(context.TODO(), in.Name, types.StrategicMergePatchType, patchByte, metaV1.PatchOptions{}); err != nil {
+		return 0, err
+	}
+
+	var revision int64
+	err = waitRevision(func() (bool, error) {
+		deploy, err = client.Get(context.TODO(), in.Name, metaV1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if deploy.Status.ObservedGeneration < deploy.Generation {
+			return false, nil
+		}
+		revision = revisionOf(deploy)
+		return true, nil
+	})
+	return revision, err
+}
+
+// SetImage 更新statefulset中指定容器的镜像，并记录变更原因，返回新的版本号
+func (d *statefulSet) SetImage(cli *K8sClient, in *kubeDto.SetImageInput) (int64, error) {
+	client := cli.ClientSet.AppsV1().StatefulSets(in.NameSpace)
+	sts, err := client.Get(context.TODO(), in.Name, metaV1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	patchByte, err := imagePatch(&sts.Spec.Template.Spec, in)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = client.Patch(context.TODO(), in.Name, types.StrategicMergePatchType, patchByte, metaV1.PatchOptions{}); err != nil {
+		return 0, err
+	}
+
+	var revision int64
+	err = waitRevision(func() (bool, error) {
+		sts, err = client.Get(context.TODO(), in.Name, metaV1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if sts.Status.ObservedGeneration < sts.Generation || sts.Status.UpdateRevision == "" {
+			return false, nil
+		}
+		cr, err := cli.ClientSet.AppsV1().ControllerRevisions(in.NameSpace).Get(context.TODO(), sts.Status.UpdateRevision, metaV1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		revision = cr.Revision
+		return true, nil
+	})
+	return revision, err
+}
+
+// SetImage 更新daemonset中指定容器的镜像，并记录变更原因，返回新的版本号
+func (d *daemonSet) SetImage(cli *K8sClient, in *kubeDto.SetImageInput) (int64, error) {
+	client := cli.ClientSet.AppsV1().DaemonSets(in.NameSpace)
+	ds, err := client.Get(context.TODO(), in.Name, metaV1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	patchByte, err := imagePatch(&ds.Spec.Template.Spec, in)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = client.Patch(context.TODO(), in.Name, types.StrategicMergePatchType, patchByte, metaV1.PatchOptions{}); err != nil {
+		return 0, err
+	}
+
+	var revision int64
+	err = waitRevision(func() (bool, error) {
+		ds, err = client.Get(context.TODO(), in.Name, metaV1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if ds.Status.ObservedGeneration < ds.Generation {
+			return false, nil
+		}
+		// daemonset的版本记录在其拥有的controllerRevision中，取最大的版本号
+		selector, err := metaV1.LabelSelectorAsSelector(ds.Spec.Selector)
+		if err != nil {
+			return false, err
+		}
+		crList, err := cli.ClientSet.AppsV1().ControllerRevisions(in.NameSpace).List(context.TODO(), metaV1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return false, err
+		}
+		for i := range crList.Items {
+			if ref := metaV1.GetControllerOf(&crList.Items[i]); ref != nil && ref.UID == ds.UID && crList.Items[i].Revision > revision {
+				revision = crList.Items[i].Revision
+			}
+		}
+		return true, nil
+	})
+	return revision, err
+}
+
+// imagePatch 根据容器名称生成更新镜像的strategic merge patch，容器不存在时返回错误
+func imagePatch(spec *coreV1.PodSpec, in *kubeDto.SetImageInput) ([]byte, error) {
+	remaining := make(map[string]string, len(in.Images))
+	for name, image := range in.Images {
+		if image == "" {
+			return nil, fmt.Errorf("容器 %s 的镜像不能为空", name)
+		}
+		remaining[name] = image
+	}
+	pick := func(containers []coreV1.Container) []map[string]string {
+		var out []map[string]string
+		for _, c := range containers {
+			if image, ok := remaining[c.Name]; ok {
+				out = append(out, map[string]string{"name": c.Name, "image": image})
+				delete(remaining, c.Name)
+			}
+		}
+		return out
+	}
+	podSpec := map[string]interface{}{}
+	if containers := pick(spec.Containers); len(containers) != 0 {
+		podSpec["containers"] = containers
+	}
+	if initContainers := pick(spec.InitContainers); len(initContainers) != 0 {
+		podSpec["initContainers"] = initContainers
+	}
+	if len(remaining) != 0 {
+		missing := make([]string, 0, len(remaining))
+		for name := range remaining {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("容器 %s 不存在", strings.Join(missing, ","))
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				ChangeCauseAnnotation: changeCause(in),
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": podSpec,
+			},
+		},
+	})
+}
+
+// changeCause 未指定变更原因时，按 kubectl set image 的格式生成
+func changeCause(in *kubeDto.SetImageInput) string {
+	if in.ChangeCause != "" {
+		return in.ChangeCause
+	}
+	pairs := make([]string, 0, len(in.Images))
+	for name, image := range in.Images {
+		pairs = append(pairs, name+"="+image)
+	}
+	sort.Strings(pairs)
+	return "set image " + strings.Join(pairs, " ")
+}
+
+// waitRevision 等待控制器处理完最新的配置并生成新版本，超时不视为失败
+func waitRevision(condition wait.ConditionFunc) error {
+	err := wait.PollImmediate(revisionPollInterval, revisionPollTimeout, condition)
+	if err == wait.ErrWaitTimeout {
+		return nil
+	}
+	return err
+}
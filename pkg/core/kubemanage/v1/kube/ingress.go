@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	nwV1 "k8s.io/api/networking/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
 )
@@ -16,6 +18,8 @@ type ingress struct{}
 type ingressResp struct {
 	Total int            `json:"total"`
 	Items []nwV1.Ingress `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []IngressRow `json:"rows,omitempty"`
 }
 
 type ingressNp struct {
@@ -23,10 +27,10 @@ type ingressNp struct {
 	IngressNum int    `json:"ingres_num"`
 }
 
-func (i *ingress) toCells(ingress []nwV1.Ingress) []DataCell {
+func (i *ingress) toCells(ingress []*nwV1.Ingress) []DataCell {
 	cells := make([]DataCell, len(ingress))
 	for i := range ingress {
-		cells[i] = ingressCell(ingress[i])
+		cells[i] = ingressCell(*ingress[i])
 	}
 	return cells
 }
@@ -39,7 +43,7 @@ func (i *ingress) FromCells(cells []DataCell) []nwV1.Ingress {
 	return ingress
 }
 
-func (i *ingress) CreateIngress(data *kubeDto.IngressCreteInput) error {
+func (i *ingress) CreateIngress(cli *K8sClient, data *kubeDto.IngressCreteInput) error {
 	//声明两个变量,用于后面组装数据
 	var ingressRules []nwV1.IngressRule
 	var httpIngressPaths []nwV1.HTTPIngressPath
@@ -86,74 +90,87 @@ func (i *ingress) CreateIngress(data *kubeDto.IngressCreteInput) error {
 	}
 	ingress.Spec.Rules = ingressRules
 	//创建ingress
-	if _, err := K8sCli.ClientSet.NetworkingV1().Ingresses(data.NameSpace).Create(context.TODO(), ingress, metaV1.CreateOptions{}); err != nil {
+	if _, err := cli.ClientSet.NetworkingV1().Ingresses(data.NameSpace).Create(context.TODO(), ingress, metaV1.CreateOptions{}); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (i *ingress) DeleteIngress(namespace, name string) error {
-	return K8sCli.ClientSet.NetworkingV1().Ingresses(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (i *ingress) DeleteIngress(cli *K8sClient, namespace, name string) error {
+	return cli.ClientSet.NetworkingV1().Ingresses(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
 
-func (i *ingress) UpdateIngress(namespace, content string) error {
+func (i *ingress) UpdateIngress(cli *K8sClient, namespace, content string, opt kubeDto.DryRunInput) (*Preview, error) {
 	ingress := &nwV1.Ingress{}
 	if err := json.Unmarshal([]byte(content), ingress); err != nil {
-		return err
-	}
-	if _, err := K8sCli.ClientSet.NetworkingV1().Ingresses(namespace).Update(context.TODO(), ingress, metaV1.UpdateOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.NetworkingV1().Ingresses(namespace)
+	return updateWithPreview(opt, ingress, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), ingress.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), ingress, opts)
+	})
 }
 
-func (i *ingress) GetIngressList(filterName, namespace string, limit, page int) (*ingressResp, error) {
-	ingressList, err := K8sCli.ClientSet.NetworkingV1().Ingresses(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (i *ingress) GetIngressList(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*ingressResp, error) {
+	query, err := NewDataSelectQuery(in, ingressCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	ingressList, err := cli.Informers.Networking().V1().Ingresses().Lister().Ingresses(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	selectableData := &dataSelector{
-		GenericDataList: i.toCells(ingressList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: i.toCells(ingressList),
+		DataSelect:      query,
 	}
 	filterd := selectableData.Filter()
 	total := len(filterd.GenericDataList)
 	data := filterd.Sort().Paginate()
 	ingress := i.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]IngressRow, len(ingress))
+		for i := range ingress {
+			rows[i] = toIngressRow(ingress[i])
+		}
+		return &ingressResp{Total: total, Rows: rows}, nil
+	}
 	return &ingressResp{
 		Total: total,
 		Items: ingress,
 	}, nil
 }
 
-func (i *ingress) GetIngressDetail(namespace, name string) (*nwV1.Ingress, error) {
-	data, err := K8sCli.ClientSet.NetworkingV1().Ingresses(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+func (i *ingress) GetIngressDetail(cli *K8sClient, namespace, name string) (*nwV1.Ingress, error) {
+	data, err := cli.ClientSet.NetworkingV1().Ingresses(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (i *ingress) GetIngressNp() ([]*ingressNp, error) {
-	namespaceList, err := K8sCli.ClientSet.CoreV1().Namespaces().List(context.TODO(), metaV1.ListOptions{})
+func (i *ingress) GetIngressNp(cli *K8sClient) ([]*ingressNp, error) {
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	namespaceList, err := cli.Informers.Core().V1().Namespaces().Lister().List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	var ingressnps []*ingressNp
-	for _, namespace := range namespaceList.Items {
-		ingress, err := K8sCli.ClientSet.NetworkingV1().Ingresses(namespace.Name).List(context.TODO(), metaV1.ListOptions{})
+	for _, namespace := range namespaceList {
+		ingress, err := cli.Informers.Networking().V1().Ingresses().Lister().Ingresses(namespace.Name).List(labels.Everything())
 		if err != nil {
 			return nil, err
 		}
 		ingressNp := &ingressNp{
 			NameSpace:  namespace.Name,
-			IngressNum: len(ingress.Items),
+			IngressNum: len(ingress),
 		}
 		ingressnps = append(ingressnps, ingressNp)
 	}
@@ -2,24 +2,72 @@ package kube
 
 import (
 	"flag"
-	"github.com/noovertime7/kubemanage/pkg/logger"
 	"os"
 	"path/filepath"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/noovertime7/kubemanage/pkg/logger"
 )
 
-// K8sCli 全局的k8s客户端
-var K8sCli K8sClient
+// DefaultClusterName 本地集群(InCluster或kubeconfig文件)注册到集群注册表时使用的名称，请求未指定cluster参数时使用该集群
+const DefaultClusterName = "default"
 
 // K8sClient 用于操作kubernetes的客户端集合
 type K8sClient struct {
+	// Name 集群名称
+	Name string
 	// Config 集群配置对象
 	Config *rest.Config
 	// ClientSet kubernetes的客户端集合
 	ClientSet *kubernetes.Clientset
+	// Informers 集群资源的共享informer，列表接口从其本地缓存中读取
+	Informers informers.SharedInformerFactory
+	// DynamicClient 用于操作任意资源的动态客户端
+	DynamicClient dynamic.Interface
+	// Mapper 通过discovery将GVK解析为GVR，发现结果缓存在内存中
+	Mapper meta.ResettableRESTMapper
+
+	// stopCh 用于停止 informer
+	stopCh chan struct{}
+	// synced 缓存是否同步完成，1为已同步
+	synced int32
+}
+
+// NewK8sClient 根据集群配置创建客户端
+func NewK8sClient(name string, config *rest.Config) (*K8sClient, error) {
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &K8sClient{
+		Name:          name,
+		Config:        config,
+		ClientSet:     clientSet,
+		Informers:     informers.NewSharedInformerFactory(clientSet, 0),
+		DynamicClient: dynamicClient,
+		Mapper:        restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(clientSet.Discovery())),
+	}, nil
+}
+
+// NewK8sClientFromKubeConfig 根据kubeconfig文件内容创建客户端
+func NewK8sClientFromKubeConfig(name string, kubeConfig []byte) (*K8sClient, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewK8sClient(name, config)
 }
 
 // Init 初始化 k8s客户端+配置
@@ -49,14 +97,17 @@ func (k *K8sClient) Init() error {
 	}
 
 	// 创建 clientSet
-	clientSet, err := kubernetes.NewForConfig(config)
+	client, err := NewK8sClient(k.Name, config)
 	if err != nil {
 		return err
 	}
 	log := logger.New()
 	log.Info("获取k8s clientSet 成功")
-	k.ClientSet = clientSet
-	k.Config = config
+	k.ClientSet = client.ClientSet
+	k.Config = client.Config
+	k.Informers = client.Informers
+	k.DynamicClient = client.DynamicClient
+	k.Mapper = client.Mapper
 	return nil
 }
 
@@ -0,0 +1,211 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+var Job job
+
+type job struct{}
+
+type JobResp struct {
+	Total int           `json:"total"`
+	Items []batchV1.Job `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []JobRow `json:"rows,omitempty"`
+}
+
+// job的运行状态
+const (
+	JobRunning   = "Running"
+	JobSucceeded = "Succeeded"
+	JobFailed    = "Failed"
+	JobSuspended = "Suspended"
+)
+
+func (j *job) toCells(jobs []*batchV1.Job) []DataCell {
+	cells := make([]DataCell, len(jobs))
+	for i := range jobs {
+		cells[i] = jobCell(*jobs[i])
+	}
+	return cells
+}
+
+func (j *job) FromCells(cells []DataCell) []batchV1.Job {
+	jobs := make([]batchV1.Job, len(cells))
+	for i := range cells {
+		jobs[i] = batchV1.Job(cells[i].(jobCell))
+	}
+	return jobs
+}
+
+func (j *job) GetJobs(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*JobResp, error) {
+	query, err := NewDataSelectQuery(in, jobCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	jobList, err := cli.Informers.Batch().V1().Jobs().Lister().Jobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	selectableData := &dataSelector{
+		GenericDataList: j.toCells(jobList),
+		DataSelect:      query,
+	}
+	filterd := selectableData.Filter()
+	total := len(filterd.GenericDataList)
+	data := filterd.Sort().Paginate()
+	jobs := j.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]JobRow, len(jobs))
+		for i := range jobs {
+			rows[i] = toJobRow(jobs[i])
+		}
+		return &JobResp{Total: total, Rows: rows}, nil
+	}
+	return &JobResp{
+		Total: total,
+		Items: jobs,
+	}, nil
+}
+
+func (j *job) GetJobDetail(cli *K8sClient, name, namespace string) (*batchV1.Job, error) {
+	data, err := cli.ClientSet.BatchV1().Jobs(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// CreateJob 新增job，pod的标签和选择器由kubernetes自动生成
+func (j *job) CreateJob(cli *K8sClient, data *kubeDto.JobCreateInput) error {
+	spec, err := jobSpecOf(data)
+	if err != nil {
+		return err
+	}
+	job := &batchV1.Job{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      data.Name,
+			Namespace: data.NameSpace,
+			Labels:    data.Labels,
+		},
+		Spec: spec,
+	}
+	if _, err := cli.ClientSet.BatchV1().Jobs(data.NameSpace).Create(context.TODO(), job, metaV1.CreateOptions{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteJob 删除job，job的pod在后台一起删除
+func (j *job) DeleteJob(cli *K8sClient, name, namespace string) error {
+	propagation := metaV1.DeletePropagationBackground
+	return cli.ClientSet.BatchV1().Jobs(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+func (j *job) UpdateJob(cli *K8sClient, content, namespace string, opt kubeDto.DryRunInput) (*Preview, error) {
+	var job = &batchV1.Job{}
+	if err := json.Unmarshal([]byte(content), job); err != nil {
+		return nil, err
+	}
+	client := cli.ClientSet.BatchV1().Jobs(namespace)
+	return updateWithPreview(opt, job, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), job.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), job, opts)
+	})
+}
+
+// jobSpecOf 根据入参生成job的spec，job和cronjob的任务模板共用
+func jobSpecOf(data *kubeDto.JobCreateInput) (batchV1.JobSpec, error) {
+	restartPolicy := coreV1.RestartPolicyNever
+	if data.RestartPolicy != "" {
+		restartPolicy = coreV1.RestartPolicy(data.RestartPolicy)
+	}
+	container := coreV1.Container{
+		Name:    data.Name,
+		Image:   data.Image,
+		Command: data.Command,
+		Args:    data.Args,
+	}
+	//定义容器的limit与request资源，未填写的资源不限制
+	for name, value := range map[coreV1.ResourceName]string{coreV1.ResourceCPU: data.Cpu, coreV1.ResourceMemory: data.Memory} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return batchV1.JobSpec{}, fmt.Errorf("%s 格式错误: %v", name, err)
+		}
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = coreV1.ResourceList{}
+			container.Resources.Requests = coreV1.ResourceList{}
+		}
+		container.Resources.Limits[name] = quantity
+		container.Resources.Requests[name] = quantity
+	}
+	spec := batchV1.JobSpec{
+		BackoffLimit:            data.BackoffLimit,
+		TTLSecondsAfterFinished: data.TTLSecondsAfterFinished,
+		Template: coreV1.PodTemplateSpec{
+			ObjectMeta: metaV1.ObjectMeta{
+				Labels: data.Labels,
+			},
+			Spec: coreV1.PodSpec{
+				RestartPolicy: restartPolicy,
+				Containers:    []coreV1.Container{container},
+			},
+		},
+	}
+	if data.Completions > 0 {
+		spec.Completions = &data.Completions
+	}
+	if data.Parallelism > 0 {
+		spec.Parallelism = &data.Parallelism
+	}
+	if data.ActiveDeadlineSeconds > 0 {
+		spec.ActiveDeadlineSeconds = &data.ActiveDeadlineSeconds
+	}
+	return spec, nil
+}
+
+// jobStatus 根据job的Complete、Failed状态计算运行状态
+func jobStatus(job batchV1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != coreV1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchV1.JobComplete:
+			return JobSucceeded
+		case batchV1.JobFailed:
+			return JobFailed
+		}
+	}
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		return JobSuspended
+	}
+	return JobRunning
+}
+
+// jobCompletions job需要成功完成的pod数，未设置时为1
+func jobCompletions(job batchV1.Job) int32 {
+	if job.Spec.Completions != nil {
+		return *job.Spec.Completions
+	}
+	return 1
+}
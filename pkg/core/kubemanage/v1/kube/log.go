@@ -0,0 +1,202 @@
+package kube
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/noovertime7/kubemanage/cmd/app/config"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+const (
+	// defaultPodLogTailLine 配置文件未设置podLogTailLine时默认返回的日志行数
+	defaultPodLogTailLine = 100
+	// maxLogBatchSize 推送日志时单条消息的最大字节数，日志流中已缓冲的多行会合并推送
+	maxLogBatchSize = 32 * 1024
+)
+
+// DefaultTailLines 获取配置文件中的podLogTailLine，未配置或格式错误时返回100
+func DefaultTailLines() int64 {
+	if config.SysConfig != nil {
+		if lines, err := strconv.ParseInt(config.SysConfig.Default.PodLogTailLine, 10, 64); err == nil && lines > 0 {
+			return lines
+		}
+	}
+	return defaultPodLogTailLine
+}
+
+// NewPodLogOptions 将日志查询参数转换为k8s的PodLogOptions
+func NewPodLogOptions(container string, in *kubeDto.PodLogOptionsInput) (*coreV1.PodLogOptions, error) {
+	opts := &coreV1.PodLogOptions{
+		Container:  container,
+		Follow:     in.Follow,
+		Timestamps: in.Timestamps,
+		Previous:   in.Previous,
+	}
+	switch {
+	case in.TailLines == 0:
+		tailLines := DefaultTailLines()
+		opts.TailLines = &tailLines
+	case in.TailLines > 0:
+		tailLines := in.TailLines
+		opts.TailLines = &tailLines
+	}
+	if in.SinceSeconds > 0 && in.SinceTime != "" {
+		return nil, errors.New("since_seconds与since_time不能同时指定")
+	}
+	if in.SinceSeconds > 0 {
+		sinceSeconds := in.SinceSeconds
+		opts.SinceSeconds = &sinceSeconds
+	}
+	if in.SinceTime != "" {
+		sinceTime, err := time.Parse(time.RFC3339, in.SinceTime)
+		if err != nil {
+			return nil, fmt.Errorf("since_time格式有误: %v", err)
+		}
+		t := metaV1.NewTime(sinceTime)
+		opts.SinceTime = &t
+	}
+	return opts, nil
+}
+
+// StreamPodLog 打开容器的日志流，ctx结束时日志流随之关闭
+func (p *pod) StreamPodLog(ctx context.Context, cli *K8sClient, podName, namespace string, opts *coreV1.PodLogOptions) (io.ReadCloser, error) {
+	return cli.ClientSet.CoreV1().Pods(namespace).GetLogs(podName, opts).Stream(ctx)
+}
+
+// PumpLog 逐行读取日志流并推送，读取缓冲区中已有的多行会合并为一条消息；
+// send阻塞时不再读取日志流，由tcp反压到apiserver，避免在服务端堆积日志
+func PumpLog(r io.Reader, send func(data string) error) error {
+	reader := bufio.NewReaderSize(r, maxLogBatchSize)
+	var batch []byte
+	for {
+		line, err := reader.ReadBytes('\n')
+		batch = append(batch, line...)
+		// 缓冲区中没有更多数据或者批次已满时推送
+		if len(batch) != 0 && (err != nil || reader.Buffered() == 0 || len(batch) >= maxLogBatchSize) {
+			if sendErr := send(string(batch)); sendErr != nil {
+				return sendErr
+			}
+			batch = batch[:0]
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// LogFilter 下载日志时在服务端按时间和正则过滤日志行，正则只匹配去掉时间戳后的内容
+type LogFilter struct {
+	Include *regexp.Regexp
+	Exclude *regexp.Regexp
+	// Until 只保留该时间之前的日志，需要日志流附带时间戳
+	Until *time.Time
+	// Timestamps 输出时是否保留日志流中的时间戳
+	Timestamps bool
+}
+
+// NewPodLogDownload 将下载参数转换为PodLogOptions和日志过滤条件，指定结束时间时总是向apiserver请求时间戳用于判断
+func NewPodLogDownload(in *kubeDto.PodLogDownloadInput) (*coreV1.PodLogOptions, *LogFilter, error) {
+	tailLines := in.TailLines
+	if tailLines == 0 {
+		tailLines = -1
+	}
+	opts, err := NewPodLogOptions(in.ContainerName, &kubeDto.PodLogOptionsInput{
+		TailLines:    tailLines,
+		SinceSeconds: in.SinceSeconds,
+		SinceTime:    in.SinceTime,
+		Timestamps:   in.Timestamps,
+		Previous:     in.Previous,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	filter := &LogFilter{Timestamps: in.Timestamps}
+	if in.Include != "" {
+		if filter.Include, err = regexp.Compile(in.Include); err != nil {
+			return nil, nil, fmt.Errorf("include格式有误: %v", err)
+		}
+	}
+	if in.Exclude != "" {
+		if filter.Exclude, err = regexp.Compile(in.Exclude); err != nil {
+			return nil, nil, fmt.Errorf("exclude格式有误: %v", err)
+		}
+	}
+	if in.UntilTime != "" {
+		until, err := time.Parse(time.RFC3339, in.UntilTime)
+		if err != nil {
+			return nil, nil, fmt.Errorf("until_time格式有误: %v", err)
+		}
+		if opts.SinceTime != nil && !until.After(opts.SinceTime.Time) {
+			return nil, nil, errors.New("until_time必须晚于since_time")
+		}
+		filter.Until = &until
+		opts.Timestamps = true
+	}
+	return opts, filter, nil
+}
+
+// CopyLog 逐行读取日志流，过滤后写入w，不在内存中缓存整个日志；日志按时间顺序输出，超过结束时间后不再读取
+func CopyLog(w io.Writer, r io.Reader, filter *LogFilter) error {
+	reader := bufio.NewReaderSize(r, maxLogBatchSize)
+	// 存在结束时间时日志流一定带有时间戳
+	withTimestamps := filter.Timestamps || filter.Until != nil
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) != 0 {
+			out, stop := filter.apply(line, withTimestamps)
+			if stop {
+				return nil
+			}
+			if out != nil {
+				if _, writeErr := w.Write(out); writeErr != nil {
+					return writeErr
+				}
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// apply 返回需要输出的内容，不满足过滤条件时返回nil，超过结束时间时stop为true
+func (f *LogFilter) apply(line []byte, withTimestamps bool) (out []byte, stop bool) {
+	content := line
+	if withTimestamps {
+		if i := bytes.IndexByte(line, ' '); i > 0 {
+			if f.Until != nil {
+				if ts, err := time.Parse(time.RFC3339Nano, string(line[:i])); err == nil && ts.After(*f.Until) {
+					return nil, true
+				}
+			}
+			content = line[i+1:]
+		}
+	}
+	if f.Include != nil && !f.Include.Match(content) {
+		return nil, false
+	}
+	if f.Exclude != nil && f.Exclude.Match(content) {
+		return nil, false
+	}
+	if withTimestamps && !f.Timestamps {
+		return content, false
+	}
+	return line, false
+}
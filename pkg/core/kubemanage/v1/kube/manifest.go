@@ -0,0 +1,189 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// FieldManager 服务端apply时使用的字段管理者名称
+const FieldManager = "kubemanage"
+
+// apply 的执行结果
+const (
+	ApplyCreated    = "created"
+	ApplyConfigured = "configured"
+	ApplyUnchanged  = "unchanged"
+	ApplyFailed     = "failed"
+)
+
+// Manifest 全局变量，用于apply任意内置或自定义资源的yaml/json清单
+var Manifest manifest
+
+type manifest struct{}
+
+// ApplyResult 单个对象的apply结果
+type ApplyResult struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	// Status 取值为 created、configured、unchanged、failed
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// Preview 预览模式下与线上对象的差异
+	Preview *Preview `json:"preview,omitempty"`
+}
+
+// Apply 解析多文档的yaml或json清单，逐个对象通过服务端apply提交，单个对象失败不影响其他对象，预览模式下不会修改集群
+func (m *manifest) Apply(cli *K8sClient, in *kubeDto.ApplyInput) ([]ApplyResult, error) {
+	objects, err := DecodeManifest(in.Content)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ApplyResult, 0, len(objects))
+	for _, obj := range objects {
+		result := ApplyResult{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+		}
+		status, preview, err := m.applyOne(cli, obj, in)
+		result.Namespace = obj.GetNamespace()
+		if err != nil {
+			result.Status = ApplyFailed
+			result.Message = err.Error()
+		} else {
+			result.Status = status
+			result.Preview = preview
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// applyOne 提交单个对象，通过对比apply前后的resourceVersion判断对象是否发生变化，预览模式下根据差异判断
+func (m *manifest) applyOne(cli *K8sClient, obj *unstructured.Unstructured, in *kubeDto.ApplyInput) (string, *Preview, error) {
+	client, err := ResourceClient(cli, obj, in.NameSpace)
+	if err != nil {
+		return "", nil, err
+	}
+	// 服务端apply不允许携带managedFields
+	obj.SetManagedFields(nil)
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", nil, err
+	}
+
+	status := ApplyConfigured
+	live, err := client.Get(context.TODO(), obj.GetName(), metaV1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		status = ApplyCreated
+		live = nil
+	} else if err != nil {
+		return "", nil, err
+	}
+	opts := metaV1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &in.Force,
+	}
+	if in.DryRun {
+		opts.DryRun = []string{metaV1.DryRunAll}
+	}
+	applied, err := client.Patch(context.TODO(), obj.GetName(), types.ApplyPatchType, data, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !in.DryRun {
+		if status == ApplyConfigured && live.GetResourceVersion() == applied.GetResourceVersion() {
+			status = ApplyUnchanged
+		}
+		return status, nil, nil
+	}
+	preview, err := NewPreview(live, applied, in.UnifiedDiff)
+	if err != nil {
+		return "", nil, err
+	}
+	if status == ApplyConfigured && len(preview.Changes) == 0 {
+		status = ApplyUnchanged
+	}
+	return status, preview, nil
+}
+
+// ResourceClient 通过discovery解析对象的GVR，返回对应的动态客户端，命名空间级资源未指定命名空间时使用namespace
+func ResourceClient(cli *K8sClient, obj *unstructured.Unstructured, namespace string) (dynamic.ResourceInterface, error) {
+	if obj.GetName() == "" {
+		return nil, errors.New("对象缺少metadata.name")
+	}
+	gvk := obj.GroupVersionKind()
+	mapping, err := cli.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// 可能是新注册的CRD，刷新discovery缓存后重试
+		cli.Mapper.Reset()
+		mapping, err = cli.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		obj.SetNamespace("")
+		return cli.DynamicClient.Resource(mapping.Resource), nil
+	}
+	if obj.GetNamespace() == "" {
+		if namespace == "" {
+			namespace = metaV1.NamespaceDefault
+		}
+		obj.SetNamespace(namespace)
+	}
+	return cli.DynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+// DecodeManifest 解析多文档的yaml或json，跳过空文档，并展开 kind 为 List 的文档
+func DecodeManifest(content string) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewBufferString(content), 4096)
+	var objects []*unstructured.Unstructured
+	for i := 1; ; i++ {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("解析第 %d 个文档失败: %v", i, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("第 %d 个文档缺少apiVersion或kind", i)
+		}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("解析第 %d 个文档失败: %v", i, err)
+			}
+			for j := range list.Items {
+				objects = append(objects, &list.Items[j])
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	if len(objects) == 0 {
+		return nil, errors.New("清单中没有可提交的对象")
+	}
+	return objects, nil
+}
@@ -2,9 +2,11 @@ package kube
 
 import (
 	"context"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	"github.com/pkg/errors"
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // NameSpace 全局变量，操作kubernetes环境使用
@@ -19,12 +21,14 @@ type NameSpaceResp struct {
 	Total int `json:"total"`
 	// Items coreV1.Namespace是k8s原生的ns结构，包括metadata、spec、status
 	Items []coreV1.Namespace `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []NamespaceRow `json:"rows,omitempty"`
 }
 
-func (n *namespace) toCells(nodes []coreV1.Namespace) []DataCell {
+func (n *namespace) toCells(nodes []*coreV1.Namespace) []DataCell {
 	cells := make([]DataCell, len(nodes))
 	for i := range nodes {
-		cells[i] = namespaceCell(nodes[i])
+		cells[i] = namespaceCell(*nodes[i])
 	}
 	return cells
 }
@@ -38,20 +42,24 @@ func (n *namespace) FromCells(cells []DataCell) []coreV1.Namespace {
 }
 
 // GetNameSpaces 从k8s中获取ns列表
-func (n *namespace) GetNameSpaces(filterName string, limit, page int) (nodesResp *NameSpaceResp, err error) {
-	// 拿到全局的K8s客户端，获取内置的clientSet，就可以获取环境中的ns列表，返回的是k8s原生的nslist结构
-	NamespaceList, err := K8sCli.ClientSet.CoreV1().Namespaces().List(context.TODO(), metaV1.ListOptions{})
+func (n *namespace) GetNameSpaces(cli *K8sClient, in *kubeDto.DataSelectInput) (nodesResp *NameSpaceResp, err error) {
+	query, err := NewDataSelectQuery(in, namespaceCell{})
+	if err != nil {
+		return nil, err
+	}
+	// 从集群的本地缓存中获取ns列表，返回的是k8s原生的ns结构
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	NamespaceList, err := cli.Informers.Core().V1().Namespaces().Lister().List(labels.Everything())
 	if err != nil {
 		return nil, errors.New("获取Namespace列表失败")
 	}
 
 	// 实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: n.toCells(NamespaceList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter:     &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{limit, page},
-		},
+		GenericDataList: n.toCells(NamespaceList),
+		DataSelect:      query,
 	}
 	// 先过滤
 	filtered := selectableData.Filter()
@@ -60,23 +68,30 @@ func (n *namespace) GetNameSpaces(filterName string, limit, page int) (nodesResp
 	data := filtered.Sort().Paginate()
 	// 将 dataCell列表 转换为 coreV1.Namespace列表
 	namespaces := n.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]NamespaceRow, len(namespaces))
+		for i := range namespaces {
+			rows[i] = toNamespaceRow(namespaces[i])
+		}
+		return &NameSpaceResp{Total: total, Rows: rows}, nil
+	}
 	// 返回的是封装好的响应体
 	return &NameSpaceResp{
-		total,
-		namespaces,
+		Total: total,
+		Items: namespaces,
 	}, nil
 }
 
 // GetNameSpacesDetail 获取Node详情
-func (n *namespace) GetNameSpacesDetail(Name string) (*coreV1.Namespace, error) {
-	namespacesRes, err := K8sCli.ClientSet.CoreV1().Namespaces().Get(context.TODO(), Name, metaV1.GetOptions{})
+func (n *namespace) GetNameSpacesDetail(cli *K8sClient, Name string) (*coreV1.Namespace, error) {
+	namespacesRes, err := cli.ClientSet.CoreV1().Namespaces().Get(context.TODO(), Name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return namespacesRes, nil
 }
 
-func (n *namespace) CreateNameSpace(name string) error {
+func (n *namespace) CreateNameSpace(cli *K8sClient, name string) error {
 	ns := &coreV1.Namespace{
 		TypeMeta: metaV1.TypeMeta{},
 		ObjectMeta: metaV1.ObjectMeta{
@@ -85,12 +100,12 @@ func (n *namespace) CreateNameSpace(name string) error {
 		Spec:   coreV1.NamespaceSpec{},
 		Status: coreV1.NamespaceStatus{},
 	}
-	if _, err := K8sCli.ClientSet.CoreV1().Namespaces().Create(context.TODO(), ns, metaV1.CreateOptions{}); err != nil {
+	if _, err := cli.ClientSet.CoreV1().Namespaces().Create(context.TODO(), ns, metaV1.CreateOptions{}); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (n *namespace) DeleteNameSpace(name string) error {
-	return K8sCli.ClientSet.CoreV1().Namespaces().Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (n *namespace) DeleteNameSpace(cli *K8sClient, name string) error {
+	return cli.ClientSet.CoreV1().Namespaces().Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
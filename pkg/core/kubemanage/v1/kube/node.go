@@ -2,8 +2,10 @@ package kube
 
 import (
 	"context"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 var Node node
@@ -13,12 +15,14 @@ type node struct{}
 type NodeResp struct {
 	Total int           `json:"total"`
 	Items []coreV1.Node `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []NodeRow `json:"rows,omitempty"`
 }
 
-func (n *node) toCells(nodes []coreV1.Node) []DataCell {
+func (n *node) toCells(nodes []*coreV1.Node) []DataCell {
 	cells := make([]DataCell, len(nodes))
 	for i := range nodes {
-		cells[i] = nodeCell(nodes[i])
+		cells[i] = nodeCell(*nodes[i])
 	}
 	return cells
 }
@@ -31,18 +35,22 @@ func (n *node) FromCells(cells []DataCell) []coreV1.Node {
 	return nodes
 }
 
-func (n *node) GetNodes(filterName string, limit, page int) (nodesResp *NodeResp, err error) {
-	nodeList, err := K8sCli.ClientSet.CoreV1().Nodes().List(context.TODO(), metaV1.ListOptions{})
+func (n *node) GetNodes(cli *K8sClient, in *kubeDto.DataSelectInput) (nodesResp *NodeResp, err error) {
+	query, err := NewDataSelectQuery(in, nodeCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	nodeList, err := cli.Informers.Core().V1().Nodes().Lister().List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	//实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: n.toCells(nodeList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter:     &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{limit, page},
-		},
+		GenericDataList: n.toCells(nodeList),
+		DataSelect:      query,
 	}
 	//先过滤
 	filtered := selectableData.Filter()
@@ -51,15 +59,22 @@ func (n *node) GetNodes(filterName string, limit, page int) (nodesResp *NodeResp
 	data := filtered.Sort().Paginate()
 	//将dataCell类型转换为coreV1.Pod
 	nodes := n.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]NodeRow, len(nodes))
+		for i := range nodes {
+			rows[i] = toNodeRow(nodes[i])
+		}
+		return &NodeResp{Total: total, Rows: rows}, nil
+	}
 	return &NodeResp{
-		total,
-		nodes,
+		Total: total,
+		Items: nodes,
 	}, nil
 }
 
 // GetNodeDetail 获取Node详情
-func (n *node) GetNodeDetail(Name string) (*coreV1.Node, error) {
-	nodeRes, err := K8sCli.ClientSet.CoreV1().Nodes().Get(context.TODO(), Name, metaV1.GetOptions{})
+func (n *node) GetNodeDetail(cli *K8sClient, Name string) (*coreV1.Node, error) {
+	nodeRes, err := cli.ClientSet.CoreV1().Nodes().Get(context.TODO(), Name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -3,8 +3,11 @@ package kube
 import (
 	"context"
 	"encoding/json"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var PersistentVolumeClaim persistentVolumeClaim
@@ -15,6 +18,8 @@ type persistentVolumeClaim struct {
 type PersistentVolumeClaimResp struct {
 	Total int                            `json:"total"`
 	Items []coreV1.PersistentVolumeClaim `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []PersistentVolumeClaimRow `json:"rows,omitempty"`
 }
 
 type PersistentVolumeClaimNp struct {
@@ -22,10 +27,10 @@ type PersistentVolumeClaimNp struct {
 	PersistentVolumeClaimNum int    `json:"PersistentVolumeClaim_num"`
 }
 
-func (d *persistentVolumeClaim) toCells(PersistentVolumeClaims []coreV1.PersistentVolumeClaim) []DataCell {
+func (d *persistentVolumeClaim) toCells(PersistentVolumeClaims []*coreV1.PersistentVolumeClaim) []DataCell {
 	cells := make([]DataCell, len(PersistentVolumeClaims))
 	for i := range PersistentVolumeClaims {
-		cells[i] = persistentVolumeClaimCell(PersistentVolumeClaims[i])
+		cells[i] = persistentVolumeClaimCell(*PersistentVolumeClaims[i])
 	}
 	return cells
 }
@@ -38,48 +43,58 @@ func (d *persistentVolumeClaim) FromCells(cells []DataCell) []coreV1.PersistentV
 	return PersistentVolumeClaims
 }
 
-func (d *persistentVolumeClaim) DeletePersistentVolumeClaim(name, namespace string) error {
-	return K8sCli.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (d *persistentVolumeClaim) DeletePersistentVolumeClaim(cli *K8sClient, name, namespace string) error {
+	return cli.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
 
-func (d *persistentVolumeClaim) UpdatePersistentVolumeClaim(content, namespace string) error {
+func (d *persistentVolumeClaim) UpdatePersistentVolumeClaim(cli *K8sClient, content, namespace string, opt kubeDto.DryRunInput) (*Preview, error) {
 	var PersistentVolumeClaim = &coreV1.PersistentVolumeClaim{}
 	if err := json.Unmarshal([]byte(content), PersistentVolumeClaim); err != nil {
-		return err
-	}
-	if _, err := K8sCli.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), PersistentVolumeClaim, metaV1.UpdateOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.CoreV1().PersistentVolumeClaims(namespace)
+	return updateWithPreview(opt, PersistentVolumeClaim, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), PersistentVolumeClaim.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), PersistentVolumeClaim, opts)
+	})
 }
 
-func (d *persistentVolumeClaim) GetPersistentVolumeClaims(filterName, namespace string, limit, page int) (*PersistentVolumeClaimResp, error) {
-	PersistentVolumeClaimList, err := K8sCli.ClientSet.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (d *persistentVolumeClaim) GetPersistentVolumeClaims(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*PersistentVolumeClaimResp, error) {
+	query, err := NewDataSelectQuery(in, persistentVolumeClaimCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	PersistentVolumeClaimList, err := cli.Informers.Core().V1().PersistentVolumeClaims().Lister().PersistentVolumeClaims(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	selectableData := &dataSelector{
-		GenericDataList: d.toCells(PersistentVolumeClaimList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: d.toCells(PersistentVolumeClaimList),
+		DataSelect:      query,
 	}
 	filterd := selectableData.Filter()
 	total := len(filterd.GenericDataList)
 	data := filterd.Sort().Paginate()
 	PersistentVolumeClaims := d.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]PersistentVolumeClaimRow, len(PersistentVolumeClaims))
+		for i := range PersistentVolumeClaims {
+			rows[i] = toPersistentVolumeClaimRow(PersistentVolumeClaims[i])
+		}
+		return &PersistentVolumeClaimResp{Total: total, Rows: rows}, nil
+	}
 	return &PersistentVolumeClaimResp{
 		Total: total,
 		Items: PersistentVolumeClaims,
 	}, nil
 }
 
-func (d *persistentVolumeClaim) GetPersistentVolumeClaimDetail(name, namespace string) (*coreV1.PersistentVolumeClaim, error) {
-	data, err := K8sCli.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+func (d *persistentVolumeClaim) GetPersistentVolumeClaimDetail(cli *K8sClient, name, namespace string) (*coreV1.PersistentVolumeClaim, error) {
+	data, err := cli.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
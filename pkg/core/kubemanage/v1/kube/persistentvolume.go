@@ -2,9 +2,11 @@ package kube
 
 import (
 	"context"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	"github.com/pkg/errors"
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 var PersistentVolume persistentVolume
@@ -14,12 +16,14 @@ type persistentVolume struct{}
 type PersistentVolumeResp struct {
 	Total int                       `json:"total"`
 	Items []coreV1.PersistentVolume `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []PersistentVolumeRow `json:"rows,omitempty"`
 }
 
-func (n *persistentVolume) toCells(pvs []coreV1.PersistentVolume) []DataCell {
+func (n *persistentVolume) toCells(pvs []*coreV1.PersistentVolume) []DataCell {
 	cells := make([]DataCell, len(pvs))
 	for i := range pvs {
-		cells[i] = persistentvolumesCell(pvs[i])
+		cells[i] = persistentvolumesCell(*pvs[i])
 	}
 	return cells
 }
@@ -32,18 +36,22 @@ func (n *persistentVolume) FromCells(cells []DataCell) []coreV1.PersistentVolume
 	return nodes
 }
 
-func (n *persistentVolume) GetPersistentVolumes(filterName string, limit, page int) (*PersistentVolumeResp, error) {
-	PersistentVolumeList, err := K8sCli.ClientSet.CoreV1().PersistentVolumes().List(context.TODO(), metaV1.ListOptions{})
+func (n *persistentVolume) GetPersistentVolumes(cli *K8sClient, in *kubeDto.DataSelectInput) (*PersistentVolumeResp, error) {
+	query, err := NewDataSelectQuery(in, persistentvolumesCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	PersistentVolumeList, err := cli.Informers.Core().V1().PersistentVolumes().Lister().List(labels.Everything())
 	if err != nil {
 		return nil, errors.New("获取Pod列表失败")
 	}
 	//实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: n.toCells(PersistentVolumeList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter:     &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{limit, page},
-		},
+		GenericDataList: n.toCells(PersistentVolumeList),
+		DataSelect:      query,
 	}
 	//先过滤
 	filtered := selectableData.Filter()
@@ -52,21 +60,28 @@ func (n *persistentVolume) GetPersistentVolumes(filterName string, limit, page i
 	data := filtered.Sort().Paginate()
 	//将dataCell类型转换为coreV1.Pod
 	PersistentVolumes := n.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]PersistentVolumeRow, len(PersistentVolumes))
+		for i := range PersistentVolumes {
+			rows[i] = toPersistentVolumeRow(PersistentVolumes[i])
+		}
+		return &PersistentVolumeResp{Total: total, Rows: rows}, nil
+	}
 	return &PersistentVolumeResp{
-		total,
-		PersistentVolumes,
+		Total: total,
+		Items: PersistentVolumes,
 	}, nil
 }
 
 // GetPersistentVolumesDetail 获取PersistentVolume详情
-func (n *persistentVolume) GetPersistentVolumesDetail(Name string) (*coreV1.PersistentVolume, error) {
-	PersistentVolumesRes, err := K8sCli.ClientSet.CoreV1().PersistentVolumes().Get(context.TODO(), Name, metaV1.GetOptions{})
+func (n *persistentVolume) GetPersistentVolumesDetail(cli *K8sClient, Name string) (*coreV1.PersistentVolume, error) {
+	PersistentVolumesRes, err := cli.ClientSet.CoreV1().PersistentVolumes().Get(context.TODO(), Name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return PersistentVolumesRes, nil
 }
 
-func (n *persistentVolume) DeletePersistentVolume(name string) error {
-	return K8sCli.ClientSet.CoreV1().PersistentVolumes().Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (n *persistentVolume) DeletePersistentVolume(cli *K8sClient, name string) error {
+	return cli.ClientSet.CoreV1().PersistentVolumes().Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
@@ -1,34 +1,49 @@
 package kube
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	coreV1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 
+	"github.com/noovertime7/kubemanage/cmd/app/config"
 	"github.com/noovertime7/kubemanage/dao"
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	"github.com/noovertime7/kubemanage/pkg/logger"
 	"github.com/noovertime7/kubemanage/pkg/types"
 )
 
+// defaultWebShells 配置文件未设置webshell.shells时web终端依次尝试的shell
+var defaultWebShells = []string{"bash", "sh", "ash", "zsh"}
+
 type PodsGetter interface {
-	Pods(cloud string) PodInterface
+	Pods(cloud string) (PodInterface, error)
 }
 
 type PodInterface interface {
-	WebShellHandler(webShellOptions *kubeDto.WebShellOptions, w http.ResponseWriter, r *http.Request) error
+	WebShellHandler(webShellOptions *kubeDto.WebShellOptions, meta *types.TerminalSessionMeta, recorder types.SessionRecorder, guard *types.CommandGuard, w http.ResponseWriter, r *http.Request) error
+	ListFiles(in *kubeDto.PodFileInput) ([]ContainerFile, error)
+	DownloadFile(in *kubeDto.PodFileInput, w io.Writer) error
+	UploadFile(in *kubeDto.PodFileInput, filename string, size int64, r io.Reader) error
 }
 
 type pods struct {
-	client  *kubernetes.Clientset
+	client  *K8sClient
 	cloud   string
 	factory dao.ShareDaoFactory
 }
 
-func NewPods(c *kubernetes.Clientset, cloud string, factory dao.ShareDaoFactory) *pods {
+func NewPods(c *K8sClient, cloud string, factory dao.ShareDaoFactory) *pods {
 	return &pods{
 		client:  c,
 		cloud:   cloud,
@@ -36,7 +51,9 @@ func NewPods(c *kubernetes.Clientset, cloud string, factory dao.ShareDaoFactory)
 	}
 }
 
-func (c *pods) WebShellHandler(webShellOptions *kubeDto.WebShellOptions, w http.ResponseWriter, r *http.Request) error {
+// WebShellHandler 会话建立后登记到 types.TerminalSessions 中，meta的ID和Container在登记时填充；
+// recorder不为空时记录会话的输入输出，录像写入失败时结束会话；guard不为空时按命令策略拦截危险命令
+func (c *pods) WebShellHandler(webShellOptions *kubeDto.WebShellOptions, meta *types.TerminalSessionMeta, recorder types.SessionRecorder, guard *types.CommandGuard, w http.ResponseWriter, r *http.Request) error {
 	log := logger.New()
 	session, err := types.NewTerminalSession(w, r)
 	if err != nil {
@@ -46,40 +63,190 @@ func (c *pods) WebShellHandler(webShellOptions *kubeDto.WebShellOptions, w http.
 	defer func() {
 		_ = session.Close()
 	}()
+	if recorder != nil {
+		session.SetRecorder(recorder)
+	}
+	if guard != nil {
+		session.SetGuard(guard)
+	}
 
-	// 组装 POST 请求
-	req := K8sCli.ClientSet.CoreV1().RESTClient().Post().
-		Resource("pods").
-		Name(webShellOptions.Pod).
-		Namespace(webShellOptions.Namespace).
-		SubResource("exec").
-		VersionedParams(&coreV1.PodExecOptions{
-			Container: webShellOptions.Container,
-			Command:   []string{"/bin/sh"},
-			Stderr:    true,
-			Stdin:     true,
-			Stdout:    true,
-			TTY:       true,
-		}, scheme.ParameterCodec)
-
-	// remotecommand 主要实现了http 转 SPDY 添加X-Stream-Protocol-Version相关header 并发送请求
-	executor, err := remotecommand.NewSPDYExecutor(K8sCli.Config, "POST", req.URL())
-	if err != nil {
-		log.ErrorWithErr("remotecommand pod error", err)
-		return err
+	// 未指定容器时解析出实际使用的容器，便于审计
+	code := types.TerminalErrExecFailed
+	webShellOptions.Container, err = c.defaultContainer(webShellOptions.Namespace, webShellOptions.Pod, webShellOptions.Container)
+	if err == nil {
+		meta.Container = webShellOptions.Container
+		if err = types.TerminalSessions.Register(session, meta, WebShellLimits()); err != nil {
+			code = types.TerminalErrSessionLimit
+		} else {
+			defer types.TerminalSessions.Remove(meta.ID)
+			// 推送会话id，用于共享会话和移交控制权
+			_ = session.WriteMessage("session", meta.ID)
+		}
+	}
+	var executor remotecommand.Executor
+	if err == nil {
+		executor, code, err = c.shellExecutor(webShellOptions)
 	}
-	// 与 kubelet 建立 stream 连接
-	if err = executor.Stream(remotecommand.StreamOptions{
-		Stdout:            session,
-		Stdin:             session,
-		Stderr:            session,
-		TerminalSizeQueue: session,
-		Tty:               true,
-	}); err != nil {
+	if err == nil {
+		// 与 kubelet 建立 stream 连接
+		code = types.TerminalErrExecFailed
+		err = executor.Stream(remotecommand.StreamOptions{
+			Stdout:            session,
+			Stdin:             session,
+			Stderr:            session,
+			TerminalSizeQueue: session,
+			Tty:               true,
+		})
+	}
+	if err != nil {
 		log.ErrorWithErr("exec pod error", err)
-		_, _ = session.Write([]byte("exec pod command failed," + err.Error()))
+		_ = session.WriteError(code, err.Error())
 		// 标记关闭terminal
 		session.Done()
 	}
 	return nil
 }
+
+// shellExecutor 创建web终端使用的executor，失败时同时返回推送给web终端的错误码；
+// 临时调试容器本身运行着shell，通过attach连接，其他容器通过exec执行自定义命令或探测到的shell
+func (c *pods) shellExecutor(webShellOptions *kubeDto.WebShellOptions) (remotecommand.Executor, string, error) {
+	if webShellOptions.Container != "" {
+		pod, err := c.client.ClientSet.CoreV1().Pods(webShellOptions.Namespace).Get(context.TODO(), webShellOptions.Pod, metaV1.GetOptions{})
+		if err != nil {
+			return nil, types.TerminalErrExecFailed, err
+		}
+		if isEphemeralContainer(pod, webShellOptions.Container) {
+			executor, err := c.executor(webShellOptions.Namespace, webShellOptions.Pod, "attach", &coreV1.PodAttachOptions{
+				Container: webShellOptions.Container,
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+				TTY:       true,
+			})
+			return executor, types.TerminalErrExecFailed, err
+		}
+	}
+	command, code, err := c.shellCommand(webShellOptions)
+	if err != nil {
+		return nil, code, err
+	}
+	executor, err := c.executor(webShellOptions.Namespace, webShellOptions.Pod, "exec", &coreV1.PodExecOptions{
+		Container: webShellOptions.Container,
+		Command:   command,
+		Stderr:    true,
+		Stdin:     true,
+		Stdout:    true,
+		TTY:       true,
+	})
+	return executor, types.TerminalErrExecFailed, err
+}
+
+// shellCommand 确定web终端执行的命令：自定义命令需要在允许列表中；未指定命令时按配置的顺序探测容器中可用的shell，
+// 在打开终端之前探测，避免失败的终端会话读取web端的输入
+func (c *pods) shellCommand(webShellOptions *kubeDto.WebShellOptions) ([]string, string, error) {
+	if command := strings.Fields(webShellOptions.Command); len(command) != 0 {
+		if !WebShellCommandAllowed(command) {
+			return nil, types.TerminalErrCommandNotAllowed, fmt.Errorf("命令 %s 不在允许列表中", strings.Join(command, " "))
+		}
+		return command, "", nil
+	}
+	shells := WebShells()
+	for _, shell := range shells {
+		err := c.probeShell(webShellOptions, shell)
+		if err == nil {
+			return []string{shell}, "", nil
+		}
+		// 容器不存在、未运行等错误换一个shell也无法解决
+		if !isCommandNotFound(err) {
+			return nil, types.TerminalErrExecFailed, err
+		}
+	}
+	return nil, types.TerminalErrShellNotFound, fmt.Errorf("容器中没有可用的shell，已尝试 %s", strings.Join(shells, "、"))
+}
+
+// probeShell 不分配tty执行 shell -c "exit 0"，判断容器中是否存在该shell
+func (c *pods) probeShell(webShellOptions *kubeDto.WebShellOptions, shell string) error {
+	executor, err := c.executor(webShellOptions.Namespace, webShellOptions.Pod, "exec", &coreV1.PodExecOptions{
+		Container: webShellOptions.Container,
+		Command:   []string{shell, "-c", "exit 0"},
+		Stdout:    true,
+		Stderr:    true,
+	})
+	if err != nil {
+		return err
+	}
+	return executor.Stream(remotecommand.StreamOptions{
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+	})
+}
+
+// WebShells 获取web终端依次尝试的shell，未配置时使用 bash、sh、ash、zsh
+func WebShells() []string {
+	if config.SysConfig != nil && len(config.SysConfig.WebShell.Shells) != 0 {
+		return config.SysConfig.WebShell.Shells
+	}
+	return defaultWebShells
+}
+
+// WebShellLimits 获取配置文件中web终端的并发限制和空闲超时
+func WebShellLimits() types.TerminalLimits {
+	if config.SysConfig == nil {
+		return types.TerminalLimits{}
+	}
+	return types.TerminalLimits{
+		MaxSessions:        config.SysConfig.WebShell.MaxSessions,
+		MaxSessionsPerUser: config.SysConfig.WebShell.MaxSessionsPerUser,
+		IdleTimeout:        time.Duration(config.SysConfig.WebShell.IdleTimeout) * time.Second,
+	}
+}
+
+// WebShellCommandAllowed 自定义命令是否在配置的允许列表中，命令及全部参数都需要一致，
+// 只比较命令名时允许 sh 就等于允许 sh -c 执行任意命令
+func WebShellCommandAllowed(command []string) bool {
+	if config.SysConfig == nil {
+		return false
+	}
+	for _, allowed := range config.SysConfig.WebShell.AllowedCommands {
+		if equalArgs(strings.Fields(allowed), command) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalArgs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isCommandNotFound 执行失败是否因为容器中不存在该命令
+func isCommandNotFound(err error) bool {
+	var exitErr utilexec.CodeExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code == 126 || exitErr.Code == 127
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "executable file not found") || strings.Contains(msg, "no such file or directory")
+}
+
+// executor 创建在容器中执行命令(exec)或连接容器主进程(attach)的executor
+func (c *pods) executor(namespace, pod, subResource string, opts runtime.Object) (remotecommand.Executor, error) {
+	// 组装 POST 请求
+	req := c.client.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(namespace).
+		SubResource(subResource).
+		VersionedParams(opts, scheme.ParameterCodec)
+
+	// remotecommand 主要实现了http 转 SPDY 添加X-Stream-Protocol-Version相关header 并发送请求
+	return remotecommand.NewSPDYExecutor(c.client.Config, "POST", req.URL())
+}
@@ -0,0 +1,190 @@
+package kube
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// DefaultContainerAnnotation 指定pod默认容器的注解，与kubectl保持一致
+const DefaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// 容器内文件的类型
+const (
+	FileTypeFile    = "file"
+	FileTypeDir     = "dir"
+	FileTypeSymlink = "symlink"
+	FileTypeOther   = "other"
+)
+
+// listFilesScript 列出目录下的文件，目录通过位置参数传入，避免拼接命令；依赖容器中的stat命令，GNU coreutils与busybox均支持
+const listFilesScript = `cd -- "$1" || exit 1
+for f in * .*; do
+	case "$f" in .|..) continue;; esac
+	[ -e "$f" ] || [ -L "$f" ] || continue
+	stat -c '%F|%s|%a|%Y|%n' -- "$f"
+done`
+
+// uploadScript 在目标目录中解压标准输入的tar包，目录不存在时自动创建
+const uploadScript = `mkdir -p -- "$1" && tar -xmf - -C "$1"`
+
+// ContainerFile 容器内目录中的一项
+type ContainerFile struct {
+	Name string `json:"name"`
+	// Type 取值为 file、dir、symlink、other
+	Type    string    `json:"type"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// ListFiles 列出容器内目录下的文件，目录排在前面
+func (c *pods) ListFiles(in *kubeDto.PodFileInput) ([]ContainerFile, error) {
+	var stdout bytes.Buffer
+	if err := c.execFile(in, []string{"sh", "-c", listFilesScript, "sh", in.Path}, nil, &stdout); err != nil {
+		return nil, err
+	}
+	files := make([]ContainerFile, 0)
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		if file, ok := parseStatLine(scanner.Text()); ok {
+			files = append(files, file)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if (files[i].Type == FileTypeDir) != (files[j].Type == FileTypeDir) {
+			return files[i].Type == FileTypeDir
+		}
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// DownloadFile 将容器内的文件或目录打包为tar写入w，与kubectl cp一样依赖容器中的tar命令
+func (c *pods) DownloadFile(in *kubeDto.PodFileInput, w io.Writer) error {
+	dir, name := path.Split(path.Clean(in.Path))
+	if name == "" || name == "/" {
+		return errors.New("不能下载根目录")
+	}
+	return c.execFile(in, []string{"tar", "-cf", "-", "-C", dir, name}, nil, w)
+}
+
+// UploadFile 将r中的内容以filename为文件名上传到容器内的目录中，边读取边打包，不在内存中缓存文件
+func (c *pods) UploadFile(in *kubeDto.PodFileInput, filename string, size int64, r io.Reader) error {
+	name := path.Base(filename)
+	if name == "." || name == ".." || name == "/" {
+		return fmt.Errorf("文件名 %s 不合法", filename)
+	}
+	reader, writer := io.Pipe()
+	// exec提前结束时关闭reader，使打包的goroutine退出
+	defer reader.Close()
+	go func() {
+		tw := tar.NewWriter(writer)
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     size,
+			Mode:     0644,
+			ModTime:  time.Now(),
+		})
+		if err == nil {
+			_, err = io.CopyN(tw, r, size)
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		_ = writer.CloseWithError(err)
+	}()
+	return c.execFile(in, []string{"sh", "-c", uploadScript, "sh", path.Clean(in.Path)}, reader, io.Discard)
+}
+
+// execFile 在容器中执行命令，失败时错误信息中附带命令的标准错误输出
+func (c *pods) execFile(in *kubeDto.PodFileInput, command []string, stdin io.Reader, stdout io.Writer) error {
+	container, err := c.defaultContainer(in.NameSpace, in.PodName, in.ContainerName)
+	if err != nil {
+		return err
+	}
+	executor, err := c.executor(in.NameSpace, in.PodName, "exec", &coreV1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    true,
+		Stderr:    true,
+	})
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	}); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// defaultContainer 未指定容器时，优先使用注解指定的默认容器，否则使用第一个容器
+func (c *pods) defaultContainer(namespace, podName, container string) (string, error) {
+	if container != "" {
+		return container, nil
+	}
+	pod, err := c.client.ClientSet.CoreV1().Pods(namespace).Get(context.TODO(), podName, metaV1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if name := pod.Annotations[DefaultContainerAnnotation]; name != "" {
+		return name, nil
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return "", fmt.Errorf("pod %s 中没有容器", podName)
+	}
+	return pod.Spec.Containers[0].Name, nil
+}
+
+// parseStatLine 解析 stat -c '%F|%s|%a|%Y|%n' 的输出，文件名中可能包含分隔符，因此放在最后
+func parseStatLine(line string) (ContainerFile, bool) {
+	parts := strings.SplitN(line, "|", 5)
+	if len(parts) != 5 {
+		return ContainerFile{}, false
+	}
+	size, _ := strconv.ParseInt(parts[1], 10, 64)
+	modTime, _ := strconv.ParseInt(parts[3], 10, 64)
+	file := ContainerFile{
+		Name:    parts[4],
+		Size:    size,
+		Mode:    parts[2],
+		ModTime: time.Unix(modTime, 0),
+	}
+	switch {
+	case parts[0] == "directory":
+		file.Type = FileTypeDir
+	case parts[0] == "symbolic link":
+		file.Type = FileTypeSymlink
+	case strings.HasPrefix(parts[0], "regular"):
+		// GNU stat 对空文件输出 regular empty file
+		file.Type = FileTypeFile
+	default:
+		file.Type = FileTypeOther
+	}
+	return file, true
+}
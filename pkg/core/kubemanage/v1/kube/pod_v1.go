@@ -8,6 +8,10 @@ import (
 
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 )
 
 var Pod pod
@@ -17,6 +21,8 @@ type pod struct{}
 type PodsResp struct {
 	Total int          `json:"total"`
 	Items []coreV1.Pod `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []PodRow `json:"rows,omitempty"`
 }
 
 type PodsNp struct {
@@ -25,18 +31,22 @@ type PodsNp struct {
 }
 
 // GetPods 获取pod列表支持、过滤、排序以及分页
-func (p *pod) GetPods(filterName, namespace string, limit, page int) (podsResp *PodsResp, err error) {
-	podlist, err := K8sCli.ClientSet.CoreV1().Pods(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (p *pod) GetPods(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (podsResp *PodsResp, err error) {
+	query, err := NewDataSelectQuery(in, podCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	podlist, err := cli.Informers.Core().V1().Pods().Lister().Pods(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	//实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: p.toCells(podlist.Items),
-		DataSelect: &DataSelectQuery{
-			Filter:     &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{limit, page},
-		},
+		GenericDataList: p.toCells(podlist),
+		DataSelect:      query,
 	}
 	//先过滤
 	filtered := selectableData.Filter()
@@ -45,15 +55,22 @@ func (p *pod) GetPods(filterName, namespace string, limit, page int) (podsResp *
 	data := filtered.Sort().Paginate()
 	//将dataCell类型转换为coreV1.Pod
 	pods := p.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]PodRow, len(pods))
+		for i := range pods {
+			rows[i] = toPodRow(pods[i])
+		}
+		return &PodsResp{Total: total, Rows: rows}, nil
+	}
 	return &PodsResp{
-		total,
-		pods,
+		Total: total,
+		Items: pods,
 	}, nil
 }
 
 // GetPodDetail 获取Pod详情
-func (p *pod) GetPodDetail(podName, namespace string) (pod *coreV1.Pod, err error) {
-	podRes, err := K8sCli.ClientSet.CoreV1().Pods(namespace).Get(context.TODO(), podName, metaV1.GetOptions{})
+func (p *pod) GetPodDetail(cli *K8sClient, podName, namespace string) (pod *coreV1.Pod, err error) {
+	podRes, err := cli.ClientSet.CoreV1().Pods(namespace).Get(context.TODO(), podName, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -61,31 +78,32 @@ func (p *pod) GetPodDetail(podName, namespace string) (pod *coreV1.Pod, err erro
 }
 
 // DeletePod 删除Pod
-func (p *pod) DeletePod(podName, namespace string) error {
-	err := K8sCli.ClientSet.CoreV1().Pods(namespace).Delete(context.TODO(), podName, metaV1.DeleteOptions{})
+func (p *pod) DeletePod(cli *K8sClient, podName, namespace string) error {
+	err := cli.ClientSet.CoreV1().Pods(namespace).Delete(context.TODO(), podName, metaV1.DeleteOptions{})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// UpdatePod 更新Pod
-func (p *pod) UpdatePod(namespace, content string) error {
+// UpdatePod 更新Pod，预览模式下只返回变更差异
+func (p *pod) UpdatePod(cli *K8sClient, namespace, content string, opt kubeDto.DryRunInput) (*Preview, error) {
 	var pod = &coreV1.Pod{}
 	//将json反序列换为pod类型
 	if err := json.Unmarshal([]byte(content), pod); err != nil {
-		return err
-	}
-	_, err := K8sCli.ClientSet.CoreV1().Pods(namespace).Update(context.TODO(), pod, metaV1.UpdateOptions{})
-	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.CoreV1().Pods(namespace)
+	return updateWithPreview(opt, pod, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), pod.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), pod, opts)
+	})
 }
 
 // GetPodContainer 获取Pod容器名
-func (p *pod) GetPodContainer(podName, namespace string) (containers []string, err error) {
-	pod, err := p.GetPodDetail(podName, namespace)
+func (p *pod) GetPodContainer(cli *K8sClient, podName, namespace string) (containers []string, err error) {
+	pod, err := p.GetPodDetail(cli, podName, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -97,15 +115,15 @@ func (p *pod) GetPodContainer(podName, namespace string) (containers []string, e
 }
 
 // GetPodLog 获取容器日志
-func (p *pod) GetPodLog(containerName, podName, namespace string) (log string, err error) {
-	//设置日志的配置，容器名，获取的内容的配置
-	lineLimit := int64(100)
+func (p *pod) GetPodLog(cli *K8sClient, containerName, podName, namespace string) (log string, err error) {
+	//设置日志的配置，容器名，获取的内容的配置，行数取自配置文件的podLogTailLine
+	lineLimit := DefaultTailLines()
 	op := &coreV1.PodLogOptions{
 		Container: containerName,
 		TailLines: &lineLimit,
 	}
 	//获取request的实例
-	req := K8sCli.ClientSet.CoreV1().Pods(namespace).GetLogs(podName, op)
+	req := cli.ClientSet.CoreV1().Pods(namespace).GetLogs(podName, op)
 	//发起stream连接，得到response.body
 	podLogs, err := req.Stream(context.TODO())
 	if err != nil {
@@ -126,20 +144,23 @@ func (p *pod) GetPodLog(containerName, podName, namespace string) (log string, e
 }
 
 // GetPodNumPerNp 获取namespace下的Pod数量
-func (p *pod) GetPodNumPerNp() (podsNps []*PodsNp, err error) {
-	namespaceList, err := K8sCli.ClientSet.CoreV1().Namespaces().List(context.TODO(), metaV1.ListOptions{})
+func (p *pod) GetPodNumPerNp(cli *K8sClient) (podsNps []*PodsNp, err error) {
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	namespaceList, err := cli.Informers.Core().V1().Namespaces().Lister().List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
-	for _, namespace := range namespaceList.Items {
-		podList, err := K8sCli.ClientSet.CoreV1().Pods(namespace.Name).List(context.TODO(), metaV1.ListOptions{})
+	for _, namespace := range namespaceList {
+		podList, err := cli.Informers.Core().V1().Pods().Lister().Pods(namespace.Name).List(labels.Everything())
 		if err != nil {
 			return nil, err
 		}
 		//组装数据
 		podsNp := &PodsNp{
 			Namespace: namespace.Name,
-			PodNum:    len(podList.Items),
+			PodNum:    len(podList),
 		}
 		podsNps = append(podsNps, podsNp)
 	}
@@ -147,10 +168,10 @@ func (p *pod) GetPodNumPerNp() (podsNps []*PodsNp, err error) {
 }
 
 // 类型转换的方法 coreV1.pod => DataCell,DataCell => coreV1.pod
-func (p *pod) toCells(pods []coreV1.Pod) []DataCell {
+func (p *pod) toCells(pods []*coreV1.Pod) []DataCell {
 	cells := make([]DataCell, len(pods))
 	for i := range pods {
-		cells[i] = podCell(pods[i])
+		cells[i] = podCell(*pods[i])
 	}
 	return cells
 }
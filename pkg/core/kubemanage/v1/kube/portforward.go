@@ -0,0 +1,134 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// portForwardErrorTimeout 数据流结束后等待错误流结束的最长时间
+const portForwardErrorTimeout = 5 * time.Second
+
+// PortForward 全局变量，用于建立到pod端口的转发连接
+var PortForward portForward
+
+type portForward struct{}
+
+// PortForwardConn 通过SPDY建立的到pod端口的单个TCP连接
+type PortForwardConn struct {
+	conn    httpstream.Connection
+	data    httpstream.Stream
+	errCh   chan error
+	errOnce sync.Once
+	err     error
+}
+
+// Dial 与apiserver建立SPDY连接，并创建到pod端口的错误流和数据流，与kubectl port-forward的单个连接一致
+func (p *portForward) Dial(cli *K8sClient, namespace, podName string, port int32) (*PortForwardConn, error) {
+	pod, err := cli.ClientSet.CoreV1().Pods(namespace).Get(context.TODO(), podName, metaV1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if pod.Status.Phase != coreV1.PodRunning {
+		return nil, fmt.Errorf("pod %s 未处于运行状态，当前状态为 %s", podName, pod.Status.Phase)
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(cli.Config)
+	if err != nil {
+		return nil, err
+	}
+	req := cli.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+	conn, protocol, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, err
+	}
+	if protocol != portforward.PortForwardProtocolV1Name {
+		_ = conn.Close()
+		return nil, fmt.Errorf("apiserver不支持端口转发协议 %s", portforward.PortForwardProtocolV1Name)
+	}
+
+	headers := http.Header{}
+	headers.Set(coreV1.StreamType, coreV1.StreamTypeError)
+	headers.Set(coreV1.PortHeader, strconv.Itoa(int(port)))
+	headers.Set(coreV1.PortForwardRequestIDHeader, "0")
+	errorStream, err := conn.CreateStream(headers)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	// 错误流只用于读取
+	_ = errorStream.Close()
+	errCh := make(chan error, 1)
+	go func() {
+		message, err := io.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			errCh <- fmt.Errorf("读取端口 %d 的错误流失败: %v", port, err)
+		case len(message) > 0:
+			errCh <- fmt.Errorf("转发端口 %d 失败: %s", port, message)
+		}
+		close(errCh)
+	}()
+
+	headers.Set(coreV1.StreamType, coreV1.StreamTypeData)
+	dataStream, err := conn.CreateStream(headers)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &PortForwardConn{conn: conn, data: dataStream, errCh: errCh}, nil
+}
+
+// Read pod端关闭连接后错误流可能晚于数据流结束，读到EOF时先等待错误流结束，避免调用方随后关闭连接导致错误丢失
+func (c *PortForwardConn) Read(p []byte) (int, error) {
+	n, err := c.data.Read(p)
+	if err == io.EOF {
+		c.waitErr()
+	}
+	return n, err
+}
+
+func (c *PortForwardConn) Write(p []byte) (int, error) {
+	return c.data.Write(p)
+}
+
+// CloseWrite 关闭写方向，通知pod端不会再有数据写入
+func (c *PortForwardConn) CloseWrite() error {
+	return c.data.Close()
+}
+
+// Close 关闭整个SPDY连接
+func (c *PortForwardConn) Close() error {
+	return c.conn.Close()
+}
+
+// Err 返回pod端通过错误流返回的错误，例如端口没有监听，没有错误时返回nil；需要在数据转发结束后调用
+func (c *PortForwardConn) Err() error {
+	c.waitErr()
+	return c.err
+}
+
+// waitErr 等待错误流结束并保存其中的错误，最多等待 portForwardErrorTimeout
+func (c *PortForwardConn) waitErr() {
+	c.errOnce.Do(func() {
+		select {
+		case c.err = <-c.errCh:
+		case <-time.After(portForwardErrorTimeout):
+		}
+	})
+}
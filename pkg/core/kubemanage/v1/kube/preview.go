@@ -0,0 +1,198 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
+)
+
+// 差异的类型
+const (
+	ChangeAdd     = "add"
+	ChangeRemove  = "remove"
+	ChangeReplace = "replace"
+)
+
+// Preview dry-run时返回的变更预览，对比时忽略managedFields和status
+type Preview struct {
+	Changes []Change `json:"changes"`
+	// Unified unified格式的yaml差异，仅在请求unified_diff时返回
+	Unified string `json:"unified,omitempty"`
+}
+
+// Change 单个字段的变化，Path 形如 spec.template.spec.containers[0].image
+type Change struct {
+	Path string      `json:"path"`
+	Op   string      `json:"op"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// ErrResourceVersionRequired 更新内容中缺少resourceVersion，无法进行并发冲突检查
+var ErrResourceVersionRequired = errors.New("更新内容缺少metadata.resourceVersion，请基于最新版本修改后提交")
+
+// ConflictError 提交的resourceVersion不是最新版本，Live为线上最新对象，供前端做三方合并
+type ConflictError struct {
+	Live runtime.Object
+	Err  error
+}
+
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// updateWithPreview 按obj的resourceVersion执行乐观并发更新，预览模式下以DryRun: All提交并返回线上对象与更新结果的差异，非预览模式返回nil，
+// 版本冲突时返回包含线上最新对象的ConflictError
+func updateWithPreview(opt kubeDto.DryRunInput, obj metaV1.Object, get func() (runtime.Object, error), update func(metaV1.UpdateOptions) (runtime.Object, error)) (*Preview, error) {
+	if obj.GetResourceVersion() == "" {
+		return nil, ErrResourceVersionRequired
+	}
+	options := metaV1.UpdateOptions{}
+	if opt.DryRun {
+		options.DryRun = []string{metaV1.DryRunAll}
+	}
+	result, err := update(options)
+	if apiErrors.IsConflict(err) {
+		live, getErr := get()
+		if getErr != nil {
+			return nil, err
+		}
+		return nil, &ConflictError{Live: live, Err: err}
+	}
+	if err != nil || !opt.DryRun {
+		return nil, err
+	}
+	// dry-run不会修改集群，此时的线上对象即为更新前的对象
+	live, err := get()
+	if err != nil {
+		return nil, err
+	}
+	return NewPreview(live, result, opt.UnifiedDiff)
+}
+
+// NewPreview 计算两个对象的差异，live为nil时表示对象将被创建
+func NewPreview(live, result runtime.Object, unified bool) (*Preview, error) {
+	liveMap, err := toComparable(live)
+	if err != nil {
+		return nil, err
+	}
+	resultMap, err := toComparable(result)
+	if err != nil {
+		return nil, err
+	}
+	preview := &Preview{Changes: []Change{}}
+	diffValue("", liveMap, resultMap, &preview.Changes)
+	if unified && len(preview.Changes) != 0 {
+		if preview.Unified, err = unifiedDiff(liveMap, resultMap); err != nil {
+			return nil, err
+		}
+	}
+	return preview, nil
+}
+
+// toComparable 将对象转换为map，并去掉managedFields和status
+func toComparable(obj runtime.Object) (map[string]interface{}, error) {
+	if obj == nil || reflect.ValueOf(obj).IsNil() {
+		return map[string]interface{}{}, nil
+	}
+	out, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj.DeepCopyObject())
+	if err != nil {
+		return nil, err
+	}
+	delete(out, "status")
+	if metadata, ok := out["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+	}
+	return out, nil
+}
+
+// diffValue 递归比较两个值，map按key比较，数组按下标比较
+func diffValue(path string, old, new interface{}, changes *[]Change) {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make([]string, 0, len(oldMap)+len(newMap))
+		for k := range oldMap {
+			keys = append(keys, k)
+		}
+		for k := range newMap {
+			if _, ok := oldMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			o, inOld := oldMap[k]
+			n, inNew := newMap[k]
+			switch {
+			case !inOld:
+				*changes = append(*changes, Change{Path: childPath, Op: ChangeAdd, New: n})
+			case !inNew:
+				*changes = append(*changes, Change{Path: childPath, Op: ChangeRemove, Old: o})
+			default:
+				diffValue(childPath, o, n, changes)
+			}
+		}
+		return
+	}
+
+	oldSlice, oldIsSlice := old.([]interface{})
+	newSlice, newIsSlice := new.([]interface{})
+	if oldIsSlice && newIsSlice {
+		for i := 0; i < len(oldSlice) || i < len(newSlice); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(oldSlice):
+				*changes = append(*changes, Change{Path: childPath, Op: ChangeAdd, New: newSlice[i]})
+			case i >= len(newSlice):
+				*changes = append(*changes, Change{Path: childPath, Op: ChangeRemove, Old: oldSlice[i]})
+			default:
+				diffValue(childPath, oldSlice[i], newSlice[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		*changes = append(*changes, Change{Path: path, Op: ChangeReplace, Old: old, New: new})
+	}
+}
+
+// unifiedDiff 生成两个对象yaml之间的unified格式差异
+func unifiedDiff(old, new map[string]interface{}) (string, error) {
+	oldYaml, err := yaml.Marshal(old)
+	if err != nil {
+		return "", err
+	}
+	if len(old) == 0 {
+		oldYaml = nil
+	}
+	newYaml, err := yaml.Marshal(new)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(oldYaml)),
+		B:        difflib.SplitLines(string(newYaml)),
+		FromFile: "live",
+		ToFile:   "dry-run",
+		Context:  3,
+	})
+}
@@ -0,0 +1,75 @@
+package kube
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"strings"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// 可以代理的资源类型
+const (
+	ProxyPods     = "pods"
+	ProxyServices = "services"
+)
+
+// Proxy 全局变量，用于通过apiserver反向代理到pod或service的端口
+var Proxy proxy
+
+type proxy struct{}
+
+// NewReverseProxy 通过apiserver的proxy子资源反向代理到pod或service的端口，prefix为本服务上对应的路径前缀，
+// 请求路径中prefix之后的部分转发到目标端口，目标返回的重定向地址会改写回prefix下；
+// 请求中用于认证本服务的token不会转发给目标，目标返回的页面在沙箱中运行，与本服务的页面隔离
+func (p *proxy) NewReverseProxy(cli *K8sClient, kind, namespace, name, port, prefix string) (*httputil.ReverseProxy, error) {
+	transport, err := rest.TransportFor(cli.Config)
+	if err != nil {
+		return nil, err
+	}
+	target := cli.ClientSet.CoreV1().RESTClient().Get().
+		Resource(kind).
+		Namespace(namespace).
+		Name(name + ":" + port).
+		SubResource("proxy").
+		URL()
+	targetPath := strings.TrimSuffix(target.Path, "/")
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	return &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.URL.Path = targetPath + strings.TrimPrefix(req.URL.Path, prefix)
+			req.URL.RawPath = ""
+			req.Host = target.Host
+			req.Header.Del("token")
+			removeCookie(req, "token")
+			removeCookie(req, pkg.ProxyTicketCookie)
+		},
+		Transport: transport,
+		ModifyResponse: func(resp *http.Response) error {
+			if location := resp.Header.Get("Location"); strings.HasPrefix(location, targetPath) {
+				resp.Header.Set("Location", prefix+strings.TrimPrefix(location, targetPath))
+			}
+			// 目标页面与本服务同源，需要放到沙箱中，避免页面中的脚本读取本服务的token或调用本服务的接口
+			resp.Header.Add("Content-Security-Policy", "sandbox")
+			// 代理地址中包含票据，不允许目标页面通过Referer泄露给其他站点
+			resp.Header.Set("Referrer-Policy", "no-referrer")
+			return nil
+		},
+	}, nil
+}
+
+// removeCookie 从请求中删除指定名称的cookie，保留其他cookie
+func removeCookie(req *http.Request, name string) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name != name {
+			req.AddCookie(cookie)
+		}
+	}
+}
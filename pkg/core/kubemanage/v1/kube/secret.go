@@ -3,8 +3,11 @@ package kube
 import (
 	"context"
 	"encoding/json"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var Secret secret
@@ -15,6 +18,8 @@ type secret struct {
 type SecretResp struct {
 	Total int             `json:"total"`
 	Items []coreV1.Secret `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []SecretRow `json:"rows,omitempty"`
 }
 
 type SecretNp struct {
@@ -22,10 +27,10 @@ type SecretNp struct {
 	SecretNum int    `json:"secret_num"`
 }
 
-func (d *secret) toCells(secrets []coreV1.Secret) []DataCell {
+func (d *secret) toCells(secrets []*coreV1.Secret) []DataCell {
 	cells := make([]DataCell, len(secrets))
 	for i := range secrets {
-		cells[i] = secretCell(secrets[i])
+		cells[i] = secretCell(*secrets[i])
 	}
 	return cells
 }
@@ -38,50 +43,60 @@ func (d *secret) FromCells(cells []DataCell) []coreV1.Secret {
 	return secrets
 }
 
-func (d *secret) GetSecrets(filterName, namespace string, limit, page int) (*SecretResp, error) {
-	SecretsList, err := K8sCli.ClientSet.CoreV1().Secrets(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (d *secret) GetSecrets(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*SecretResp, error) {
+	query, err := NewDataSelectQuery(in, secretCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	SecretsList, err := cli.Informers.Core().V1().Secrets().Lister().Secrets(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	selectableData := &dataSelector{
-		GenericDataList: d.toCells(SecretsList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: d.toCells(SecretsList),
+		DataSelect:      query,
 	}
 	filterd := selectableData.Filter()
 	total := len(filterd.GenericDataList)
 	data := filterd.Sort().Paginate()
 	secrets := d.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]SecretRow, len(secrets))
+		for i := range secrets {
+			rows[i] = toSecretRow(secrets[i])
+		}
+		return &SecretResp{Total: total, Rows: rows}, nil
+	}
 	return &SecretResp{
 		Total: total,
 		Items: secrets,
 	}, nil
 }
 
-func (d *secret) GetSecretsDetail(name, namespace string) (*coreV1.Secret, error) {
-	data, err := K8sCli.ClientSet.CoreV1().Secrets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+func (d *secret) GetSecretsDetail(cli *K8sClient, name, namespace string) (*coreV1.Secret, error) {
+	data, err := cli.ClientSet.CoreV1().Secrets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (d *secret) DeleteSecrets(name, namespace string) error {
-	return K8sCli.ClientSet.CoreV1().Secrets(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (d *secret) DeleteSecrets(cli *K8sClient, name, namespace string) error {
+	return cli.ClientSet.CoreV1().Secrets(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
 
-func (d *secret) UpdateSecrets(content, namespace string) error {
+func (d *secret) UpdateSecrets(cli *K8sClient, content, namespace string, opt kubeDto.DryRunInput) (*Preview, error) {
 	var secret = &coreV1.Secret{}
 	if err := json.Unmarshal([]byte(content), secret); err != nil {
-		return err
-	}
-	if _, err := K8sCli.ClientSet.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metaV1.UpdateOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.CoreV1().Secrets(namespace)
+	return updateWithPreview(opt, secret, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), secret.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), secret, opts)
+	})
 }
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
@@ -17,6 +19,8 @@ type service struct{}
 type serviceResp struct {
 	Total int              `json:"total"`
 	Items []coreV1.Service `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []ServiceRow `json:"rows,omitempty"`
 }
 
 type serviceNp struct {
@@ -24,10 +28,10 @@ type serviceNp struct {
 	ServiceNum int    `json:"service_num"`
 }
 
-func (s *service) toCells(services []coreV1.Service) []DataCell {
+func (s *service) toCells(services []*coreV1.Service) []DataCell {
 	cells := make([]DataCell, len(services))
 	for i := range services {
-		cells[i] = serviceCell(services[i])
+		cells[i] = serviceCell(*services[i])
 	}
 	return cells
 }
@@ -40,7 +44,7 @@ func (s *service) FromCells(cells []DataCell) []coreV1.Service {
 	return services
 }
 
-func (s *service) CreateService(data *kubeDto.ServiceCreateInput) error {
+func (s *service) CreateService(cli *K8sClient, data *kubeDto.ServiceCreateInput) error {
 	service := &coreV1.Service{
 		ObjectMeta: metaV1.ObjectMeta{
 			Name:      data.Name,
@@ -68,39 +72,45 @@ func (s *service) CreateService(data *kubeDto.ServiceCreateInput) error {
 		service.Spec.Ports[0].NodePort = data.NodePort
 	}
 	//创建service
-	if _, err := K8sCli.ClientSet.CoreV1().Services(data.NameSpace).Create(context.TODO(), service, metaV1.CreateOptions{}); err != nil {
+	if _, err := cli.ClientSet.CoreV1().Services(data.NameSpace).Create(context.TODO(), service, metaV1.CreateOptions{}); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *service) DeleteService(name, namespace string) error {
-	return K8sCli.ClientSet.CoreV1().Services(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (s *service) DeleteService(cli *K8sClient, name, namespace string) error {
+	return cli.ClientSet.CoreV1().Services(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
 
-func (s *service) UpdateService(namespace, content string) error {
+func (s *service) UpdateService(cli *K8sClient, namespace, content string, opt kubeDto.DryRunInput) (*Preview, error) {
 	var Service = &coreV1.Service{}
 	if err := json.Unmarshal([]byte(content), Service); err != nil {
-		return err
-	}
-	if _, err := K8sCli.ClientSet.CoreV1().Services(namespace).Update(context.TODO(), Service, metaV1.UpdateOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.CoreV1().Services(namespace)
+	return updateWithPreview(opt, Service, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), Service.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), Service, opts)
+	})
 }
 
-func (s *service) GetServiceList(filterName, namespace string, limit, page int) (*serviceResp, error) {
-	ServiceList, err := K8sCli.ClientSet.CoreV1().Services(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (s *service) GetServiceList(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*serviceResp, error) {
+	query, err := NewDataSelectQuery(in, serviceCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	ServiceList, err := cli.Informers.Core().V1().Services().Lister().Services(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	//实例化dataSelector结构体，组装数据
 	selectableData := &dataSelector{
-		GenericDataList: s.toCells(ServiceList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter:     &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{limit, page},
-		},
+		GenericDataList: s.toCells(ServiceList),
+		DataSelect:      query,
 	}
 	//先过滤
 	filtered := selectableData.Filter()
@@ -109,35 +119,45 @@ func (s *service) GetServiceList(filterName, namespace string, limit, page int)
 	data := filtered.Sort().Paginate()
 	//将dataCell类型转换为coreV1.Pod
 	Services := s.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]ServiceRow, len(Services))
+		for i := range Services {
+			rows[i] = toServiceRow(Services[i])
+		}
+		return &serviceResp{Total: total, Rows: rows}, nil
+	}
 	return &serviceResp{
-		total,
-		Services,
+		Total: total,
+		Items: Services,
 	}, nil
 }
 
-func (s *service) GetServiceDetail(name, namespace string) (*coreV1.Service, error) {
-	data, err := K8sCli.ClientSet.CoreV1().Services(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+func (s *service) GetServiceDetail(cli *K8sClient, name, namespace string) (*coreV1.Service, error) {
+	data, err := cli.ClientSet.CoreV1().Services(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (s *service) GetServiceNp() ([]*serviceNp, error) {
-	namespaceList, err := K8sCli.ClientSet.CoreV1().Namespaces().List(context.TODO(), metaV1.ListOptions{})
+func (s *service) GetServiceNp(cli *K8sClient) ([]*serviceNp, error) {
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	namespaceList, err := cli.Informers.Core().V1().Namespaces().Lister().List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	var services []*serviceNp
-	for _, namespace := range namespaceList.Items {
-		serviceList, err := K8sCli.ClientSet.CoreV1().Services(namespace.Name).List(context.TODO(), metaV1.ListOptions{})
+	for _, namespace := range namespaceList {
+		serviceList, err := cli.Informers.Core().V1().Services().Lister().Services(namespace.Name).List(labels.Everything())
 		if err != nil {
 			return nil, err
 		}
 		//组装数据
 		ServiceNp := &serviceNp{
 			NameSpace:  namespace.Name,
-			ServiceNum: len(serviceList.Items),
+			ServiceNum: len(serviceList),
 		}
 		services = append(services, ServiceNp)
 	}
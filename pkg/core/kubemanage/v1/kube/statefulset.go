@@ -3,8 +3,11 @@ package kube
 import (
 	"context"
 	"encoding/json"
+	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	appsV1 "k8s.io/api/apps/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var StatefulSet statefulSet
@@ -14,6 +17,8 @@ type statefulSet struct{}
 type statefulSetResp struct {
 	Total int                  `json:"total"`
 	Items []appsV1.StatefulSet `json:"items"`
+	// Rows view=table时返回的摘要行，此时Items为空
+	Rows []StatefulSetRow `json:"rows,omitempty"`
 }
 
 type StatefulSetNp struct {
@@ -21,10 +26,10 @@ type StatefulSetNp struct {
 	DaemonSetNum int    `json:"daemonset_num"`
 }
 
-func (d *statefulSet) toCells(statefulSets []appsV1.StatefulSet) []DataCell {
+func (d *statefulSet) toCells(statefulSets []*appsV1.StatefulSet) []DataCell {
 	cells := make([]DataCell, len(statefulSets))
 	for i := range statefulSets {
-		cells[i] = statefulSetCell(statefulSets[i])
+		cells[i] = statefulSetCell(*statefulSets[i])
 	}
 	return cells
 }
@@ -37,50 +42,60 @@ func (d *statefulSet) FromCells(cells []DataCell) []appsV1.StatefulSet {
 	return statefulSets
 }
 
-func (d *statefulSet) GetStatefulSets(filterName, namespace string, limit, page int) (*statefulSetResp, error) {
-	statefulSetList, err := K8sCli.ClientSet.AppsV1().StatefulSets(namespace).List(context.TODO(), metaV1.ListOptions{})
+func (d *statefulSet) GetStatefulSets(cli *K8sClient, namespace string, in *kubeDto.DataSelectInput) (*statefulSetResp, error) {
+	query, err := NewDataSelectQuery(in, statefulSetCell{})
+	if err != nil {
+		return nil, err
+	}
+	if err := cli.CacheSynced(); err != nil {
+		return nil, err
+	}
+	statefulSetList, err := cli.Informers.Apps().V1().StatefulSets().Lister().StatefulSets(namespace).List(labels.Everything())
 	if err != nil {
 		return nil, err
 	}
 	selectableData := &dataSelector{
-		GenericDataList: d.toCells(statefulSetList.Items),
-		DataSelect: &DataSelectQuery{
-			Filter: &FilterQuery{Name: filterName},
-			Paginatite: &PaginateQuery{
-				Limit: limit,
-				Page:  page,
-			},
-		},
+		GenericDataList: d.toCells(statefulSetList),
+		DataSelect:      query,
 	}
 	filterd := selectableData.Filter()
 	total := len(filterd.GenericDataList)
 	data := filterd.Sort().Paginate()
 	statefulSets := d.FromCells(data.GenericDataList)
+	if in.View == kubeDto.ViewTable {
+		rows := make([]StatefulSetRow, len(statefulSets))
+		for i := range statefulSets {
+			rows[i] = toStatefulSetRow(statefulSets[i])
+		}
+		return &statefulSetResp{Total: total, Rows: rows}, nil
+	}
 	return &statefulSetResp{
 		Total: total,
 		Items: statefulSets,
 	}, nil
 }
 
-func (d *statefulSet) GetStatefulSetDetail(name, namespace string) (*appsV1.StatefulSet, error) {
-	data, err := K8sCli.ClientSet.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+func (d *statefulSet) GetStatefulSetDetail(cli *K8sClient, name, namespace string) (*appsV1.StatefulSet, error) {
+	data, err := cli.ClientSet.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
-func (d *statefulSet) DeleteStatefulSet(name, namespace string) error {
-	return K8sCli.ClientSet.AppsV1().StatefulSets(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
+func (d *statefulSet) DeleteStatefulSet(cli *K8sClient, name, namespace string) error {
+	return cli.ClientSet.AppsV1().StatefulSets(namespace).Delete(context.TODO(), name, metaV1.DeleteOptions{})
 }
 
-func (d *statefulSet) UpdateStatefulSet(content, namespace string) error {
+func (d *statefulSet) UpdateStatefulSet(cli *K8sClient, content, namespace string, opt kubeDto.DryRunInput) (*Preview, error) {
 	var statefulSet = &appsV1.StatefulSet{}
 	if err := json.Unmarshal([]byte(content), statefulSet); err != nil {
-		return err
-	}
-	if _, err := K8sCli.ClientSet.AppsV1().StatefulSets(namespace).Update(context.TODO(), statefulSet, metaV1.UpdateOptions{}); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	client := cli.ClientSet.AppsV1().StatefulSets(namespace)
+	return updateWithPreview(opt, statefulSet, func() (runtime.Object, error) {
+		return client.Get(context.TODO(), statefulSet.Name, metaV1.GetOptions{})
+	}, func(opts metaV1.UpdateOptions) (runtime.Object, error) {
+		return client.Update(context.TODO(), statefulSet, opts)
+	})
 }
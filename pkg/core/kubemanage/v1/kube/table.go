@@ -0,0 +1,494 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsV1 "k8s.io/api/apps/v1"
+	autoscalingV2 "k8s.io/api/autoscaling/v2"
+	batchV1 "k8s.io/api/batch/v1"
+	coreV1 "k8s.io/api/core/v1"
+	nwV1 "k8s.io/api/networking/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// 以下为 view=table 时各资源返回的摘要行，字段与kubectl get的输出列保持一致
+
+type PodRow struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Ready     string `json:"ready"`
+	Status    string `json:"status"`
+	Restarts  int64  `json:"restarts"`
+	Age       string `json:"age"`
+	Node      string `json:"node"`
+	IP        string `json:"ip"`
+}
+
+type DeploymentRow struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Ready     string   `json:"ready"`
+	UpToDate  int32    `json:"up_to_date"`
+	Available int32    `json:"available"`
+	Age       string   `json:"age"`
+	Images    []string `json:"images"`
+}
+
+type DaemonSetRow struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Desired   int32  `json:"desired"`
+	Current   int32  `json:"current"`
+	Ready     int32  `json:"ready"`
+	UpToDate  int32  `json:"up_to_date"`
+	Available int32  `json:"available"`
+	Age       string `json:"age"`
+}
+
+type StatefulSetRow struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Ready     string `json:"ready"`
+	Age       string `json:"age"`
+}
+
+type JobRow struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	Status      string `json:"status"`
+	Completions string `json:"completions"`
+	Duration    string `json:"duration"`
+	Age         string `json:"age"`
+}
+
+type CronJobRow struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Schedule     string `json:"schedule"`
+	Suspend      bool   `json:"suspend"`
+	Active       int    `json:"active"`
+	LastSchedule string `json:"last_schedule"`
+	Age          string `json:"age"`
+}
+
+type HPARow struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Reference string `json:"reference"`
+	Targets   string `json:"targets"`
+	MinPods   int32  `json:"min_pods"`
+	MaxPods   int32  `json:"max_pods"`
+	Replicas  int32  `json:"replicas"`
+	Age       string `json:"age"`
+}
+
+type NodeRow struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Roles      string `json:"roles"`
+	Age        string `json:"age"`
+	Version    string `json:"version"`
+	InternalIP string `json:"internal_ip"`
+}
+
+type NamespaceRow struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Age    string `json:"age"`
+}
+
+type ServiceRow struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Type       string `json:"type"`
+	ClusterIP  string `json:"cluster_ip"`
+	ExternalIP string `json:"external_ip"`
+	Ports      string `json:"ports"`
+	Age        string `json:"age"`
+}
+
+type IngressRow struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Class     string `json:"class"`
+	Hosts     string `json:"hosts"`
+	Address   string `json:"address"`
+	Age       string `json:"age"`
+}
+
+type ConfigmapRow struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Data      int    `json:"data"`
+	Age       string `json:"age"`
+}
+
+type SecretRow struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	Data      int    `json:"data"`
+	Age       string `json:"age"`
+}
+
+type PersistentVolumeRow struct {
+	Name          string `json:"name"`
+	Capacity      string `json:"capacity"`
+	AccessModes   string `json:"access_modes"`
+	ReclaimPolicy string `json:"reclaim_policy"`
+	Status        string `json:"status"`
+	Claim         string `json:"claim"`
+	StorageClass  string `json:"storage_class"`
+	Age           string `json:"age"`
+}
+
+type PersistentVolumeClaimRow struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Status       string `json:"status"`
+	Volume       string `json:"volume"`
+	Capacity     string `json:"capacity"`
+	AccessModes  string `json:"access_modes"`
+	StorageClass string `json:"storage_class"`
+	Age          string `json:"age"`
+}
+
+func toPodRow(pod coreV1.Pod) PodRow {
+	ready := 0
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Ready {
+			ready++
+		}
+	}
+	return PodRow{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Ready:     fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+		Status:    podDisplayStatus(pod),
+		Restarts:  podRestarts(pod),
+		Age:       translateAge(pod.CreationTimestamp),
+		Node:      pod.Spec.NodeName,
+		IP:        pod.Status.PodIP,
+	}
+}
+
+// podDisplayStatus 计算与kubectl get pod一致的STATUS列
+func podDisplayStatus(pod coreV1.Pod) string {
+	reason := string(pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		reason = pod.Status.Reason
+	}
+
+	initializing := false
+	for i, cs := range pod.Status.InitContainerStatuses {
+		switch {
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0:
+			continue
+		case cs.State.Terminated != nil:
+			if cs.State.Terminated.Reason != "" {
+				reason = "Init:" + cs.State.Terminated.Reason
+			} else {
+				reason = fmt.Sprintf("Init:ExitCode:%d", cs.State.Terminated.ExitCode)
+			}
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + cs.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing {
+		for i := len(pod.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			cs := pod.Status.ContainerStatuses[i]
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+				reason = cs.State.Waiting.Reason
+			} else if cs.State.Terminated != nil && cs.State.Terminated.Reason != "" {
+				reason = cs.State.Terminated.Reason
+			}
+		}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		reason = "Terminating"
+	}
+	return reason
+}
+
+func toDeploymentRow(deploy appsV1.Deployment) DeploymentRow {
+	images := make([]string, 0, len(deploy.Spec.Template.Spec.Containers))
+	for _, c := range deploy.Spec.Template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	replicas := int32(1)
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	return DeploymentRow{
+		Name:      deploy.Name,
+		Namespace: deploy.Namespace,
+		Ready:     fmt.Sprintf("%d/%d", deploy.Status.ReadyReplicas, replicas),
+		UpToDate:  deploy.Status.UpdatedReplicas,
+		Available: deploy.Status.AvailableReplicas,
+		Age:       translateAge(deploy.CreationTimestamp),
+		Images:    images,
+	}
+}
+
+func toDaemonSetRow(ds appsV1.DaemonSet) DaemonSetRow {
+	return DaemonSetRow{
+		Name:      ds.Name,
+		Namespace: ds.Namespace,
+		Desired:   ds.Status.DesiredNumberScheduled,
+		Current:   ds.Status.CurrentNumberScheduled,
+		Ready:     ds.Status.NumberReady,
+		UpToDate:  ds.Status.UpdatedNumberScheduled,
+		Available: ds.Status.NumberAvailable,
+		Age:       translateAge(ds.CreationTimestamp),
+	}
+}
+
+func toStatefulSetRow(sts appsV1.StatefulSet) StatefulSetRow {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	return StatefulSetRow{
+		Name:      sts.Name,
+		Namespace: sts.Namespace,
+		Ready:     fmt.Sprintf("%d/%d", sts.Status.ReadyReplicas, replicas),
+		Age:       translateAge(sts.CreationTimestamp),
+	}
+}
+
+func toJobRow(job batchV1.Job) JobRow {
+	return JobRow{
+		Name:        job.Name,
+		Namespace:   job.Namespace,
+		Status:      jobStatus(job),
+		Completions: fmt.Sprintf("%d/%d", job.Status.Succeeded, jobCompletions(job)),
+		Duration:    jobDuration(job),
+		Age:         translateAge(job.CreationTimestamp),
+	}
+}
+
+func toCronJobRow(cronJob batchV1.CronJob) CronJobRow {
+	lastSchedule := "<none>"
+	if cronJob.Status.LastScheduleTime != nil {
+		lastSchedule = translateAge(*cronJob.Status.LastScheduleTime)
+	}
+	return CronJobRow{
+		Name:         cronJob.Name,
+		Namespace:    cronJob.Namespace,
+		Schedule:     cronJob.Spec.Schedule,
+		Suspend:      cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+		Active:       len(cronJob.Status.Active),
+		LastSchedule: lastSchedule,
+		Age:          translateAge(cronJob.CreationTimestamp),
+	}
+}
+
+func toHPARow(hpa autoscalingV2.HorizontalPodAutoscaler) HPARow {
+	var minPods int32 = 1
+	if hpa.Spec.MinReplicas != nil {
+		minPods = *hpa.Spec.MinReplicas
+	}
+	return HPARow{
+		Name:      hpa.Name,
+		Namespace: hpa.Namespace,
+		Reference: hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name,
+		Targets:   hpaTargets(hpa),
+		MinPods:   minPods,
+		MaxPods:   hpa.Spec.MaxReplicas,
+		Replicas:  hpa.Status.CurrentReplicas,
+		Age:       translateAge(hpa.CreationTimestamp),
+	}
+}
+
+func toNodeRow(node coreV1.Node) NodeRow {
+	status := strings.Join(nodeCell(node).GetStatus(), ",")
+	var roles []string
+	for k := range node.Labels {
+		if strings.HasPrefix(k, "node-role.kubernetes.io/") {
+			roles = append(roles, strings.TrimPrefix(k, "node-role.kubernetes.io/"))
+		}
+	}
+	sort.Strings(roles)
+	internalIP := ""
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == coreV1.NodeInternalIP {
+			internalIP = addr.Address
+			break
+		}
+	}
+	return NodeRow{
+		Name:       node.Name,
+		Status:     status,
+		Roles:      noneIfEmpty(strings.Join(roles, ",")),
+		Age:        translateAge(node.CreationTimestamp),
+		Version:    node.Status.NodeInfo.KubeletVersion,
+		InternalIP: noneIfEmpty(internalIP),
+	}
+}
+
+func toNamespaceRow(ns coreV1.Namespace) NamespaceRow {
+	return NamespaceRow{
+		Name:   ns.Name,
+		Status: string(ns.Status.Phase),
+		Age:    translateAge(ns.CreationTimestamp),
+	}
+}
+
+func toServiceRow(svc coreV1.Service) ServiceRow {
+	// 拷贝后再追加，避免写入informer缓存中对象的底层数组
+	externalIPs := append([]string(nil), svc.Spec.ExternalIPs...)
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		if ing.IP != "" {
+			externalIPs = append(externalIPs, ing.IP)
+		} else if ing.Hostname != "" {
+			externalIPs = append(externalIPs, ing.Hostname)
+		}
+	}
+	ports := make([]string, 0, len(svc.Spec.Ports))
+	for _, p := range svc.Spec.Ports {
+		if p.NodePort != 0 {
+			ports = append(ports, fmt.Sprintf("%d:%d/%s", p.Port, p.NodePort, p.Protocol))
+		} else {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.Port, p.Protocol))
+		}
+	}
+	return ServiceRow{
+		Name:       svc.Name,
+		Namespace:  svc.Namespace,
+		Type:       string(svc.Spec.Type),
+		ClusterIP:  noneIfEmpty(svc.Spec.ClusterIP),
+		ExternalIP: noneIfEmpty(strings.Join(externalIPs, ",")),
+		Ports:      noneIfEmpty(strings.Join(ports, ",")),
+		Age:        translateAge(svc.CreationTimestamp),
+	}
+}
+
+func toIngressRow(ing nwV1.Ingress) IngressRow {
+	class := ""
+	if ing.Spec.IngressClassName != nil {
+		class = *ing.Spec.IngressClassName
+	}
+	var hosts, address []string
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			address = append(address, lb.IP)
+		} else if lb.Hostname != "" {
+			address = append(address, lb.Hostname)
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = []string{"*"}
+	}
+	return IngressRow{
+		Name:      ing.Name,
+		Namespace: ing.Namespace,
+		Class:     noneIfEmpty(class),
+		Hosts:     strings.Join(hosts, ","),
+		Address:   strings.Join(address, ","),
+		Age:       translateAge(ing.CreationTimestamp),
+	}
+}
+
+func toConfigmapRow(cm coreV1.ConfigMap) ConfigmapRow {
+	return ConfigmapRow{
+		Name:      cm.Name,
+		Namespace: cm.Namespace,
+		Data:      len(cm.Data) + len(cm.BinaryData),
+		Age:       translateAge(cm.CreationTimestamp),
+	}
+}
+
+func toSecretRow(secret coreV1.Secret) SecretRow {
+	return SecretRow{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		Type:      string(secret.Type),
+		Data:      len(secret.Data),
+		Age:       translateAge(secret.CreationTimestamp),
+	}
+}
+
+func toPersistentVolumeRow(pv coreV1.PersistentVolume) PersistentVolumeRow {
+	claim := ""
+	if pv.Spec.ClaimRef != nil {
+		claim = pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+	}
+	return PersistentVolumeRow{
+		Name:          pv.Name,
+		Capacity:      pv.Spec.Capacity.Storage().String(),
+		AccessModes:   accessModesString(pv.Spec.AccessModes),
+		ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+		Status:        string(pv.Status.Phase),
+		Claim:         claim,
+		StorageClass:  pv.Spec.StorageClassName,
+		Age:           translateAge(pv.CreationTimestamp),
+	}
+}
+
+func toPersistentVolumeClaimRow(pvc coreV1.PersistentVolumeClaim) PersistentVolumeClaimRow {
+	storageClass := ""
+	if pvc.Spec.StorageClassName != nil {
+		storageClass = *pvc.Spec.StorageClassName
+	}
+	capacity := ""
+	if storage, ok := pvc.Status.Capacity[coreV1.ResourceStorage]; ok {
+		capacity = storage.String()
+	}
+	return PersistentVolumeClaimRow{
+		Name:         pvc.Name,
+		Namespace:    pvc.Namespace,
+		Status:       string(pvc.Status.Phase),
+		Volume:       pvc.Spec.VolumeName,
+		Capacity:     capacity,
+		AccessModes:  accessModesString(pvc.Status.AccessModes),
+		StorageClass: storageClass,
+		Age:          translateAge(pvc.CreationTimestamp),
+	}
+}
+
+// accessModesString 将访问模式转换为kubectl的缩写形式，如 RWO,ROX
+func accessModesString(modes []coreV1.PersistentVolumeAccessMode) string {
+	short := map[coreV1.PersistentVolumeAccessMode]string{
+		coreV1.ReadWriteOnce:    "RWO",
+		coreV1.ReadOnlyMany:     "ROX",
+		coreV1.ReadWriteMany:    "RWX",
+		coreV1.ReadWriteOncePod: "RWOP",
+	}
+	out := make([]string, 0, len(modes))
+	for _, m := range modes {
+		if s, ok := short[m]; ok {
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// translateAge 将创建时间转换为kubectl风格的存活时长，如 5d3h
+func translateAge(timestamp metaV1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
+
+func noneIfEmpty(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
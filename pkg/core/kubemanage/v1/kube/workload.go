@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// 工作负载类型
+const (
+	WorkloadDeployment  = "deployment"
+	WorkloadStatefulSet = "statefulset"
+	WorkloadDaemonSet   = "daemonset"
+)
+
+// WorkloadSelector 获取工作负载用于选择pod的标签选择器
+func WorkloadSelector(cli *K8sClient, kind, name, namespace string) (labels.Selector, error) {
+	var selector *metaV1.LabelSelector
+	switch kind {
+	case WorkloadDeployment:
+		deploy, err := cli.ClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = deploy.Spec.Selector
+	case WorkloadStatefulSet:
+		sts, err := cli.ClientSet.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = sts.Spec.Selector
+	case WorkloadDaemonSet:
+		ds, err := cli.ClientSet.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metaV1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = ds.Spec.Selector
+	default:
+		return nil, fmt.Errorf("不支持的工作负载类型: %s", kind)
+	}
+	return metaV1.LabelSelectorAsSelector(selector)
+}
@@ -0,0 +1,156 @@
+package sys
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/types"
+)
+
+type CommandPolicyServiceGetter interface {
+	CommandPolicy() CommandPolicyService
+}
+
+type CommandPolicyService interface {
+	GetPageList(ctx *gin.Context, in *dto.CommandPolicyListInput) (*dto.CommandPolicyListOutPut, error)
+	Create(ctx *gin.Context, in *dto.CommandPolicyInput) error
+	Update(ctx *gin.Context, in *dto.CommandPolicyUpdateInput) error
+	Delete(ctx *gin.Context, id int) error
+	// CommandRules 查询对namespace和角色生效的已启用策略，用于web终端的危险命令拦截
+	CommandRules(ctx context.Context, namespace string, authorityId uint) ([]types.CommandRule, error)
+	// RecordCommandEvent 将web终端中命中命令策略的命令写入操作记录，userID为输入该命令的用户
+	RecordCommandEvent(ctx *gin.Context, userID int, meta *types.TerminalSessionMeta, event types.CommandEvent) error
+}
+
+type commandPolicyService struct {
+	factory dao.ShareDaoFactory
+}
+
+func NewCommandPolicyService(factory dao.ShareDaoFactory) *commandPolicyService {
+	return &commandPolicyService{factory: factory}
+}
+
+func (c *commandPolicyService) GetPageList(ctx *gin.Context, in *dto.CommandPolicyListInput) (*dto.CommandPolicyListOutPut, error) {
+	list, total, err := c.factory.CommandPolicy().PageList(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CommandPolicyListOutPut{PolicyList: list, Total: total, PageInfo: in.PageInfo}, nil
+}
+
+func (c *commandPolicyService) Create(ctx *gin.Context, in *dto.CommandPolicyInput) error {
+	policy := &model.TerminalCommandPolicy{}
+	if err := fillCommandPolicy(policy, in); err != nil {
+		return err
+	}
+	return c.factory.CommandPolicy().Save(ctx, policy)
+}
+
+func (c *commandPolicyService) Update(ctx *gin.Context, in *dto.CommandPolicyUpdateInput) error {
+	policy, err := c.factory.CommandPolicy().Find(ctx, &model.TerminalCommandPolicy{ID: in.ID})
+	if err != nil {
+		return err
+	}
+	if err := fillCommandPolicy(policy, &in.CommandPolicyInput); err != nil {
+		return err
+	}
+	return c.factory.CommandPolicy().Save(ctx, policy)
+}
+
+func (c *commandPolicyService) Delete(ctx *gin.Context, id int) error {
+	return c.factory.CommandPolicy().Delete(ctx, id)
+}
+
+func (c *commandPolicyService) CommandRules(ctx context.Context, namespace string, authorityId uint) ([]types.CommandRule, error) {
+	list, err := c.factory.CommandPolicy().FindEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authority := strconv.Itoa(int(authorityId))
+	rules := make([]types.CommandRule, 0, len(list))
+	for _, policy := range list {
+		if !matchListItem(policy.Namespaces, namespace) || !matchListItem(policy.AuthorityIds, authority) {
+			continue
+		}
+		pattern, err := regexp.Compile(policy.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("命令策略 %s 的正则表达式错误: %v", policy.Name, err)
+		}
+		rules = append(rules, types.CommandRule{Name: policy.Name, Pattern: pattern, Action: policy.Action})
+	}
+	return rules, nil
+}
+
+// RecordCommandEvent 被拦截或取消的命令状态码为403
+func (c *commandPolicyService) RecordCommandEvent(ctx *gin.Context, userID int, meta *types.TerminalSessionMeta, event types.CommandEvent) error {
+	body, _ := json.Marshal(map[string]interface{}{
+		"session":   meta.ID,
+		"cluster":   meta.Cluster,
+		"namespace": meta.Namespace,
+		"pod":       meta.Pod,
+		"container": meta.Container,
+		"command":   event.Command,
+		"rule":      event.Rule,
+		"action":    event.Action,
+		"outcome":   event.Outcome,
+	})
+	record := &model.SysOperationRecord{
+		Ip:     ctx.ClientIP(),
+		Method: ctx.Request.Method,
+		Path:   ctx.Request.URL.Path,
+		Agent:  ctx.Request.UserAgent(),
+		Body:   string(body),
+		Status: http.StatusOK,
+		UserID: userID,
+	}
+	if event.Outcome != types.CommandOutcomeConfirmed {
+		record.Status = http.StatusForbidden
+		record.ErrorMessage = fmt.Sprintf("命令被web终端命令策略 %s 拦截", event.Rule)
+		if event.Outcome == types.CommandOutcomeCancelled {
+			record.ErrorMessage = fmt.Sprintf("用户取消执行命中web终端命令策略 %s 的命令", event.Rule)
+		}
+	}
+	return c.factory.Opera().Save(ctx, record)
+}
+
+// fillCommandPolicy 校验正则表达式后将入参写入策略
+func fillCommandPolicy(policy *model.TerminalCommandPolicy, in *dto.CommandPolicyInput) error {
+	if _, err := regexp.Compile(in.Pattern); err != nil {
+		return fmt.Errorf("正则表达式错误: %v", err)
+	}
+	authorityIds := make([]string, 0, len(in.AuthorityIds))
+	for _, id := range in.AuthorityIds {
+		authorityIds = append(authorityIds, strconv.Itoa(int(id)))
+	}
+	policy.Name = in.Name
+	policy.Pattern = in.Pattern
+	policy.Action = in.Action
+	policy.Namespaces = strings.Join(in.Namespaces, ",")
+	policy.AuthorityIds = strings.Join(authorityIds, ",")
+	policy.Enabled = in.Enabled
+	policy.Description = in.Description
+	return nil
+}
+
+// matchListItem list为逗号分隔的列表，为空时匹配所有值
+func matchListItem(list, item string) bool {
+	if strings.TrimSpace(list) == "" {
+		return true
+	}
+	for _, v := range strings.Split(list, ",") {
+		if strings.TrimSpace(v) == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package sys
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/noovertime7/kubemanage/dao"
+	"github.com/noovertime7/kubemanage/dao/model"
+	"github.com/noovertime7/kubemanage/dto"
+	"github.com/noovertime7/kubemanage/pkg/recording"
+)
+
+type RecordingServiceGetter interface {
+	Recording() RecordingService
+}
+
+type RecordingService interface {
+	// Start 在存储后端创建录像并写入索引，会话结束后需要调用 Finish
+	Start(ctx context.Context, record *model.TerminalRecording) (*recording.Recorder, error)
+	// Finish 关闭录像，并在索引中记录录像大小和会话时长
+	Finish(ctx context.Context, record *model.TerminalRecording, recorder *recording.Recorder) error
+	GetPageList(ctx *gin.Context, in *dto.RecordingListInput) (*dto.RecordingListOutPut, error)
+	// Open 读取录像的原始内容，调用方负责关闭
+	Open(ctx *gin.Context, id int) (*model.TerminalRecording, io.ReadCloser, error)
+	// Play 分页解析录像，用于前端按时间回放
+	Play(ctx *gin.Context, id int, in *dto.RecordingPlayInput) (*recording.Cast, error)
+}
+
+type recordingService struct {
+	factory dao.ShareDaoFactory
+}
+
+func NewRecordingService(factory dao.ShareDaoFactory) *recordingService {
+	return &recordingService{factory: factory}
+}
+
+func (r *recordingService) Start(ctx context.Context, record *model.TerminalRecording) (*recording.Recorder, error) {
+	store := recording.DefaultStore()
+	record.StartedAt = time.Now()
+	record.Storage = store.Name()
+	record.StorageKey = fmt.Sprintf("%s/%s.cast", record.StartedAt.Format("2006/01/02"), uuid.NewV4())
+	w, err := store.Create(record.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("创建会话录像失败: %v", err)
+	}
+	if err := r.factory.Recording().Save(ctx, record); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	title := fmt.Sprintf("%s@%s %s/%s/%s", record.Username, record.Cluster, record.Namespace, record.Pod, record.Container)
+	return recording.NewRecorder(w, title), nil
+}
+
+func (r *recordingService) Finish(ctx context.Context, record *model.TerminalRecording, recorder *recording.Recorder) error {
+	closeErr := recorder.Close()
+	finishedAt := time.Now()
+	record.Size = recorder.Size()
+	record.Duration = recorder.Duration().Seconds()
+	record.FinishedAt = &finishedAt
+	if err := r.factory.Recording().Save(ctx, record); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func (r *recordingService) GetPageList(ctx *gin.Context, in *dto.RecordingListInput) (*dto.RecordingListOutPut, error) {
+	list, total, err := r.factory.Recording().PageList(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RecordingListOutPut{RecordingList: list, Total: total, PageInfo: in.PageInfo}, nil
+}
+
+func (r *recordingService) Open(ctx *gin.Context, id int) (*model.TerminalRecording, io.ReadCloser, error) {
+	record, err := r.factory.Recording().Find(ctx, &model.TerminalRecording{ID: id})
+	if err != nil {
+		return nil, nil, err
+	}
+	store := recording.DefaultStore()
+	if record.Storage != store.Name() {
+		return nil, nil, fmt.Errorf("录像保存在存储后端 %s 中，当前存储后端为 %s", record.Storage, store.Name())
+	}
+	reader, err := store.Open(record.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return record, reader, nil
+}
+
+func (r *recordingService) Play(ctx *gin.Context, id int, in *dto.RecordingPlayInput) (*recording.Cast, error) {
+	_, reader, err := r.Open(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return recording.Parse(reader, in.Offset, in.Limit)
+}
@@ -25,6 +25,10 @@ type SystemInterface interface {
 	sys.OperationServiceGetter
 	// APIServiceGetter API服务相关接口
 	sys.APIServiceGetter
+	// RecordingServiceGetter web终端会话录像相关接口
+	sys.RecordingServiceGetter
+	// CommandPolicyServiceGetter web终端命令策略相关接口
+	sys.CommandPolicyServiceGetter
 }
 
 var _ SystemInterface = &system{}
@@ -70,3 +74,13 @@ func (s *system) Operation() sys.OperationService {
 func (s *system) Api() sys.APIService {
 	return sys.NewApiService(s.factory)
 }
+
+// Recording 获取一个 sys.RecordingService 对象，此方法为实现 sys.RecordingServiceGetter 接口方法
+func (s *system) Recording() sys.RecordingService {
+	return sys.NewRecordingService(s.factory)
+}
+
+// CommandPolicy 获取一个 sys.CommandPolicyService 对象，此方法为实现 sys.CommandPolicyServiceGetter 接口方法
+func (s *system) CommandPolicy() sys.CommandPolicyService {
+	return sys.NewCommandPolicyService(s.factory)
+}
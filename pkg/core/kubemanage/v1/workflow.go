@@ -6,6 +6,7 @@ import (
 	"github.com/noovertime7/kubemanage/dao/model"
 	"github.com/noovertime7/kubemanage/dto/kubeDto"
 	"github.com/noovertime7/kubemanage/pkg/core/kubemanage/v1/kube"
+	"k8s.io/apimachinery/pkg/api/errors"
 )
 
 type WorkFlowServiceGetter interface {
@@ -46,7 +47,17 @@ func (w *workflow) Save(ctx context.Context, params *kubeDto.WorkFlowCreateInput
 	} else {
 		ingressName = ""
 	}
+	//只有设置了最大副本数的workflow才创建hpa
+	var hpaName string
+	if params.MaxReplicas > 0 {
+		hpaName = getHPAName(params.Name)
+	}
+	cli, err := kube.Clusters.Get(params.Cluster)
+	if err != nil {
+		return err
+	}
 	dataWorkFlow := &model.Workflow{
+		Cluster:     cli.Name,
 		Name:        params.Name,
 		NameSpace:   params.NameSpace,
 		Replicas:    params.Replicas,
@@ -54,9 +65,10 @@ func (w *workflow) Save(ctx context.Context, params *kubeDto.WorkFlowCreateInput
 		Service:     getServiceName(params.Name),
 		Ingress:     ingressName,
 		ServiceType: params.Type,
+		HPA:         hpaName,
 	}
 	//创建k8s资源
-	if err := createWorkflowRes(params); err != nil {
+	if err := createWorkflowRes(cli, params); err != nil {
 		return err
 	}
 	return w.factory.WorkFlow().Save(ctx, dataWorkFlow)
@@ -99,24 +111,56 @@ func (w *workflow) delWorkflowRes(ctx context.Context, id int) error {
 	if err != nil {
 		return err
 	}
+	cli, err := kube.Clusters.Get(workFlowInfo.Cluster)
+	if err != nil {
+		return err
+	}
 	//删除deployment
-	if err := kube.Deployment.DeleteDeployment(workFlowInfo.Name, workFlowInfo.NameSpace); err != nil {
+	if err := kube.Deployment.DeleteDeployment(cli, workFlowInfo.Name, workFlowInfo.NameSpace); err != nil {
 		return err
 	}
 	//删除service
-	if err := kube.Service.DeleteService(getServiceName(workFlowInfo.Name), workFlowInfo.NameSpace); err != nil {
+	if err := kube.Service.DeleteService(cli, getServiceName(workFlowInfo.Name), workFlowInfo.NameSpace); err != nil {
 		return err
 	}
 	//删除ingress，这里多了一层判断，因为只有type为ingress的workflow才有ingress资源
 	if workFlowInfo.ServiceType == "Ingress" {
-		if err := kube.Ingress.DeleteIngress(getIngressName(workFlowInfo.Name), workFlowInfo.NameSpace); err != nil {
+		if err := kube.Ingress.DeleteIngress(cli, getIngressName(workFlowInfo.Name), workFlowInfo.NameSpace); err != nil {
+			return err
+		}
+	}
+	//删除hpa，hpa可能已经通过hpa接口单独删除，不存在时忽略
+	if workFlowInfo.HPA != "" {
+		if err := kube.HPA.DeleteHPA(cli, workFlowInfo.HPA, workFlowInfo.NameSpace); err != nil && !errors.IsNotFound(err) {
 			return err
 		}
 	}
 	return nil
 }
 
-func createWorkflowRes(params *kubeDto.WorkFlowCreateInput) error {
+func createWorkflowRes(cli *kube.K8sClient, params *kubeDto.WorkFlowCreateInput) error {
+	//组装HPACreate类型的数据，设置了最大副本数时创建hpa，最小副本数未填写时使用deployment的副本数；
+	//在创建其他资源之前校验，避免hpa参数错误时留下创建了一半的资源
+	var hc *kubeDto.HPACreateInput
+	if params.MaxReplicas > 0 {
+		minReplicas := params.MinReplicas
+		if minReplicas == 0 {
+			minReplicas = params.Replicas
+		}
+		hc = &kubeDto.HPACreateInput{
+			Name:              getHPAName(params.Name),
+			NameSpace:         params.NameSpace,
+			Deployment:        params.Name,
+			MinReplicas:       minReplicas,
+			MaxReplicas:       params.MaxReplicas,
+			CpuUtilization:    params.CpuUtilization,
+			MemoryUtilization: params.MemoryUtilization,
+			Labels:            params.Label,
+		}
+		if err := kube.HPA.Validate(hc); err != nil {
+			return err
+		}
+	}
 	//声明service类型
 	var serviceType string
 	//组装DeployCreate类型的数据
@@ -133,7 +177,7 @@ func createWorkflowRes(params *kubeDto.WorkFlowCreateInput) error {
 		HealthPath:    params.HealthPath,
 	}
 	//创建deployment
-	if err := kube.Deployment.CreateDeployment(dc); err != nil {
+	if err := kube.Deployment.CreateDeployment(cli, dc); err != nil {
 		return err
 	}
 	//判断service类型
@@ -153,7 +197,7 @@ func createWorkflowRes(params *kubeDto.WorkFlowCreateInput) error {
 		NodePort:      params.NodePort,
 		Label:         params.Label,
 	}
-	if err := kube.Service.CreateService(sc); err != nil {
+	if err := kube.Service.CreateService(cli, sc); err != nil {
 		return err
 	}
 	//组装IngressCreate类型的数据，创建ingress，只有ingress类型的workflow才有ingress资源，所以这里做了一层判断
@@ -164,7 +208,12 @@ func createWorkflowRes(params *kubeDto.WorkFlowCreateInput) error {
 			Label:     params.Label,
 			Hosts:     params.Hosts,
 		}
-		if err := kube.Ingress.CreateIngress(ic); err != nil {
+		if err := kube.Ingress.CreateIngress(cli, ic); err != nil {
+			return err
+		}
+	}
+	if hc != nil {
+		if err := kube.HPA.CreateHPA(cli, hc); err != nil {
 			return err
 		}
 	}
@@ -180,3 +229,8 @@ func getServiceName(workflowName string) (serviceName string) {
 func getIngressName(workflowName string) (ingressName string) {
 	return workflowName + "-ing"
 }
+
+// workflow名字转换成hpa名字，添加-hpa后缀
+func getHPAName(workflowName string) (hpaName string) {
+	return workflowName + "-hpa"
+}
@@ -5,6 +5,8 @@ type GlobalError struct {
 	Code             int    `json:"code"`    // 业务码
 	Message          string `json:"message"` // 业务码
 	RealErrorMessage string `json:"err_msg"`
+	// Data 错误附带的数据，如资源版本冲突时的线上最新对象
+	Data interface{} `json:"data,omitempty"`
 }
 
 // Error 获取 GlobalError 的msg部分，这是实现的builtin/error接口方法
@@ -26,6 +28,10 @@ const (
 	CreateError = 20102
 	DeleteError = 20103
 	UpdateError = 20104
+	// ConflictError 资源已被他人修改，提交的resourceVersion不是最新版本
+	ConflictError = 20105
+
+	ClusterNotExistError = 20201
 
 	LoginErr  = 30101
 	LogoutErr = 30102
@@ -46,6 +52,10 @@ var codeTag = map[int]string{
 	UpdateError: "修改失败",
 	DeleteError: "删除失败",
 
+	ConflictError: "资源已被修改，请基于最新版本重新提交",
+
+	ClusterNotExistError: "集群不存在",
+
 	LoginErr:  "登录失败",
 	LogoutErr: "注销失败",
 }
@@ -64,3 +74,13 @@ func NewGlobalError(code int, err error) error {
 		RealErrorMessage: err.Error(),
 	}
 }
+
+// NewGlobalErrorWithData 创建一个附带数据的GlobalError，数据会作为响应体的data返回
+func NewGlobalErrorWithData(code int, err error, data interface{}) error {
+	return &GlobalError{
+		Code:             code,
+		Message:          codeTag[code],
+		RealErrorMessage: err.Error(),
+		Data:             data,
+	}
+}
@@ -13,6 +13,19 @@ func DefaultGetValidParams(c *gin.Context, params interface{}) error {
 	if err := c.ShouldBind(params); err != nil {
 		return err
 	}
+	return validParams(c, params)
+}
+
+// DefaultGetValidUriParams 绑定并校验路径参数，不读取请求体，用于请求体需要原样转发的场景
+func DefaultGetValidUriParams(c *gin.Context, params interface{}) error {
+	if err := c.ShouldBindUri(params); err != nil {
+		return err
+	}
+	return validParams(c, params)
+}
+
+// validParams 使用上下文中的验证器校验参数，并翻译错误信息
+func validParams(c *gin.Context, params interface{}) error {
 	//获取验证器
 	valid, err := GetValidator(c)
 	if err != nil {
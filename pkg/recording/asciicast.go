@@ -0,0 +1,252 @@
+package recording
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// asciinema v2 录像的事件类型
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+	EventMarker = "m"
+)
+
+// 客户端未发送窗口大小时录像使用的默认宽高
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// Header asciinema v2 录像的首行
+type Header struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// 回放时每页返回的事件数量和数据大小上限，超出时分页返回
+const (
+	DefaultPageEvents = 1000
+	MaxPageEvents     = 5000
+	maxPageBytes      = 4 << 20
+)
+
+// Cast 解析后的一页录像，Events 中每一项为 [时间, 类型, 数据] 格式的json数组；
+// More 为true时以 Next 作为offset继续获取后续事件
+type Cast struct {
+	Header Header            `json:"header"`
+	Events []json.RawMessage `json:"events"`
+	Offset int               `json:"offset"`
+	Next   int               `json:"next"`
+	More   bool              `json:"more"`
+}
+
+// Recorder 以asciinema v2格式记录终端会话，可以在输入和输出两个goroutine中并发使用；
+// 首行在第一个事件时才写入，第一个事件为调整窗口大小时作为录像的初始宽高
+type Recorder struct {
+	lock    sync.Mutex
+	w       io.WriteCloser
+	encoder *json.Encoder
+	header  Header
+	started bool
+	start   time.Time
+	elapsed time.Duration
+	size    int64
+	// pending 输出中被截断的不完整utf-8字符，与下一次输出拼接后再记录
+	pending []byte
+	err     error
+}
+
+// NewRecorder 创建录像器，录像写入w，Close时关闭w
+func NewRecorder(w io.WriteCloser, title string) *Recorder {
+	r := &Recorder{
+		w:     w,
+		start: time.Now(),
+	}
+	r.header = Header{
+		Version:   2,
+		Width:     defaultWidth,
+		Height:    defaultHeight,
+		Timestamp: r.start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm"},
+	}
+	r.encoder = json.NewEncoder(&countingWriter{w: w, n: &r.size})
+	// 终端输出中的<>&原样保存
+	r.encoder.SetEscapeHTML(false)
+	return r
+}
+
+// RecordOutput 记录容器输出到终端的内容
+func (r *Recorder) RecordOutput(p []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	data := append(r.pending, p...)
+	cut := incompleteSuffix(data)
+	r.pending = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return r.err
+	}
+	return r.event(EventOutput, string(data[:cut]))
+}
+
+// RecordInput 记录用户在终端中的输入
+func (r *Recorder) RecordInput(p []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.event(EventInput, string(p))
+}
+
+// RecordResize 记录终端窗口大小的调整
+func (r *Recorder) RecordResize(cols, rows uint16) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.started && r.err == nil && cols > 0 && rows > 0 {
+		r.header.Width, r.header.Height = cols, rows
+		r.writeHeader()
+		return r.err
+	}
+	return r.event(EventResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// RecordMarker 记录标记，用于在录像中标注控制权的移交等事件
+func (r *Recorder) RecordMarker(label string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.event(EventMarker, label)
+}
+
+// Size 已写入录像的字节数
+func (r *Recorder) Size() int64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.size
+}
+
+// Duration 最后一个事件距离录像开始的时间
+func (r *Recorder) Duration() time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.elapsed
+}
+
+// Close 写入剩余的输出并关闭录像，返回录像过程中的第一个错误
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.pending) > 0 {
+		_ = r.event(EventOutput, string(r.pending))
+		r.pending = nil
+	}
+	if !r.started && r.err == nil {
+		r.writeHeader()
+	}
+	if err := r.w.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// event 写入一个事件，写入失败后不再记录，之后的调用都返回该错误
+func (r *Recorder) event(kind, data string) error {
+	if r.err != nil {
+		return r.err
+	}
+	if !r.started {
+		r.writeHeader()
+	}
+	elapsed := time.Since(r.start)
+	if r.err == nil {
+		r.elapsed = elapsed
+		// 与asciinema一致保留到微秒
+		seconds := math.Round(elapsed.Seconds()*1e6) / 1e6
+		r.err = r.encoder.Encode([]interface{}{seconds, kind, data})
+	}
+	return r.err
+}
+
+func (r *Recorder) writeHeader() {
+	r.started = true
+	r.err = r.encoder.Encode(r.header)
+}
+
+// incompleteSuffix 返回末尾不完整utf-8字符的起始位置，末尾完整时返回len(p)
+func incompleteSuffix(p []byte) int {
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if !utf8.FullRune(p[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(p)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+// Parse 解析asciinema v2格式的录像，跳过前offset个事件，最多返回limit个事件，
+// 单页数据超过maxPageBytes时提前结束；会话异常中断时最后一行可能不完整，忽略该行
+func Parse(r io.Reader, offset, limit int) (*Cast, error) {
+	if limit <= 0 {
+		limit = DefaultPageEvents
+	}
+	if limit > MaxPageEvents {
+		limit = MaxPageEvents
+	}
+	reader := bufio.NewReader(r)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	cast := &Cast{Events: make([]json.RawMessage, 0), Offset: offset, Next: offset}
+	if err := json.Unmarshal(line, &cast.Header); err != nil {
+		return nil, fmt.Errorf("录像首行格式错误: %v", err)
+	}
+	if cast.Header.Version != 2 {
+		return nil, fmt.Errorf("不支持的录像版本 %d", cast.Header.Version)
+	}
+	index, size := 0, 0
+	for err == nil {
+		line, err = reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || !json.Valid(line) {
+			continue
+		}
+		if index++; index <= offset {
+			continue
+		}
+		if len(cast.Events) >= limit || (len(cast.Events) > 0 && size+len(line) > maxPageBytes) {
+			cast.More = true
+			break
+		}
+		cast.Events = append(cast.Events, json.RawMessage(line))
+		cast.Next++
+		size += len(line)
+	}
+	return cast, nil
+}
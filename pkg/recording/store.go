@@ -0,0 +1,93 @@
+package recording
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/noovertime7/kubemanage/cmd/app/config"
+)
+
+// defaultRecordingDir 配置文件未设置recordingDir时，录像保存在工作目录下的recordings目录
+const defaultRecordingDir = "recordings"
+
+// Store 录像的存储后端，key为以/分隔的相对路径，默认使用本地磁盘，
+// 需要保存到对象存储等位置时实现该接口并通过 RegisterStore 注册
+type Store interface {
+	// Name 存储后端名称，与录像索引一起保存，切换后端后旧录像给出明确的错误
+	Name() string
+	// Create 创建录像，key已存在时返回错误
+	Create(key string) (io.WriteCloser, error)
+	// Open 读取录像
+	Open(key string) (io.ReadCloser, error)
+}
+
+var (
+	storeLock    sync.Mutex
+	defaultStore Store
+)
+
+// RegisterStore 替换默认的存储后端，需要在服务启动时调用
+func RegisterStore(store Store) {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	defaultStore = store
+}
+
+// DefaultStore 获取当前的存储后端，未注册时使用配置文件中recordingDir指定的本地目录
+func DefaultStore() Store {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	if defaultStore == nil {
+		dir := defaultRecordingDir
+		if config.SysConfig != nil && config.SysConfig.WebShell.RecordingDir != "" {
+			dir = config.SysConfig.WebShell.RecordingDir
+		}
+		defaultStore = NewLocalStore(dir)
+	}
+	return defaultStore
+}
+
+// LocalStore 将录像保存在本地目录中
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{dir: dir}
+}
+
+func (s *LocalStore) Name() string {
+	return "local"
+}
+
+func (s *LocalStore) Create(key string) (io.WriteCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0750); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0640)
+}
+
+func (s *LocalStore) Open(key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(name)
+}
+
+// path 将key转换为目录下的文件路径，拒绝跳出目录的key
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.New("录像路径不合法")
+	}
+	return filepath.Join(s.dir, clean), nil
+}
@@ -0,0 +1,159 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocketTicketTTL websocket一次性票据的有效期
+const WebSocketTicketTTL = 30 * time.Second
+
+// WebSocketTicket 全局对象，签发和兑换websocket连接使用的一次性票据；
+// 票据保存在内存中，多实例部署时需要保证签发与连接落在同一实例
+var WebSocketTicket = &ticketStore{tickets: map[string]ticketEntry{}}
+
+type ticketStore struct {
+	lock    sync.Mutex
+	tickets map[string]ticketEntry
+}
+
+type ticketEntry struct {
+	claims    *CustomClaims
+	expiresAt time.Time
+}
+
+// Issue 为已认证的用户签发票据，同时清理已过期的票据
+func (s *ticketStore) Issue(claims *CustomClaims) (string, error) {
+	ticket, err := newTicket()
+	if err != nil {
+		return "", err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	for k, entry := range s.tickets {
+		if now.After(entry.expiresAt) {
+			delete(s.tickets, k)
+		}
+	}
+	s.tickets[ticket] = ticketEntry{claims: claims, expiresAt: now.Add(WebSocketTicketTTL)}
+	return ticket, nil
+}
+
+// Redeem 兑换票据，票据兑换后立即失效
+func (s *ticketStore) Redeem(ticket string) (*CustomClaims, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	entry, ok := s.tickets[ticket]
+	if !ok {
+		return nil, errors.New("票据不存在或已被使用")
+	}
+	delete(s.tickets, ticket)
+	if time.Now().After(entry.expiresAt) {
+		return nil, errors.New("票据已过期")
+	}
+	return entry.claims, nil
+}
+
+// ProxyTicketTTL 反向代理票据的有效期
+const ProxyTicketTTL = 10 * time.Minute
+
+// ProxyTicketCookie 签发反向代理票据时一起下发的cookie，路径限定在票据的代理地址下；
+// 票据需要与cookie中的密钥一起使用，只拿到代理地址无法访问
+const ProxyTicketCookie = "proxy_key"
+
+// ProxyTicket 全局对象，签发和校验反向代理使用的票据；票据作为路径的第一段放在 K8sProxyURLPrefix 之后，
+// 只能访问签发时指定的代理目标，有效期内可以重复使用，以便浏览器加载页面引用的其他资源
+var ProxyTicket = &proxyTicketStore{tickets: map[string]proxyTicketEntry{}}
+
+type proxyTicketStore struct {
+	lock    sync.Mutex
+	tickets map[string]proxyTicketEntry
+}
+
+type proxyTicketEntry struct {
+	claims    *CustomClaims
+	target    string
+	key       string
+	expiresAt time.Time
+}
+
+// Issue 为已认证的用户签发访问target的票据和与之绑定的密钥，target为 集群/命名空间/资源类型/名称/端口，
+// 同时清理已过期的票据
+func (s *proxyTicketStore) Issue(claims *CustomClaims, target string) (ticket, key string, err error) {
+	if ticket, err = newTicket(); err != nil {
+		return "", "", err
+	}
+	if key, err = newTicket(); err != nil {
+		return "", "", err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	for k, entry := range s.tickets {
+		if now.After(entry.expiresAt) {
+			delete(s.tickets, k)
+		}
+	}
+	s.tickets[ticket] = proxyTicketEntry{claims: claims, target: target, key: key, expiresAt: now.Add(ProxyTicketTTL)}
+	return ticket, key, nil
+}
+
+// Validate 从反向代理的请求路径中取出票据，校验票据有效、key与票据绑定的密钥一致且路径在票据的代理目标之下，
+// 路径中不允许出现 .. 等非规范的写法，避免通过上级目录访问其他目标
+func (s *proxyTicketStore) Validate(urlPath, key string) (*CustomClaims, error) {
+	if path.Clean(urlPath) != strings.TrimSuffix(urlPath, "/") {
+		return nil, errors.New("代理地址不合法")
+	}
+	rest := strings.TrimPrefix(urlPath, K8sProxyURLPrefix)
+	ticket, target := rest, ""
+	if idx := strings.Index(rest, "/"); idx >= 0 {
+		ticket, target = rest[:idx], rest[idx+1:]
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	entry, ok := s.tickets[ticket]
+	if !ok {
+		return nil, errors.New("代理票据不存在")
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.tickets, ticket)
+		return nil, errors.New("代理票据已过期")
+	}
+	if subtle.ConstantTimeCompare([]byte(key), []byte(entry.key)) != 1 {
+		return nil, errors.New("代理票据与当前客户端不匹配")
+	}
+	if target != entry.target && !strings.HasPrefix(target, entry.target+"/") {
+		return nil, errors.New("代理票据无权访问该地址")
+	}
+	return entry.claims, nil
+}
+
+// RedactProxyTicket 将反向代理路径中的票据替换为***，用于日志和操作记录，其他路径原样返回
+func RedactProxyTicket(urlPath string) string {
+	if !strings.HasPrefix(urlPath, K8sProxyURLPrefix) {
+		return urlPath
+	}
+	rest := strings.TrimPrefix(urlPath, K8sProxyURLPrefix)
+	if idx := strings.Index(rest, "/"); idx >= 0 {
+		return K8sProxyURLPrefix + "***" + rest[idx:]
+	}
+	return K8sProxyURLPrefix + "***"
+}
+
+func newTicket() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,199 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 命令策略的动作，与数据库中保存的值一致
+const (
+	CommandActionBlock   = "block"
+	CommandActionConfirm = "confirm"
+)
+
+// 命中策略后的处理结果
+const (
+	CommandOutcomeBlocked   = "blocked"
+	CommandOutcomeConfirmed = "confirmed"
+	CommandOutcomeCancelled = "cancelled"
+)
+
+// CommandRule 一条已编译的命令策略
+type CommandRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Action  string
+}
+
+// CommandEvent 命令行命中策略的事件，用于写入操作记录
+type CommandEvent struct {
+	Command string `json:"command"`
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Outcome string `json:"outcome"`
+}
+
+// 解析终端转义序列的状态
+const (
+	escNone = iota
+	// escStart 收到ESC，等待序列类型
+	escStart
+	// escCSI 在ESC [ 序列中，直到收到结束字符
+	escCSI
+	// escSS3 在ESC O 序列中，再跳过一个字符
+	escSS3
+)
+
+// CommandGuard 从web终端的输入流中还原用户输入的命令行，在回车时按策略拦截或要求确认。
+// 只能识别逐字输入的命令，方向键、历史命令和tab补全修改的内容无法还原，属于尽力而为的防护，
+// 不能替代容器本身的权限控制
+type CommandGuard struct {
+	lock    sync.Mutex
+	rules   []CommandRule
+	onMatch func(event CommandEvent)
+	line    []rune
+	esc     int
+	// confirming 等待用户确认的命令，下一次输入为回车时执行，否则取消
+	confirming *CommandEvent
+}
+
+// NewCommandGuard 创建命令拦截器，拦截的策略优先于需要确认的策略；onMatch在命令被拦截、确认或取消时调用
+func NewCommandGuard(rules []CommandRule, onMatch func(event CommandEvent)) *CommandGuard {
+	sorted := make([]CommandRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Action == CommandActionBlock {
+			sorted = append(sorted, rule)
+		}
+	}
+	for _, rule := range rules {
+		if rule.Action != CommandActionBlock {
+			sorted = append(sorted, rule)
+		}
+	}
+	return &CommandGuard{rules: sorted, onMatch: onMatch}
+}
+
+// Filter 处理一次输入，返回可以交给容器的内容和需要在终端中提示用户的信息；
+// 命令被拦截时用Ctrl+C清空shell中已输入的内容，并丢弃同一次输入中剩余的内容
+func (g *CommandGuard) Filter(data []byte) ([]byte, string) {
+	g.lock.Lock()
+	out, notice, events := g.filter(data)
+	g.lock.Unlock()
+	if g.onMatch != nil {
+		for _, event := range events {
+			g.onMatch(event)
+		}
+	}
+	return out, notice
+}
+
+func (g *CommandGuard) filter(data []byte) ([]byte, string, []CommandEvent) {
+	var events []CommandEvent
+	out := make([]byte, 0, len(data))
+	if g.confirming != nil && len(data) > 0 {
+		event := *g.confirming
+		g.confirming = nil
+		if data[0] != '\r' && data[0] != '\n' {
+			event.Outcome = CommandOutcomeCancelled
+			return []byte{'\x03'}, "已取消执行", append(events, event)
+		}
+		event.Outcome = CommandOutcomeConfirmed
+		events = append(events, event)
+		out = append(out, data[0])
+		data = data[1:]
+	}
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		raw := data[:size]
+		data = data[size:]
+		if g.skipEscape(r) {
+			out = append(out, raw...)
+			continue
+		}
+		switch r {
+		case '\r', '\n':
+			command := strings.Join(strings.Fields(string(g.line)), " ")
+			g.line = g.line[:0]
+			rule := g.match(command)
+			if rule == nil {
+				break
+			}
+			event := CommandEvent{Command: command, Rule: rule.Name, Action: rule.Action}
+			if rule.Action == CommandActionBlock {
+				event.Outcome = CommandOutcomeBlocked
+				return append(out, '\x03'), fmt.Sprintf("命令 %q 被策略 %s 拦截", command, rule.Name), append(events, event)
+			}
+			g.confirming = &event
+			return out, fmt.Sprintf("命令 %q 命中策略 %s，再次按回车确认执行，按其他任意键取消", command, rule.Name), events
+		// Ctrl+C、Ctrl+U 清空当前行
+		case '\x03', '\x15':
+			g.line = g.line[:0]
+		// 退格
+		case '\x7f', '\b':
+			if len(g.line) > 0 {
+				g.line = g.line[:len(g.line)-1]
+			}
+		// Ctrl+W 删除前一个单词
+		case '\x17':
+			end := len(g.line)
+			for end > 0 && unicode.IsSpace(g.line[end-1]) {
+				end--
+			}
+			for end > 0 && !unicode.IsSpace(g.line[end-1]) {
+				end--
+			}
+			g.line = g.line[:end]
+		default:
+			if unicode.IsPrint(r) || r == ' ' {
+				g.line = append(g.line, r)
+			}
+		}
+		out = append(out, raw...)
+	}
+	return out, "", events
+}
+
+// skipEscape 跳过方向键、粘贴标记等转义序列，返回r是否属于转义序列
+func (g *CommandGuard) skipEscape(r rune) bool {
+	switch g.esc {
+	case escStart:
+		switch r {
+		case '[':
+			g.esc = escCSI
+		case 'O':
+			g.esc = escSS3
+		default:
+			g.esc = escNone
+		}
+		return true
+	case escCSI:
+		if r >= 0x40 && r <= 0x7e {
+			g.esc = escNone
+		}
+		return true
+	case escSS3:
+		g.esc = escNone
+		return true
+	}
+	if r == '\x1b' {
+		g.esc = escStart
+		return true
+	}
+	return false
+}
+
+func (g *CommandGuard) match(command string) *CommandRule {
+	if command == "" {
+		return nil
+	}
+	for i := range g.rules {
+		if g.rules[i].Pattern.MatchString(command) {
+			return &g.rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// viewerSendBuffer 每个观看者待推送消息的缓冲数量，写满时断开该观看者，避免拖慢会话
+	viewerSendBuffer = 256
+	// viewerWriteTimeout 向观看者推送单条消息的超时时间
+	viewerWriteTimeout = 10 * time.Second
+)
+
+// TerminalErrReadOnly 观看者没有控制权时的输入被忽略
+const TerminalErrReadOnly = "read_only"
+
+var ErrTerminalViewerNotFound = errors.New("观看者不存在或已离开")
+
+// TerminalViewer 共享会话的观看者
+type TerminalViewer struct {
+	ID       string    `json:"id"`
+	UserID   int       `json:"user_id"`
+	Username string    `json:"username"`
+	JoinedAt time.Time `json:"joined_at"`
+	// Control 是否拥有会话的控制权
+	Control bool `json:"control"`
+}
+
+// TerminalControl 控制权变化时推送给所有者和观看者的消息，ViewerID为空时控制权属于会话所有者
+type TerminalControl struct {
+	ViewerID string `json:"viewer_id"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// marker 控制权变化时写入录像的标记
+func (c TerminalControl) marker() string {
+	if c.ViewerID == "" {
+		return "control: owner"
+	}
+	return fmt.Sprintf("control: %s(%d) viewer %s", c.Username, c.UserID, c.ViewerID)
+}
+
+type terminalViewer struct {
+	TerminalViewer
+	// guard 按观看者角色创建的危险命令拦截，观看者拥有控制权时用于过滤其输入
+	guard     *CommandGuard
+	conn      *websocket.Conn
+	send      chan []byte
+	closeOnce sync.Once
+	closeChan chan struct{}
+	// closeMessage 关闭帧，推送完已缓冲的消息后发送
+	closeMessage []byte
+}
+
+// close 通知writeLoop推送完已缓冲的消息后，以code和reason发送关闭帧并断开连接
+func (v *terminalViewer) close(code int, reason string) {
+	v.closeOnce.Do(func() {
+		v.closeMessage = websocket.FormatCloseMessage(code, reason)
+		close(v.closeChan)
+	})
+}
+
+// terminalHub 将会话的输出和窗口大小广播给只读的观看者，并记录控制权的归属
+type terminalHub struct {
+	lock    sync.Mutex
+	viewers map[string]*terminalViewer
+	// control 拥有控制权的观看者id，为空时控制权属于会话所有者
+	control string
+	size    *remotecommand.TerminalSize
+}
+
+func (h *terminalHub) writer() string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.control
+}
+
+func (h *terminalHub) setSize(size remotecommand.TerminalSize) {
+	h.lock.Lock()
+	h.size = &size
+	h.lock.Unlock()
+	h.broadcast(TerminalMessage{Operation: "resize", Cols: size.Width, Rows: size.Height})
+}
+
+// broadcast 将消息推送给所有观看者，观看者的缓冲写满时将其断开
+func (h *terminalHub) broadcast(msg TerminalMessage) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(h.viewers) == 0 {
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	for _, viewer := range h.viewers {
+		select {
+		case viewer.send <- data:
+		default:
+			go viewer.close(websocket.CloseTryAgainLater, "viewer too slow")
+		}
+	}
+}
+
+func (h *terminalHub) list() []TerminalViewer {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	list := make([]TerminalViewer, 0, len(h.viewers))
+	for _, viewer := range h.viewers {
+		info := viewer.TerminalViewer
+		info.Control = viewer.ID == h.control
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].JoinedAt.Before(list[j].JoinedAt)
+	})
+	return list
+}
+
+func (h *terminalHub) closeViewers(code int, reason string) {
+	h.lock.Lock()
+	viewers := make([]*terminalViewer, 0, len(h.viewers))
+	for _, viewer := range h.viewers {
+		viewers = append(viewers, viewer)
+	}
+	h.lock.Unlock()
+	for _, viewer := range viewers {
+		viewer.close(code, reason)
+	}
+}
+
+// Viewers 列出会话当前的观看者
+func (t *TerminalSession) Viewers() []TerminalViewer {
+	return t.hub.list()
+}
+
+// Watch 将http协议升级为websocket，以只读方式镜像会话的输出和窗口大小，直到观看者离开或会话结束；
+// 观看者获得控制权后，其输入经过guard拦截后作为会话的输入，guard为空时不拦截
+func (t *TerminalSession) Watch(w http.ResponseWriter, r *http.Request, info TerminalViewer, guard *CommandGuard) error {
+	upgrader := &websocket.Upgrader{
+		HandshakeTimeout: time.Second * 2,
+		// 检测请求来源
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+		Subprotocols: subprotocols(r),
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+	if info.ID, err = randomID(8); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	info.JoinedAt = time.Now()
+	viewer := &terminalViewer{
+		TerminalViewer: info,
+		guard:          guard,
+		conn:           conn,
+		send:           make(chan []byte, viewerSendBuffer),
+		closeChan:      make(chan struct{}),
+	}
+
+	go viewer.writeLoop()
+	t.hub.lock.Lock()
+	select {
+	case <-t.closeChan:
+		t.hub.lock.Unlock()
+		viewer.close(websocket.CloseNormalClosure, "session closed")
+		return nil
+	default:
+	}
+	t.hub.viewers[viewer.ID] = viewer
+	// 先推送观看者id和当前窗口大小，之后的输出才能正确显示
+	viewer.send <- mustMarshal(TerminalMessage{Operation: "viewer", Data: viewer.ID})
+	if t.hub.size != nil {
+		viewer.send <- mustMarshal(TerminalMessage{Operation: "resize", Cols: t.hub.size.Width, Rows: t.hub.size.Height})
+	}
+	t.hub.lock.Unlock()
+	t.notifyViewers()
+
+	defer t.leave(viewer)
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return nil
+		}
+		var msg TerminalMessage
+		if err := json.Unmarshal(message, &msg); err != nil {
+			return err
+		}
+		if msg.Operation != "stdin" {
+			continue
+		}
+		if t.hub.writer() != viewer.ID {
+			viewer.sendMessage(TerminalMessage{Operation: "error", Data: string(mustMarshal(TerminalError{Code: TerminalErrReadOnly, Message: "没有控制权，输入已忽略"}))})
+			continue
+		}
+		if err := t.input([]byte(msg.Data), viewer.guard); err != nil {
+			return err
+		}
+	}
+}
+
+// HandOver 将控制权移交给指定的观看者，viewerID为空时收回给会话所有者，返回获得控制权的用户；
+// 移交前先在录像中写入标记，写入失败时不移交，保证录像中的每段输入都能对应到用户
+func (t *TerminalSession) HandOver(viewerID string) (TerminalControl, error) {
+	t.hub.lock.Lock()
+	control := TerminalControl{}
+	if viewerID != "" {
+		viewer, ok := t.hub.viewers[viewerID]
+		if !ok {
+			t.hub.lock.Unlock()
+			return control, ErrTerminalViewerNotFound
+		}
+		control = TerminalControl{ViewerID: viewer.ID, UserID: viewer.UserID, Username: viewer.Username}
+	}
+	if err := t.recordControl(control); err != nil {
+		t.hub.lock.Unlock()
+		return control, err
+	}
+	t.hub.control = viewerID
+	t.hub.lock.Unlock()
+	t.notifyControl(control)
+	return control, nil
+}
+
+// StopSharing 断开所有观看者，控制权收回给会话所有者
+func (t *TerminalSession) StopSharing() {
+	t.hub.closeViewers(websocket.CloseNormalClosure, "sharing stopped")
+}
+
+// leave 观看者离开，持有控制权时收回给会话所有者
+func (t *TerminalSession) leave(viewer *terminalViewer) {
+	viewer.close(websocket.CloseNormalClosure, "")
+	t.hub.lock.Lock()
+	delete(t.hub.viewers, viewer.ID)
+	hadControl := t.hub.control == viewer.ID
+	if hadControl {
+		t.hub.control = ""
+		_ = t.recordControl(TerminalControl{})
+	}
+	t.hub.lock.Unlock()
+	if hadControl {
+		t.notifyControl(TerminalControl{})
+	}
+	t.notifyViewers()
+}
+
+// notifyViewers 观看者变化时向会话所有者推送当前的观看者列表
+func (t *TerminalSession) notifyViewers() {
+	_ = t.WriteMessage("viewers", string(mustMarshal(t.hub.list())))
+}
+
+// recordControl 在录像中写入控制权变化的标记，调用方需要持有hub的锁，保证标记先于新控制者的输入
+func (t *TerminalSession) recordControl(control TerminalControl) error {
+	if t.recorder == nil {
+		return nil
+	}
+	return t.recorder.RecordMarker(control.marker())
+}
+
+// notifyControl 控制权变化时通知所有者和所有观看者
+func (t *TerminalSession) notifyControl(control TerminalControl) {
+	data := string(mustMarshal(control))
+	_ = t.WriteMessage("control", data)
+	t.hub.broadcast(TerminalMessage{Operation: "control", Data: data})
+}
+
+func (v *terminalViewer) sendMessage(msg TerminalMessage) {
+	select {
+	case v.send <- mustMarshal(msg):
+	default:
+	}
+}
+
+func (v *terminalViewer) writeLoop() {
+	defer v.conn.Close()
+	for {
+		select {
+		case data := <-v.send:
+			if err := v.write(data, viewerWriteTimeout); err != nil {
+				v.close(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-v.closeChan:
+			for {
+				select {
+				case data := <-v.send:
+					if err := v.write(data, time.Second); err != nil {
+						return
+					}
+				default:
+					_ = v.conn.WriteControl(websocket.CloseMessage, v.closeMessage, time.Now().Add(time.Second))
+					return
+				}
+			}
+		}
+	}
+}
+
+func (v *terminalViewer) write(data []byte, timeout time.Duration) error {
+	_ = v.conn.SetWriteDeadline(time.Now().Add(timeout))
+	return v.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
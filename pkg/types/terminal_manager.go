@@ -0,0 +1,315 @@
+package types
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// idleWarningBefore 空闲断开前多久在终端中提醒用户
+const idleWarningBefore = time.Minute
+
+var (
+	ErrTerminalShuttingDown = errors.New("服务正在关闭，不能打开新的web终端")
+	ErrTerminalNotFound     = errors.New("web终端会话不存在或已结束")
+	ErrTerminalNotOwner     = errors.New("只有会话所有者可以共享会话或移交控制权")
+	ErrTerminalShareInvalid = errors.New("共享链接无效或会话已结束")
+)
+
+// TerminalSessionMeta web终端会话的元信息，用于会话管理和审计
+type TerminalSessionMeta struct {
+	ID          string    `json:"id"`
+	UserID      int       `json:"user_id"`
+	Username    string    `json:"username"`
+	Cluster     string    `json:"cluster"`
+	Namespace   string    `json:"namespace"`
+	Pod         string    `json:"pod"`
+	Container   string    `json:"container"`
+	Command     string    `json:"command"`
+	RecordingID int       `json:"recording_id"`
+	StartedAt   time.Time `json:"started_at"`
+	LastInputAt time.Time `json:"last_input_at"`
+	// Shared 是否已生成共享链接
+	Shared  bool             `json:"shared"`
+	Viewers []TerminalViewer `json:"viewers"`
+}
+
+// TerminalLimits web终端的并发会话数限制和空闲超时，为0时不限制
+type TerminalLimits struct {
+	MaxSessions        int
+	MaxSessionsPerUser int
+	IdleTimeout        time.Duration
+}
+
+// TerminalSessions 全局对象，管理当前活跃的web终端会话；
+// 会话保存在内存中，多实例部署时并发限制按实例计算
+var TerminalSessions = &terminalManager{sessions: map[string]*managedSession{}, shares: map[string]string{}}
+
+type managedSession struct {
+	session *TerminalSession
+	meta    TerminalSessionMeta
+	stop    chan struct{}
+	// shareToken 共享链接中的令牌，为空时未共享
+	shareToken string
+}
+
+type terminalManager struct {
+	lock     sync.Mutex
+	sessions map[string]*managedSession
+	// shares 共享令牌到会话id的映射
+	shares  map[string]string
+	closing bool
+	// active 正在处理的web终端请求，服务关闭时等待其完成收尾工作
+	active sync.WaitGroup
+}
+
+// Begin 开始处理一个web终端请求，服务正在关闭时返回错误；
+// 返回的函数需要在请求的收尾工作(如保存录像)完成后调用，服务关闭时会等待所有请求调用该函数
+func (m *terminalManager) Begin() (func(), error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.closing {
+		return nil, ErrTerminalShuttingDown
+	}
+	m.active.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(m.active.Done)
+	}, nil
+}
+
+// Register 登记已建立的会话并分配会话id，超过并发限制时返回错误；IdleTimeout大于0时，空闲超时后自动断开
+func (m *terminalManager) Register(session *TerminalSession, meta *TerminalSessionMeta, limits TerminalLimits) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.closing {
+		return ErrTerminalShuttingDown
+	}
+	if limits.MaxSessions > 0 && len(m.sessions) >= limits.MaxSessions {
+		return fmt.Errorf("web终端会话数已达到上限 %d，请稍后重试", limits.MaxSessions)
+	}
+	if limits.MaxSessionsPerUser > 0 {
+		count := 0
+		for _, ms := range m.sessions {
+			if ms.meta.UserID == meta.UserID {
+				count++
+			}
+		}
+		if count >= limits.MaxSessionsPerUser {
+			return fmt.Errorf("用户 %s 的web终端会话数已达到上限 %d，请先关闭其他终端", meta.Username, limits.MaxSessionsPerUser)
+		}
+	}
+	id, err := randomID(8)
+	if err != nil {
+		return err
+	}
+	meta.ID = id
+	meta.StartedAt = time.Now()
+	ms := &managedSession{session: session, meta: *meta, stop: make(chan struct{})}
+	m.sessions[meta.ID] = ms
+	if limits.IdleTimeout > 0 {
+		go m.watchIdle(ms, limits.IdleTimeout)
+	}
+	return nil
+}
+
+// Remove 会话结束后移除登记
+func (m *terminalManager) Remove(id string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if ms, ok := m.sessions[id]; ok {
+		delete(m.sessions, id)
+		delete(m.shares, ms.shareToken)
+		close(ms.stop)
+	}
+}
+
+// List 列出活跃的会话，按开始时间排序
+func (m *terminalManager) List() []TerminalSessionMeta {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	list := make([]TerminalSessionMeta, 0, len(m.sessions))
+	for _, ms := range m.sessions {
+		meta := ms.meta
+		meta.LastInputAt = ms.session.LastInput()
+		meta.Shared = ms.shareToken != ""
+		meta.Viewers = ms.session.Viewers()
+		list = append(list, meta)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StartedAt.Before(list[j].StartedAt)
+	})
+	return list
+}
+
+// Kill 终止指定的会话，operator为执行终止的管理员，会显示在被终止的终端中
+func (m *terminalManager) Kill(id, operator string) error {
+	m.lock.Lock()
+	ms, ok := m.sessions[id]
+	m.lock.Unlock()
+	if !ok {
+		return ErrTerminalNotFound
+	}
+	ms.session.Terminate(websocket.ClosePolicyViolation, "terminated by administrator", fmt.Sprintf("会话已被管理员 %s 终止", operator))
+	return nil
+}
+
+// Share 会话所有者生成共享令牌，已共享时返回原有的令牌，令牌在会话结束或停止共享后失效
+func (m *terminalManager) Share(id string, userID int) (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ms, err := m.owned(id, userID)
+	if err != nil {
+		return "", err
+	}
+	if ms.shareToken == "" {
+		token, err := randomID(16)
+		if err != nil {
+			return "", err
+		}
+		ms.shareToken = token
+		m.shares[token] = id
+	}
+	return ms.shareToken, nil
+}
+
+// Unshare 会话所有者停止共享，使令牌失效并断开所有观看者
+func (m *terminalManager) Unshare(id string, userID int) error {
+	m.lock.Lock()
+	ms, err := m.owned(id, userID)
+	if err == nil {
+		delete(m.shares, ms.shareToken)
+		ms.shareToken = ""
+	}
+	m.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	ms.session.StopSharing()
+	return nil
+}
+
+// HandOver 会话所有者将控制权移交给观看者，viewerID为空时收回控制权，返回会话的元信息和获得控制权的用户
+func (m *terminalManager) HandOver(id string, userID int, viewerID string) (TerminalSessionMeta, TerminalControl, error) {
+	m.lock.Lock()
+	ms, err := m.owned(id, userID)
+	m.lock.Unlock()
+	if err != nil {
+		return TerminalSessionMeta{}, TerminalControl{}, err
+	}
+	control, err := ms.session.HandOver(viewerID)
+	return ms.meta, control, err
+}
+
+// Lookup 根据共享令牌查询会话的元信息，用于观看前的权限校验
+func (m *terminalManager) Lookup(token string) (TerminalSessionMeta, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ms, ok := m.sessions[m.shares[token]]
+	if !ok || token == "" {
+		return TerminalSessionMeta{}, ErrTerminalShareInvalid
+	}
+	return ms.meta, nil
+}
+
+// Watch 通过共享令牌以观看者身份加入会话，阻塞直到观看者离开或会话结束；guard按观看者的角色创建
+func (m *terminalManager) Watch(token string, viewer TerminalViewer, guard *CommandGuard, w http.ResponseWriter, r *http.Request) error {
+	m.lock.Lock()
+	ms, ok := m.sessions[m.shares[token]]
+	m.lock.Unlock()
+	if !ok || token == "" {
+		return ErrTerminalShareInvalid
+	}
+	return ms.session.Watch(w, r, viewer, guard)
+}
+
+// owned 查询userID所拥有的会话，调用方需要持有锁
+func (m *terminalManager) owned(id string, userID int) (*managedSession, error) {
+	ms, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrTerminalNotFound
+	}
+	if ms.meta.UserID != userID {
+		return nil, ErrTerminalNotOwner
+	}
+	return ms, nil
+}
+
+// Shutdown 拒绝新的会话，向所有活跃会话发送关闭帧，并等待请求的收尾工作完成或ctx超时
+func (m *terminalManager) Shutdown(ctx context.Context) error {
+	m.lock.Lock()
+	m.closing = true
+	sessions := make([]*managedSession, 0, len(m.sessions))
+	for _, ms := range m.sessions {
+		sessions = append(sessions, ms)
+	}
+	m.lock.Unlock()
+
+	for _, ms := range sessions {
+		ms.session.Terminate(websocket.CloseGoingAway, "server shutting down", "服务正在关闭，会话已断开")
+	}
+	done := make(chan struct{})
+	go func() {
+		m.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// watchIdle 定期检查会话的空闲时间，断开前在终端中提醒一次，用户重新输入后可以再次提醒
+func (m *terminalManager) watchIdle(ms *managedSession, timeout time.Duration) {
+	warnAfter := timeout - idleWarningBefore
+	if warnAfter <= 0 {
+		warnAfter = timeout / 2
+	}
+	interval := timeout / 10
+	if interval < time.Second {
+		interval = time.Second
+	} else if interval > 10*time.Second {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	warned := false
+	for {
+		select {
+		case <-ms.stop:
+			return
+		case <-ticker.C:
+			idle := time.Since(ms.session.LastInput())
+			switch {
+			case idle >= timeout:
+				ms.session.Terminate(websocket.CloseNormalClosure, "idle timeout", fmt.Sprintf("会话空闲超过 %s，已断开连接", timeout))
+				return
+			case idle >= warnAfter && !warned:
+				warned = true
+				_, _ = ms.session.Write([]byte(fmt.Sprintf("\r\n会话已空闲 %s，将在 %s 后断开，输入任意内容保持连接\r\n",
+					idle.Round(time.Second), (timeout - idle).Round(time.Second))))
+			case idle < warnAfter:
+				warned = false
+			}
+		}
+	}
+}
+
+// randomID 生成n字节的随机十六进制字符串
+func randomID(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -19,7 +19,10 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -35,11 +38,59 @@ type TerminalMessage struct {
 	Cols      uint16 `json:"cols"`
 }
 
+// web终端结构化错误的错误码
+const (
+	// TerminalErrShellNotFound 容器中没有可用的shell
+	TerminalErrShellNotFound = "shell_not_found"
+	// TerminalErrCommandNotAllowed 自定义命令不在允许列表中
+	TerminalErrCommandNotAllowed = "command_not_allowed"
+	// TerminalErrExecFailed 执行命令失败
+	TerminalErrExecFailed = "exec_failed"
+	// TerminalErrSessionLimit 超过web终端的并发会话数限制，或服务正在关闭
+	TerminalErrSessionLimit = "session_limit"
+)
+
+// TerminalError web终端的结构化错误，以operation为error的消息推送，data为其json
+type TerminalError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// SessionRecorder 记录终端会话的输入、输出和窗口大小调整，返回错误时终端随之结束；
+// 控制权移交时记录标记，用于区分之后的输入来自哪个用户
+type SessionRecorder interface {
+	RecordInput(p []byte) error
+	RecordOutput(p []byte) error
+	RecordResize(cols, rows uint16) error
+	RecordMarker(label string) error
+}
+
 // TerminalSession 定义 TerminalSession 结构体，实现 PtyHandler 接口 // wsConn 是 websocket 连接 // sizeChan 用来定义终端输入和输出的宽和高 // doneChan 用于标记退出终端
 type TerminalSession struct {
 	wsConn   *websocket.Conn
 	sizeChan chan remotecommand.TerminalSize
 	doneChan chan struct{}
+	doneOnce sync.Once
+	recorder SessionRecorder
+	// guard 危险命令拦截，为空时不拦截
+	guard *CommandGuard
+	// writeLock websocket连接不支持并发写，空闲提醒等推送与终端输出需要互斥
+	writeLock sync.Mutex
+	// lastInput 最后一次收到用户输入的时间，UnixNano
+	lastInput int64
+	// inputChan 所有者和拥有控制权的观看者的输入都通过该通道交给Read
+	inputChan chan terminalInput
+	readOnce  sync.Once
+	pending   []byte
+	closeChan chan struct{}
+	closeOnce sync.Once
+	hub       terminalHub
+}
+
+// terminalInput 会话的一次输入，err不为空时表示输入结束
+type terminalInput struct {
+	data []byte
+	err  error
 }
 
 // NewTerminalSession 该方法用于升级 http 协议至 websocket，并new一个 TerminalSession 类型的对象返回
@@ -51,71 +102,221 @@ func NewTerminalSession(w http.ResponseWriter, r *http.Request) (*TerminalSessio
 		CheckOrigin: func(r *http.Request) bool {
 			return true
 		},
-		Subprotocols: []string{r.Header.Get("Sec-WebSocket-Protocol")},
+		Subprotocols: subprotocols(r),
 	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return nil, err
 	}
 	session := &TerminalSession{
-		wsConn:   conn,
-		sizeChan: make(chan remotecommand.TerminalSize),
-		doneChan: make(chan struct{}),
+		wsConn:    conn,
+		sizeChan:  make(chan remotecommand.TerminalSize),
+		doneChan:  make(chan struct{}),
+		lastInput: time.Now().UnixNano(),
+		inputChan: make(chan terminalInput),
+		closeChan: make(chan struct{}),
+		hub:       terminalHub{viewers: map[string]*terminalViewer{}},
 	}
 
 	return session, nil
 }
 
-// 用于读取web端的输入，接收web端输入的指令内容
+// 用于读取web端的输入，接收web端输入的指令内容；第一次调用时开始读取web端的消息，
+// 一次输入超过p的长度时分多次返回
 func (t *TerminalSession) Read(p []byte) (int, error) {
-	_, message, err := t.wsConn.ReadMessage()
-	if err != nil {
-		return copy(p, "\u0004"), err
-	}
-	// 反序列化
-	var msg TerminalMessage
-	if err = json.Unmarshal(message, &msg); err != nil {
-		return copy(p, "\u0004"), err
-	}
-	// 逻辑判断
-	switch msg.Operation {
-	// 如果是标准输入
-	case "stdin":
-		return copy(p, msg.Data), nil
-	// 窗口调整大小
-	case "resize":
-		t.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
-		return 0, nil
-	// ping	无内容交互
-	case "ping":
-		return 0, nil
-	default:
-		return copy(p, "\u0004"), fmt.Errorf("unknown message type")
+	t.readOnce.Do(func() {
+		go t.readLoop()
+	})
+	if len(t.pending) == 0 {
+		select {
+		case in := <-t.inputChan:
+			if in.err != nil {
+				return copy(p, "\u0004"), in.err
+			}
+			t.pending = in.data
+		case <-t.closeChan:
+			return copy(p, "\u0004"), io.EOF
+		}
+	}
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	return n, nil
+}
+
+// readLoop 读取会话所有者发来的消息，控制权移交给观看者后忽略所有者的输入
+func (t *TerminalSession) readLoop() {
+	for {
+		_, message, err := t.wsConn.ReadMessage()
+		// 反序列化
+		var msg TerminalMessage
+		if err == nil {
+			err = json.Unmarshal(message, &msg)
+		}
+		if err == nil {
+			// 逻辑判断
+			switch msg.Operation {
+			// 如果是标准输入
+			case "stdin":
+				if t.hub.writer() == "" {
+					err = t.input([]byte(msg.Data), t.guard)
+				} else {
+					_ = t.WriteError(TerminalErrReadOnly, "控制权已移交给观看者，输入已忽略")
+				}
+			// 窗口调整大小
+			case "resize":
+				err = t.resize(msg.Cols, msg.Rows)
+			// ping	无内容交互
+			case "ping":
+			default:
+				err = fmt.Errorf("unknown message type")
+			}
+		}
+		if err != nil {
+			t.sendInput(terminalInput{err: err})
+			return
+		}
+	}
+}
+
+// input 记录一次输入，经过guard的危险命令拦截后交给Read，录像中保存用户的原始输入；
+// 所有者的输入使用会话的guard，拥有控制权的观看者使用按其角色创建的guard
+func (t *TerminalSession) input(data []byte, guard *CommandGuard) error {
+	atomic.StoreInt64(&t.lastInput, time.Now().UnixNano())
+	if t.recorder != nil {
+		if err := t.recorder.RecordInput(data); err != nil {
+			return err
+		}
+	}
+	if guard != nil {
+		var notice string
+		data, notice = guard.Filter(data)
+		if notice != "" {
+			if _, err := t.Write([]byte("\r\n" + notice + "\r\n")); err != nil {
+				return err
+			}
+		}
+		if len(data) == 0 {
+			return nil
+		}
+	}
+	t.sendInput(terminalInput{data: data})
+	return nil
+}
+
+func (t *TerminalSession) sendInput(in terminalInput) {
+	select {
+	case t.inputChan <- in:
+	case <-t.closeChan:
 	}
 }
 
+// resize 记录窗口大小调整，同步给观看者后交给Next
+func (t *TerminalSession) resize(cols, rows uint16) error {
+	if t.recorder != nil {
+		if err := t.recorder.RecordResize(cols, rows); err != nil {
+			return err
+		}
+	}
+	size := remotecommand.TerminalSize{Width: cols, Height: rows}
+	t.hub.setSize(size)
+	select {
+	case t.sizeChan <- size:
+	case <-t.doneChan:
+	case <-t.closeChan:
+	}
+	return nil
+}
+
 // 写数据的方法，拿到 api-server 的返回内容，向web端输出
 func (t *TerminalSession) Write(p []byte) (int, error) {
+	if t.recorder != nil {
+		if err := t.recorder.RecordOutput(p); err != nil {
+			return 0, err
+		}
+	}
+	if err := t.WriteMessage("stdout", string(p)); err != nil {
+		return 0, err
+	}
+	t.hub.broadcast(TerminalMessage{Operation: "stdout", Data: string(p)})
+	return len(p), nil
+}
+
+// SetRecorder 设置会话录像，需要在开始读写之前调用
+func (t *TerminalSession) SetRecorder(recorder SessionRecorder) {
+	t.recorder = recorder
+}
+
+// SetGuard 设置危险命令拦截，需要在开始读写之前调用
+func (t *TerminalSession) SetGuard(guard *CommandGuard) {
+	t.guard = guard
+}
+
+// WriteMessage 以指定的操作类型向web端推送一条消息，终端之外的推送场景(如发布状态)也复用该格式
+func (t *TerminalSession) WriteMessage(operation, data string) error {
 	msg, err := json.Marshal(TerminalMessage{
-		Operation: "stdout",
-		Data:      string(p),
+		Operation: operation,
+		Data:      data,
 	})
 	if err != nil {
-		return 0, err
+		return err
 	}
-	if err = t.wsConn.WriteMessage(websocket.TextMessage, msg); err != nil {
-		return 0, err
+	t.writeLock.Lock()
+	defer t.writeLock.Unlock()
+	return t.wsConn.WriteMessage(websocket.TextMessage, msg)
+}
+
+// WriteError 向web端推送结构化错误
+func (t *TerminalSession) WriteError(code, message string) error {
+	data, err := json.Marshal(TerminalError{Code: code, Message: message})
+	if err != nil {
+		return err
+	}
+	return t.WriteMessage("error", string(data))
+}
+
+// LastInput 最后一次收到用户输入的时间，没有输入时为会话开始的时间
+func (t *TerminalSession) LastInput() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&t.lastInput))
+}
+
+// Terminate 在终端中输出message后，以code和reason发送关闭帧并断开连接，用于空闲超时、管理员终止和服务关闭；
+// 关闭帧的reason长度有限，使用简短的英文
+func (t *TerminalSession) Terminate(code int, reason, message string) {
+	if message != "" {
+		_, _ = t.Write([]byte("\r\n" + message + "\r\n"))
+	}
+	_ = t.wsConn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	t.Done()
+	_ = t.wsConn.Close()
+}
+
+// SetWriteDeadline 设置写超时，客户端长时间不读取时推送失败，避免服务端一直阻塞
+func (t *TerminalSession) SetWriteDeadline(deadline time.Time) error {
+	return t.wsConn.SetWriteDeadline(deadline)
+}
+
+// WaitClose 读取并丢弃web端发来的消息，直到连接断开，用于只推送不接收的场景感知客户端退出
+func (t *TerminalSession) WaitClose() {
+	for {
+		if _, _, err := t.wsConn.ReadMessage(); err != nil {
+			return
+		}
 	}
-	return len(p), nil
 }
 
 // Done 标记关闭doneChan,关闭后触发退出终端
 func (t *TerminalSession) Done() {
-	close(t.doneChan)
+	t.doneOnce.Do(func() {
+		close(t.doneChan)
+	})
 }
 
-// Close 用于关闭websocket连接
+// Close 用于关闭websocket连接，同时断开所有观看者
 func (t *TerminalSession) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.closeChan)
+	})
+	t.hub.closeViewers(websocket.CloseNormalClosure, "session closed")
 	return t.wsConn.Close()
 }
 
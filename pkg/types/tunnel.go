@@ -0,0 +1,100 @@
+package types
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/noovertime7/kubemanage/pkg"
+)
+
+// tunnelBufferSize 转发时单条websocket消息的最大字节数
+const tunnelBufferSize = 32 * 1024
+
+// TunnelSession 以二进制消息在websocket上承载tcp字节流，用于端口转发
+type TunnelSession struct {
+	wsConn *websocket.Conn
+}
+
+// NewTunnelSession 将http协议升级为websocket，并new一个 TunnelSession 类型的对象返回
+func NewTunnelSession(w http.ResponseWriter, r *http.Request) (*TunnelSession, error) {
+	upgrader := &websocket.Upgrader{
+		HandshakeTimeout: time.Second * 2,
+		ReadBufferSize:   tunnelBufferSize,
+		WriteBufferSize:  tunnelBufferSize,
+		// 检测请求来源
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+		Subprotocols: subprotocols(r),
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TunnelSession{wsConn: conn}, nil
+}
+
+// Bridge 双向转发conn与websocket之间的数据，任意一端关闭后结束并关闭两端，
+// 返回web端发往conn的字节数和conn发往web端的字节数
+func (t *TunnelSession) Bridge(conn io.ReadWriteCloser) (sent, received int64, err error) {
+	done := make(chan error, 2)
+	go func() {
+		buf := make([]byte, tunnelBufferSize)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				if err := t.wsConn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+					done <- err
+					return
+				}
+				received += int64(n)
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				done <- err
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			_, data, err := t.wsConn.ReadMessage()
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					err = nil
+				}
+				done <- err
+				return
+			}
+			if _, err := conn.Write(data); err != nil {
+				done <- err
+				return
+			}
+			sent += int64(len(data))
+		}
+	}()
+	err = <-done
+	// 关闭两端使另一个方向的转发退出
+	_ = conn.Close()
+	_ = t.wsConn.Close()
+	<-done
+	return sent, received, err
+}
+
+// subprotocols 回应给客户端的子协议，优先选择携带token之外的子协议，避免在响应中回显token；
+// 客户端只提供了token子协议时仍然原样回应，否则浏览器会拒绝连接
+func subprotocols(r *http.Request) []string {
+	protocols := websocket.Subprotocols(r)
+	for _, protocol := range protocols {
+		if !strings.HasPrefix(protocol, pkg.WebSocketTokenProtocolPrefix) {
+			return []string{protocol}
+		}
+	}
+	return protocols
+}
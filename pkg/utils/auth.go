@@ -1,14 +1,50 @@
 package utils
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/noovertime7/kubemanage/pkg"
 	"github.com/pkg/errors"
 )
 
 // GetClaims 从token中，取出claims值
+// websocket请求还可以通过ticket查询参数携带一次性票据，或在Sec-WebSocket-Protocol中携带token；
+// 反向代理的请求只接受路径中的代理票据
 func GetClaims(c *gin.Context) (*pkg.CustomClaims, error) {
+	// JWTAuth 已经解析过时直接使用，一次性票据只能兑换一次
+	if claims, exists := c.Get("claims"); exists {
+		if cl, ok := claims.(*pkg.CustomClaims); ok {
+			return cl, nil
+		}
+	}
+	if strings.HasPrefix(c.Request.URL.Path, pkg.K8sProxyURLPrefix) {
+		key, _ := c.Cookie(pkg.ProxyTicketCookie)
+		claims, err := pkg.ProxyTicket.Validate(c.Request.URL.Path, key)
+		if err != nil {
+			return nil, err
+		}
+		c.Set("claims", claims)
+		return claims, nil
+	}
 	token := c.Request.Header.Get("token")
+	if token == "" && websocket.IsWebSocketUpgrade(c.Request) {
+		if ticket := c.Query("ticket"); ticket != "" {
+			claims, err := pkg.WebSocketTicket.Redeem(ticket)
+			if err != nil {
+				return nil, err
+			}
+			c.Set("claims", claims)
+			return claims, nil
+		}
+		for _, protocol := range websocket.Subprotocols(c.Request) {
+			if strings.HasPrefix(protocol, pkg.WebSocketTokenProtocolPrefix) {
+				token = strings.TrimPrefix(protocol, pkg.WebSocketTokenProtocolPrefix)
+				break
+			}
+		}
+	}
 	if token == "" {
 		return nil, errors.New("请求未携带token,无权限访问")
 	}
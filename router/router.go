@@ -4,18 +4,23 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/noovertime7/kubemanage/controller/api"
 	"github.com/noovertime7/kubemanage/controller/operation"
+	"github.com/noovertime7/kubemanage/controller/recording"
 	"github.com/noovertime7/kubemanage/controller/user"
 
 	"github.com/noovertime7/kubemanage/cmd/app/options"
 	"github.com/noovertime7/kubemanage/controller/authority"
+	"github.com/noovertime7/kubemanage/controller/cluster"
 	"github.com/noovertime7/kubemanage/controller/kubeController"
 	"github.com/noovertime7/kubemanage/controller/menu"
 	"github.com/noovertime7/kubemanage/controller/other"
+	"github.com/noovertime7/kubemanage/controller/webshell"
 	"github.com/noovertime7/kubemanage/middleware"
 )
 
 // InstallRouters 初始化 APIs 路由
 func InstallRouters(opt *options.Options) {
+	// 安装就绪检查路由，不需要认证
+	other.NewHealthRoute(opt.GinEngine)
 	// 创建一个apiGroup，前缀是/api
 	apiGroup := opt.GinEngine.Group("/api")
 	// 安装中间件组件
@@ -32,6 +37,8 @@ func installUnOperationRouters(apiGroup *gin.RouterGroup) {
 	api.NewApiRouter(apiGroup)
 	// 安装 操作历史记录相关 的路由
 	operation.NewOperationRouter(apiGroup)
+	// 安装 web终端会话录像相关 的路由
+	recording.NewRecordingRouter(apiGroup)
 	// 安装 用户相关 的路由
 	user.NewUserRouter(apiGroup)
 }
@@ -43,11 +50,15 @@ func installOperationRouters(apiGroup *gin.RouterGroup) {
 	{
 		// 安装swagger路由
 		other.NewSwaggarRoute(apiGroup)
+		// 安装集群管理相关的路由
+		cluster.NewClusterRouter(apiGroup)
 		// 安装k8s资源操作相关的路由
 		kubeController.NewKubeRouter(apiGroup)
 		// 安装菜单相关的路由
 		menu.NewMenuRouter(apiGroup)
 		// 安装csbin权限安全相关的路由
 		authority.NewCasbinRouter(apiGroup)
+		// 安装web终端会话管理相关的路由
+		webshell.NewWebShellRouter(apiGroup)
 	}
 }